@@ -24,7 +24,6 @@ package main
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -103,12 +102,24 @@ const (
 	API_FETCH_CERTS = API_ROOT + "/bmc/fetchcerts"
 	API_SET_CERTS   = API_ROOT + "/bmc/setcerts"
 	API_SET_CERT    = API_ROOT + "/bmc/setcert"
+	API_CERT_EXPIRY = API_ROOT + "/bmc/certexpiry"
+	API_CERT_RENEW  = API_ROOT + "/bmc/certrenew"
+	API_CSR_CERTS   = API_ROOT + "/bmc/csrcerts"
+	API_UPL_CERTS   = API_ROOT + "/bmc/uploadcerts"
+	API_CERT_AUDIT  = API_ROOT + "/bmc/certaudit"
+	API_TRUST_CERTS = API_ROOT + "/bmc/trustcerts"
+	API_FETCH_TRUST = API_ROOT + "/bmc/fetchtrustcerts"
+	API_DEL_TRUST   = API_ROOT + "/bmc/deletetrustcerts"
+	API_TLS_PINS    = API_ROOT + "/bmc/tlspins"
 	API_BIOS        = API_ROOT + "/bmc/bios"
+	API_BIOS_DUMP   = API_BIOS + "/dump"
+	API_BIOS_LOAD   = API_BIOS + "/load"
 	API_HEALTH      = API_ROOT + "/health"
 	API_LIVENESS    = API_ROOT + "/liveness"
 	API_READINESS   = API_ROOT + "/readiness"
 	API_VERSION     = API_ROOT + "/version"
 	API_PARAMS      = API_ROOT + "/params"
+	API_AUDIT       = API_ROOT + "/audit"
 )
 
 // Commonly used Redfish endpoints
@@ -120,6 +131,7 @@ const (
 	RFMANAGERS_API   = "/redfish/v1/Managers"
 	RFSYSTEMS_API    = "/redfish/v1/Systems"
 	RFREGISTRIES_API = "/redfish/v1/Registries"
+	GB_BIOSREG_API   = "/redfish/v1/Registries/BiosAttributeRegistry.json"
 	MT_NWP_API       = "/redfish/v1/Managers/BMC/NetworkProtocol"
 	RV_NWP_API       = "/redfish/v1/Managers/Self/NetworkProtocol"
 	ACCTSVC_API      = "/redfish/v1/Account"
@@ -141,6 +153,13 @@ func newRouter(routes []Route) *mux.Router {
 	for _, route := range routes {
 		var handler http.Handler
 		handler = route.HandlerFunc
+		if authEnable {
+			handler = authHandler(route, handler)
+		}
+		//Audit outside of auth, so rejected calls are recorded too
+		if auditEnabled() && routeAudited(route) {
+			handler = auditHandler(route, handler)
+		}
 		router.
 			Methods(route.Method).
 			Path(route.Pattern).
@@ -234,6 +253,66 @@ func generateRoutes() Routes {
 			API_SET_CERT + "/{xname}",
 			doBMCSetCertsPostSingle,
 		},
+		Route{"doBMCCertExpiryPost",
+			strings.ToUpper("Post"),
+			API_CERT_EXPIRY,
+			doBMCCertExpiryPost,
+		},
+		Route{"doBMCCertRenewGet",
+			strings.ToUpper("Get"),
+			API_CERT_RENEW,
+			doBMCCertRenewGet,
+		},
+		Route{"doBMCCSRCertsPost",
+			strings.ToUpper("Post"),
+			API_CSR_CERTS,
+			doBMCCSRCertsPost,
+		},
+		Route{"doBMCUploadCertsPost",
+			strings.ToUpper("Post"),
+			API_UPL_CERTS,
+			doBMCUploadCertsPost,
+		},
+		Route{"doBMCCertAuditPost",
+			strings.ToUpper("Post"),
+			API_CERT_AUDIT,
+			doBMCCertAuditPost,
+		},
+		Route{"doBMCTrustCertsPost",
+			strings.ToUpper("Post"),
+			API_TRUST_CERTS,
+			doBMCTrustCertsPost,
+		},
+		Route{"doBMCFetchTrustCertsPost",
+			strings.ToUpper("Post"),
+			API_FETCH_TRUST,
+			doBMCFetchTrustCertsPost,
+		},
+		Route{"doBMCDeleteTrustCertsPost",
+			strings.ToUpper("Post"),
+			API_DEL_TRUST,
+			doBMCDeleteTrustCertsPost,
+		},
+		Route{"doBMCTLSPinsGet",
+			strings.ToUpper("Get"),
+			API_TLS_PINS,
+			doBMCTLSPinsGet,
+		},
+		Route{"doBMCTLSPinGet",
+			strings.ToUpper("Get"),
+			API_TLS_PINS + "/{xname}",
+			doBMCTLSPinGet,
+		},
+		Route{"doBMCTLSPinDelete",
+			strings.ToUpper("Delete"),
+			API_TLS_PINS + "/{xname}",
+			doBMCTLSPinDelete,
+		},
+		Route{"doBMCTLSPinAckPost",
+			strings.ToUpper("Post"),
+			API_TLS_PINS + "/{xname}/ack",
+			doBMCTLSPinAckPost,
+		},
 		Route{"doBiosTpmStateGet",
 			strings.ToUpper("Get"),
 			API_BIOS + "/{xname}/tpmstate",
@@ -244,6 +323,96 @@ func generateRoutes() Routes {
 			API_BIOS + "/{xname}/tpmstate",
 			doBiosTpmStatePatch,
 		},
+		Route{"doBiosTpmStateDumpPost",
+			strings.ToUpper("Post"),
+			API_BIOS_DUMP + "/tpmstate",
+			doBiosTpmStateDumpPost,
+		},
+		Route{"doBiosTpmStateLoadPost",
+			strings.ToUpper("Post"),
+			API_BIOS_LOAD + "/tpmstate",
+			doBiosTpmStateLoadPost,
+		},
+		Route{"doBiosComparePost",
+			strings.ToUpper("Post"),
+			API_BIOS + "/compare",
+			doBiosComparePost,
+		},
+		Route{"doBiosPasswordPost",
+			strings.ToUpper("Post"),
+			API_BIOS + "/{xname}/password",
+			doBiosPasswordPost,
+		},
+		Route{"doBiosPasswordBulkPost",
+			strings.ToUpper("Post"),
+			API_BIOS + "/password",
+			doBiosPasswordBulkPost,
+		},
+		Route{"doBiosSecureBootGet",
+			strings.ToUpper("Get"),
+			API_BIOS + "/{xname}/secureboot",
+			doBiosSecureBootGet,
+		},
+		Route{"doBiosSecureBootPatch",
+			strings.ToUpper("Patch"),
+			API_BIOS + "/{xname}/secureboot",
+			doBiosSecureBootPatch,
+		},
+		Route{"doBiosSecureBootDumpPost",
+			strings.ToUpper("Post"),
+			API_BIOS_DUMP + "/secureboot",
+			doBiosSecureBootDumpPost,
+		},
+		Route{"doBiosSecureBootLoadPost",
+			strings.ToUpper("Post"),
+			API_BIOS_LOAD + "/secureboot",
+			doBiosSecureBootLoadPost,
+		},
+		Route{"doBiosResetPost",
+			strings.ToUpper("Post"),
+			API_BIOS + "/{xname}/reset",
+			doBiosResetPost,
+		},
+		Route{"doBiosResetBulkPost",
+			strings.ToUpper("Post"),
+			API_BIOS + "/reset",
+			doBiosResetBulkPost,
+		},
+		Route{"doBiosFeaturesGet",
+			strings.ToUpper("Get"),
+			API_BIOS + "/features",
+			doBiosFeaturesGet,
+		},
+		Route{"doBiosPendingGet",
+			strings.ToUpper("Get"),
+			API_BIOS + "/{xname}",
+			doBiosPendingGet,
+		},
+		Route{"doBiosPendingDelete",
+			strings.ToUpper("Delete"),
+			API_BIOS + "/{xname}",
+			doBiosPendingDelete,
+		},
+		Route{"doBiosFeatureGet",
+			strings.ToUpper("Get"),
+			API_BIOS + "/{xname}/{feature}",
+			doBiosFeatureGet,
+		},
+		Route{"doBiosFeaturePatch",
+			strings.ToUpper("Patch"),
+			API_BIOS + "/{xname}/{feature}",
+			doBiosFeaturePatch,
+		},
+		Route{"doBiosFeatureDumpPost",
+			strings.ToUpper("Post"),
+			API_BIOS_DUMP + "/{feature}",
+			doBiosFeatureDumpPost,
+		},
+		Route{"doBiosFeatureLoadPost",
+			strings.ToUpper("Post"),
+			API_BIOS_LOAD + "/{feature}",
+			doBiosFeatureLoadPost,
+		},
 		Route{"doHealthGet",
 			strings.ToUpper("Get"),
 			API_HEALTH,
@@ -264,6 +433,11 @@ func generateRoutes() Routes {
 			API_VERSION,
 			doVersionGet,
 		},
+		Route{"doAuditGet",
+			strings.ToUpper("Get"),
+			API_AUDIT,
+			doAuditGet,
+		},
 	}
 }
 
@@ -327,6 +501,9 @@ func getStatusMsg(tp *trsapi.HttpTask) string {
 	if statusCodeOK(ecode) {
 		return "OK"
 	}
+	if taskRFTLSRefused(tp) {
+		return (*tp.Err).Error()
+	}
 
 	smsg := fmt.Sprintf("%s, URL: %s", http.StatusText(ecode),
 		tp.Request.URL.Path)
@@ -477,65 +654,12 @@ func sendErrorRsp(w http.ResponseWriter, title string, emsg string, url string,
 
 // Query HSM to verify targets and/or expand groups
 
-var hsmClient *http.Client
-var hsmTransport *http.Transport
-
 func doHSMGet(url string) ([]byte, error) {
-	if hsmClient == nil {
-		hsmTransport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
-		hsmClient = &http.Client{Transport: hsmTransport}
-	}
-	req, _ := http.NewRequest("GET", url, nil)
-	base.SetHTTPUserAgent(req, serviceName)
-	rsp, err := hsmClient.Do(req)
-	defer base.DrainAndCloseResponseBody(rsp)
-	if err != nil {
-		logger.Errorf("Problem contacting state manager: %v", err)
-		return nil, err
-	}
-	rspPayload, plerr := ioutil.ReadAll(rsp.Body)
-	if plerr != nil {
-		logger.Errorf("Problem reading request body: %v", plerr)
-		return nil, plerr
-	}
-
-	if !statusCodeOK(rsp.StatusCode) {
-		emsg := fmt.Errorf("Bad return status from state manager: %d",
-			rsp.StatusCode)
-		logger.Println(emsg)
-		return nil, emsg
-	}
-
-	return rspPayload, nil
+	return getHSMRequester().Do(http.MethodGet, url, nil)
 }
 
 func doHSMPutPostPatchDel(url string, method string, pld []byte) ([]byte, error) {
-	if hsmClient == nil {
-		hsmTransport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
-		hsmClient = &http.Client{Transport: hsmTransport}
-	}
-	req, _ := http.NewRequest(strings.ToUpper(method), url, bytes.NewBuffer(pld))
-	base.SetHTTPUserAgent(req, serviceName)
-	rsp, err := hsmClient.Do(req)
-	defer base.DrainAndCloseResponseBody(rsp)
-	if err != nil {
-		logger.Errorf("Problem contacting state manager: %v", err)
-		return nil, err
-	}
-	rspPayload, plerr := ioutil.ReadAll(rsp.Body)
-	if plerr != nil {
-		logger.Errorf("Problem reading request body: %v", plerr)
-		return nil, plerr
-	}
-
-	if !statusCodeOK(rsp.StatusCode) {
-		emsg := fmt.Errorf("Bad return status from state manager: %d",
-			rsp.StatusCode)
-		logger.Println(emsg)
-		return nil, emsg
-	}
-
-	return rspPayload, nil
+	return getHSMRequester().Do(strings.ToUpper(method), url, pld)
 }
 
 // Given an HSM state returns if it is a viable state.
@@ -746,22 +870,54 @@ func unlockComponents(taskList []trsapi.HttpTask) error {
 	return nil
 }
 
-// Convenience func.  Launches a task list and waits for all tasks to complete.
-// Returns an error if the launch fails (NOT if any of the tasks fail).
+// Add the Redfish creds to the tasks of a task list which aren't ignored
+// and launch it, holding the Redfish client lock.
 
-func doOp(taskList []trsapi.HttpTask) error {
+func launchWithRFCreds(taskList *[]trsapi.HttpTask) (chan *trsapi.HttpTask, error) {
 	rfClientLock.Lock()
 	defer rfClientLock.Unlock()
-	nTasks := 0
-	for ii := 0; ii < len(taskList); ii++ {
-		if taskList[ii].Ignore {
+
+	for ii := 0; ii < len(*taskList); ii++ {
+		if (*taskList)[ii].Ignore {
 			continue
 		}
-		nTasks++
-		err := popRFCreds(&taskList[ii])
+		err := popRFCreds(&(*taskList)[ii])
 		if err != nil {
-			return fmt.Errorf("Error getting RF creds for '%s': %v",
-				targFromTask(&taskList[ii]), err)
+			return nil, fmt.Errorf("Error getting RF creds for '%s': %v",
+				targFromTask(&(*taskList)[ii]), err)
+		}
+	}
+	return launchRFTasks(taskList)
+}
+
+// Convenience func.  Launches a task list and waits for all tasks to complete.
+// Returns an error if the launch fails (NOT if any of the tasks fail).
+
+func doOp(taskList []trsapi.HttpTask) error {
+	//Tasks refused by strict TLS mode or BMC identity verification are left
+	//out of the launch, and keep their refusal as their result.  TLS pins
+	//are checked when the tasks are sent, after identity verification, so
+	//that a wrong device doesn't get pinned.
+
+	var refused []int
+	checks := []func([]trsapi.HttpTask) []int{strictTLSCheck,
+		bmcIdentityPreflight}
+	for _, check := range checks {
+		for _, ii := range check(taskList) {
+			taskList[ii].Ignore = true
+			refused = append(refused, ii)
+		}
+	}
+	defer func() {
+		for _, ii := range refused {
+			taskList[ii].Ignore = false
+		}
+	}()
+
+	nTasks := 0
+	for ii := 0; ii < len(taskList); ii++ {
+		if !taskList[ii].Ignore {
+			nTasks++
 		}
 	}
 
@@ -770,7 +926,10 @@ func doOp(taskList []trsapi.HttpTask) error {
 		return nil
 	}
 
-	rchan, lerr := tloc.Launch(&taskList)
+	//The pre-flight checks above do network I/O, so the Redfish client
+	//lock is only taken to add the creds and launch.
+
+	rchan, lerr := launchWithRFCreds(&taskList)
 	if lerr != nil {
 		logger.Errorf("Launch() failed: %v", lerr)
 		return lerr
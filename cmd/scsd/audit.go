@@ -0,0 +1,477 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/gorilla/mux"
+)
+
+// Audit log.  If SCSD_AUDIT_FILE is set, every call to the BMC creds,
+// certs and TLS pins, BMC config, BIOS and SCSD parameter endpoints, reads
+// included, is recorded as one JSON line appended to that file: who made
+// it, from where, the endpoint and targets, the request fields with
+// secrets redacted, and the outcome for each target.  Only /v1/audit and
+// the health, liveness, readiness and version endpoints aren't audited.
+// /v1/audit GET queries the records.
+//
+// Calls are recorded whether or not they pass authentication and access
+// checks, along with the auth outcome; the subject of a token which fails
+// validation is recorded apart from the verified subject.
+//
+// The X-Forwarded-Client-Cert and X-Forwarded-For headers are only
+// recorded for calls coming from a proxy listed in
+// SCSD_AUDIT_TRUSTED_PROXIES, a comma separated list of IP addresses or
+// CIDRs, since any other client could set them to anything.
+
+var auditFile = ""
+var auditTrustedProxies = ""
+
+const auditMaxBody = 4 * 1024 * 1024
+const auditDfltLimit = 100
+
+type auditOutcome struct {
+	Target     string `json:"Target"`
+	StatusCode int    `json:"StatusCode"`
+	StatusMsg  string `json:"StatusMsg,omitempty"`
+}
+
+type auditRecord struct {
+	Time           string                 `json:"Time"`
+	Subject        string                 `json:"Subject,omitempty"`
+	ClaimedSubject string                 `json:"ClaimedSubject,omitempty"`
+	AuthResult     string                 `json:"AuthResult,omitempty"`
+	ClientCert     string                 `json:"ClientCert,omitempty"`
+	SourceIP       string                 `json:"SourceIP"`
+	ForwardedFor   string                 `json:"ForwardedFor,omitempty"`
+	Method         string                 `json:"Method"`
+	Endpoint       string                 `json:"Endpoint"`
+	Targets        []string               `json:"Targets,omitempty"`
+	Fields         map[string]interface{} `json:"Fields,omitempty"`
+	StatusCode     int                    `json:"StatusCode"`
+	Outcomes       []auditOutcome         `json:"Outcomes,omitempty"`
+}
+
+// Auth outcomes recorded in the audit log.
+
+const (
+	AuditAuthOK           = "Authorized"
+	AuditAuthNoToken      = "MissingToken"
+	AuditAuthInvalidToken = "InvalidToken"
+	AuditAuthForbidden    = "Forbidden"
+)
+
+type auditCtxKeyType int
+
+const auditCtxKey auditCtxKeyType = 0
+
+type auditRsp struct {
+	Records []auditRecord `json:"Records"`
+}
+
+var auditLock sync.Mutex
+var auditFD *os.File
+
+// Captures the status and payload of a response.
+
+type auditRspWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *auditRspWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditRspWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.body.Len() < auditMaxBody {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func auditEnabled() bool {
+	return auditFile != ""
+}
+
+// Route families whose calls are audited, by path prefix: everything under
+// /v1/bmc (creds, certs, TLS pins, config and BIOS) and SCSD's parameters.
+
+var auditRoutePrefixes = []string{API_ROOT + "/bmc/", API_PARAMS}
+
+func routeAudited(route Route) bool {
+	for _, prefix := range auditRoutePrefixes {
+		if strings.HasPrefix(route.Pattern, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the strings of a JSON list, or nil if it isn't a list of strings.
+
+func stringList(val interface{}) []string {
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil
+	}
+	var strs []string
+	for _, v := range list {
+		s, ok := v.(string)
+		if !ok {
+			return nil
+		}
+		strs = append(strs, s)
+	}
+	return strs
+}
+
+// Fill in the targets and fields of an audit record from a request.
+
+func auditRequest(rec *auditRecord, r *http.Request, reqBody []byte) {
+	if xname, ok := mux.Vars(r)["xname"]; ok {
+		rec.Targets = append(rec.Targets, xname)
+	}
+
+	var body map[string]interface{}
+	if (len(reqBody) == 0) || (json.Unmarshal(reqBody, &body) != nil) {
+		return
+	}
+	for _, k := range []string{"Targets", "DomainIDs"} {
+		if strs := stringList(body[k]); strs != nil {
+			rec.Targets = append(rec.Targets, strs...)
+			delete(body, k)
+		} else if list, ok := body[k].([]interface{}); ok {
+			for _, v := range list {
+				if m, ok := v.(map[string]interface{}); ok {
+					if xname, ok := m["Xname"].(string); ok {
+						rec.Targets = append(rec.Targets, xname)
+					}
+				}
+			}
+		}
+	}
+	if len(body) > 0 {
+		rec.Fields = redactSecrets(body).(map[string]interface{})
+	}
+}
+
+// Fill in the per-target outcomes of an audit record from a response.
+// These are the elements of its top level lists which have a StatusCode.
+
+func auditResponse(rec *auditRecord, rspBody []byte) {
+	var body map[string]json.RawMessage
+	if json.Unmarshal(rspBody, &body) != nil {
+		return
+	}
+	for _, raw := range body {
+		var elems []struct {
+			Xname      string `json:"Xname"`
+			ID         string `json:"ID"`
+			StatusCode *int   `json:"StatusCode"`
+			StatusMsg  string `json:"StatusMsg"`
+		}
+		if json.Unmarshal(raw, &elems) != nil {
+			continue
+		}
+		for _, elem := range elems {
+			if elem.StatusCode == nil {
+				continue
+			}
+			targ := elem.Xname
+			if targ == "" {
+				targ = elem.ID
+			}
+			rec.Outcomes = append(rec.Outcomes, auditOutcome{Target: targ,
+				StatusCode: *elem.StatusCode, StatusMsg: elem.StatusMsg})
+		}
+	}
+}
+
+// Returns true if a request comes from a trusted proxy, whose forwarded
+// identity headers can be recorded.
+
+func auditFromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, elem := range strings.Split(auditTrustedProxies, ",") {
+		elem = strings.TrimSpace(elem)
+		if strings.Contains(elem, "/") {
+			_, ipnet, err := net.ParseCIDR(elem)
+			if (err == nil) && ipnet.Contains(ip) {
+				return true
+			}
+		} else if pip := net.ParseIP(elem); (pip != nil) && pip.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the client cert identity of a request: the subject of a TLS
+// client cert, or the URI or subject forwarded by a trusted proxy.
+
+func auditClientCert(r *http.Request) string {
+	if (r.TLS != nil) && (len(r.TLS.PeerCertificates) > 0) {
+		return r.TLS.PeerCertificates[0].Subject.String()
+	}
+	if !auditFromTrustedProxy(r) {
+		return ""
+	}
+	xfcc := r.Header.Get("X-Forwarded-Client-Cert")
+	for _, key := range []string{"URI=", "Subject="} {
+		for _, elem := range strings.Split(xfcc, ";") {
+			if strings.HasPrefix(elem, key) {
+				return strings.Trim(strings.TrimPrefix(elem, key), `"`)
+			}
+		}
+	}
+	return ""
+}
+
+// Append a record to the audit log.
+
+func writeAuditRecord(rec *auditRecord) error {
+	ba, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	ba = append(ba, '\n')
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	if auditFD == nil {
+		auditFD, err = os.OpenFile(auditFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			auditFD = nil
+			return err
+		}
+	}
+	_, err = auditFD.Write(ba)
+	if err != nil {
+		auditFD.Close()
+		auditFD = nil
+	}
+	return err
+}
+
+// Record the auth outcome of a call in its audit record, if it has one.
+//
+// r(in):       Request being authorized.
+// result(in):  Auth outcome, one of the AuditAuth* values.
+// subject(in): Subject of the caller's validated token; "" if none.
+// claimed(in): Subject claimed by a token which failed validation.
+
+func auditAuthResult(r *http.Request, result, subject, claimed string) {
+	rec, _ := r.Context().Value(auditCtxKey).(*auditRecord)
+	if rec == nil {
+		return
+	}
+	rec.AuthResult = result
+	rec.Subject = subject
+	rec.ClaimedSubject = claimed
+}
+
+// Wrap a route's handler so each call is recorded in the audit log.  It
+// wraps the auth handler, which records the auth outcome.
+
+func auditHandler(route Route, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody []byte
+		if r.Body != nil {
+			reqBody, _ = ioutil.ReadAll(io.LimitReader(r.Body, auditMaxBody))
+			r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(reqBody), r.Body))
+		}
+
+		rec := auditRecord{Time: time.Now().UTC().Format(time.RFC3339),
+			Method: r.Method, Endpoint: r.URL.Path,
+			ClientCert: auditClientCert(r)}
+		if auditFromTrustedProxy(r) {
+			rec.ForwardedFor = r.Header.Get("X-Forwarded-For")
+		}
+		rec.SourceIP, _, _ = net.SplitHostPort(r.RemoteAddr)
+		if rec.SourceIP == "" {
+			rec.SourceIP = r.RemoteAddr
+		}
+		auditRequest(&rec, r, reqBody)
+
+		aw := &auditRspWriter{ResponseWriter: w}
+		handler.ServeHTTP(aw, r.WithContext(context.WithValue(r.Context(), auditCtxKey, &rec)))
+
+		rec.StatusCode = aw.status
+		if rec.StatusCode == 0 {
+			rec.StatusCode = http.StatusOK
+		}
+		auditResponse(&rec, aw.body.Bytes())
+
+		err := writeAuditRecord(&rec)
+		if err != nil {
+			logger.Errorf("Can't write audit record of %s %s to '%s': %v",
+				rec.Method, rec.Endpoint, auditFile, err)
+		}
+	})
+}
+
+// Returns true if an audit record matches the query parameters.
+
+func auditMatch(rec *auditRecord, subject, target, endpoint string,
+	since, until time.Time) bool {
+	if (subject != "") && (rec.Subject != subject) && (rec.ClientCert != subject) {
+		return false
+	}
+	if (endpoint != "") && !strings.HasPrefix(rec.Endpoint, endpoint) {
+		return false
+	}
+	if !since.IsZero() || !until.IsZero() {
+		tm, err := time.Parse(time.RFC3339, rec.Time)
+		if (err != nil) || (!since.IsZero() && tm.Before(since)) ||
+			(!until.IsZero() && tm.After(until)) {
+			return false
+		}
+	}
+	if target == "" {
+		return true
+	}
+	for _, targ := range rec.Targets {
+		if strings.EqualFold(targ, target) {
+			return true
+		}
+	}
+	for _, oc := range rec.Outcomes {
+		if strings.EqualFold(oc.Target, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// /v1/audit GET
+//
+// Query parameters, all optional: subject, target, endpoint (path prefix),
+// since and until (RFC3339 times), limit (the most recent records kept,
+// default 100).
+
+func doAuditGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	if !auditEnabled() {
+		sendErrorRsp(w, "Audit log not enabled", "Audit log is not enabled",
+			r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	var since, until time.Time
+	var err error
+	q := r.URL.Query()
+	limit := auditDfltLimit
+	if q.Get("limit") != "" {
+		limit, err = strconv.Atoi(q.Get("limit"))
+		if (err != nil) || (limit <= 0) {
+			sendErrorRsp(w, "Bad query parameter",
+				"ERROR: limit must be a positive number", r.URL.Path,
+				http.StatusBadRequest)
+			return
+		}
+	}
+	for _, tp := range []struct {
+		name string
+		tm   *time.Time
+	}{{"since", &since}, {"until", &until}} {
+		if q.Get(tp.name) == "" {
+			continue
+		}
+		*tp.tm, err = time.Parse(time.RFC3339, q.Get(tp.name))
+		if err != nil {
+			sendErrorRsp(w, "Bad query parameter",
+				"ERROR: "+tp.name+" must be an RFC3339 time", r.URL.Path,
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	retData := auditRsp{Records: []auditRecord{}}
+	fd, err := os.Open(auditFile)
+	if err != nil && !os.IsNotExist(err) {
+		sendErrorRsp(w, "Audit log read error",
+			"ERROR: can't read audit log: "+err.Error(), r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+	if err == nil {
+		defer fd.Close()
+		rdr := bufio.NewReader(fd)
+		for {
+			line, rerr := rdr.ReadBytes('\n')
+			var rec auditRecord
+			if (len(line) > 0) && (json.Unmarshal(line, &rec) == nil) &&
+				auditMatch(&rec, q.Get("subject"), q.Get("target"),
+					q.Get("endpoint"), since, until) {
+				retData.Records = append(retData.Records, rec)
+				if len(retData.Records) > limit {
+					retData.Records = retData.Records[1:]
+				}
+			}
+			if rerr != nil {
+				break
+			}
+		}
+	}
+
+	ba, berr := json.Marshal(&retData)
+	if berr != nil {
+		sendErrorRsp(w, "Return data marshal error", "ERROR: problem marshaling return data.",
+			r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
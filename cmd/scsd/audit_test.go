@@ -0,0 +1,258 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditHandler(t *testing.T) {
+	loggerSetup()
+	savedAudit, savedEnable, savedJWKS, savedIssuer := auditFile, authEnable, authJWKS, authIssuer
+	savedProxies := auditTrustedProxies
+	defer func() {
+		auditLock.Lock()
+		if auditFD != nil {
+			auditFD.Close()
+			auditFD = nil
+		}
+		auditLock.Unlock()
+		auditFile, authEnable, authJWKS, authIssuer = savedAudit, savedEnable, savedJWKS, savedIssuer
+		auditTrustedProxies = savedProxies
+		authKeys, authKeysTime = nil, time.Time{}
+	}()
+
+	dir := t.TempDir()
+	key := newTestAuthKey(t, "key1")
+	writeTestJWKS(t, filepath.Join(dir, "jwks.json"), key)
+	auditFile = filepath.Join(dir, "audit.log")
+	authEnable = true
+	authJWKS = filepath.Join(dir, "jwks.json")
+	authIssuer = "https://auth.local"
+	authKeys, authKeysTime = nil, time.Time{}
+	auditTrustedProxies = "192.0.2.0/24"
+
+	loadPld := `{"Force":false,"Targets":["x0c0s0b0","x0c0s1b0"],
+		"Params":{"SyslogServer":"syslog1:514","SSHKey":"ssh-rsa AAA"}}`
+
+	routes := generateRoutes()
+	for ix := range routes {
+		if routes[ix].Name == "doAuditGet" {
+			continue
+		}
+		routes[ix].HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			ba, _ := ioutil.ReadAll(r.Body)
+			if (r.URL.Path == API_LOADCFG) && (string(ba) != loadPld) {
+				t.Errorf("Request body not passed on: '%s'", string(ba))
+			}
+			w.Header().Set(CT_TYPE, CT_APPJSON)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"Targets":[{"Xname":"x0c0s0b0","StatusCode":200,"StatusMsg":"OK"},
+				{"Xname":"x0c0s1b0","Password":"pw","StatusCode":500,"StatusMsg":"failed"}]}`))
+		}
+	}
+	router := newRouter(routes)
+
+	operator := makeTestToken(t, key, authIssuer, time.Hour, []string{"scsd-operator"})
+	admin := makeTestToken(t, key, authIssuer, time.Hour, []string{"scsd-admin"})
+	expired := makeTestToken(t, key, authIssuer, -time.Hour, []string{"scsd-admin"})
+
+	send := func(method, url, token, pld string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(pld))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Forwarded-For", "10.1.1.1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	send(http.MethodPost, API_LOADCFG, operator, loadPld)
+	send(http.MethodGet, API_CREDS, admin, "")
+	send(http.MethodPost, API_CFG+"/x0c0s2b0", operator, `{"Params":{"NTPServerInfo":{"NTPServers":["ntp"]}}}`)
+	send(http.MethodGet, API_HEALTH, operator, "")
+	send(http.MethodPost, API_DUMPCFG, operator, `{"Targets":["x0c0s0b0"]}`)
+	send(http.MethodGet, API_CREDS, operator, "")
+	send(http.MethodGet, API_CREDS, expired, "")
+
+	w := send(http.MethodGet, API_AUDIT, operator, "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected operator audit query status %d, got %d", http.StatusForbidden, w.Code)
+	}
+
+	var rsp auditRsp
+	w = send(http.MethodGet, API_AUDIT, admin, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Audit query failed: %d %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &rsp)
+	if len(rsp.Records) != 6 {
+		t.Fatalf("Expected 6 audit records, got %d: %s", len(rsp.Records), w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "ssh-rsa") || strings.Contains(w.Body.String(), `"pw"`) {
+		t.Errorf("Secrets in audit records: %s", w.Body.String())
+	}
+
+	rec := rsp.Records[0]
+	if (rec.Subject != "tester") || (rec.SourceIP != "192.0.2.1") ||
+		(rec.ForwardedFor != "10.1.1.1") || (rec.Method != http.MethodPost) ||
+		(rec.Endpoint != API_LOADCFG) || (rec.StatusCode != http.StatusOK) ||
+		(rec.AuthResult != AuditAuthOK) {
+		t.Errorf("Unexpected loadcfg audit record: %+v", rec)
+	}
+	if (len(rec.Targets) != 2) || (rec.Targets[1] != "x0c0s1b0") {
+		t.Errorf("Unexpected loadcfg targets: %v", rec.Targets)
+	}
+	params, _ := rec.Fields["Params"].(map[string]interface{})
+	if (params["SyslogServer"] != "syslog1:514") || (params["SSHKey"] != secretRedacted) {
+		t.Errorf("Unexpected loadcfg fields: %v", rec.Fields)
+	}
+	if (len(rec.Outcomes) != 2) || (rec.Outcomes[1].Target != "x0c0s1b0") ||
+		(rec.Outcomes[1].StatusCode != 500) {
+		t.Errorf("Unexpected loadcfg outcomes: %+v", rec.Outcomes)
+	}
+	if rsp.Records[1].Endpoint != API_CREDS {
+		t.Errorf("Expected a creds audit record, got %+v", rsp.Records[1])
+	}
+	if rsp.Records[3].Endpoint != API_DUMPCFG {
+		t.Errorf("Expected a dumpcfg audit record, got %+v", rsp.Records[3])
+	}
+	rec = rsp.Records[4]
+	if (rec.Endpoint != API_CREDS) || (rec.StatusCode != http.StatusForbidden) ||
+		(rec.AuthResult != AuditAuthForbidden) || (rec.Subject != "tester") {
+		t.Errorf("Unexpected forbidden creds audit record: %+v", rec)
+	}
+	rec = rsp.Records[5]
+	if (rec.Endpoint != API_CREDS) || (rec.StatusCode != http.StatusUnauthorized) ||
+		(rec.AuthResult != AuditAuthInvalidToken) || (rec.Subject != "") ||
+		(rec.ClaimedSubject != "tester") {
+		t.Errorf("Unexpected unauthorized creds audit record: %+v", rec)
+	}
+
+	//Queries
+
+	tests := []struct {
+		query string
+		code  int
+		nrecs int
+	}{
+		{"?target=X0C0S2B0", http.StatusOK, 1},
+		{"?target=x0c0s1b0", http.StatusOK, 4},
+		{"?endpoint=" + API_CREDS, http.StatusOK, 3},
+		{"?subject=tester&limit=2", http.StatusOK, 2},
+		{"?subject=other", http.StatusOK, 0},
+		{"?since=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339), http.StatusOK, 0},
+		{"?until=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339), http.StatusOK, 6},
+		{"?since=yesterday", http.StatusBadRequest, 0},
+		{"?limit=0", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		w = send(http.MethodGet, API_AUDIT+tt.query, admin, "")
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.query, tt.code, w.Code)
+			continue
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		rsp = auditRsp{}
+		json.Unmarshal(w.Body.Bytes(), &rsp)
+		if len(rsp.Records) != tt.nrecs {
+			t.Errorf("%s: expected %d records, got %d", tt.query, tt.nrecs, len(rsp.Records))
+		}
+	}
+}
+
+// All calls but the audit query and the service health ones are audited,
+// whatever access level they need.
+
+func TestRouteAudited(t *testing.T) {
+	notAudited := map[string]bool{"doAuditGet": true, "doHealthGet": true,
+		"doLivenessGet": true, "doReadinessGet": true, "doVersionGet": true}
+	for _, route := range generateRoutes() {
+		if routeAudited(route) == notAudited[route.Name] {
+			t.Errorf("%s %s: expected audited %t", route.Method, route.Pattern,
+				!notAudited[route.Name])
+		}
+	}
+}
+
+// Forwarded identity headers are only recorded from trusted proxies.
+
+func TestAuditForwardedIdentity(t *testing.T) {
+	savedProxies := auditTrustedProxies
+	defer func() { auditTrustedProxies = savedProxies }()
+
+	const xfcc = `Hash=abcd;Subject="CN=client";URI=spiffe://shasta/ns/services/sa/scsd`
+	peer := &x509.Certificate{Subject: pkix.Name{CommonName: "peer"}}
+
+	tests := []struct {
+		name    string
+		proxies string
+		remote  string
+		peer    bool
+		cert    string
+		fwdFor  string
+	}{
+		{"no trusted proxies", "", "10.0.0.5:1234", false, "", ""},
+		{"untrusted client", "10.0.0.1, 10.1.0.0/16", "10.0.0.5:1234", false, "", ""},
+		{"trusted proxy IP", "10.0.0.1, 10.1.0.0/16", "10.0.0.1:1234", false,
+			"spiffe://shasta/ns/services/sa/scsd", "192.168.1.1"},
+		{"trusted proxy CIDR", "10.0.0.1, 10.1.0.0/16", "10.1.2.3:1234", false,
+			"spiffe://shasta/ns/services/sa/scsd", "192.168.1.1"},
+		{"bad entries", "bogus,10.0.0.0/99", "10.0.0.5:1234", false, "", ""},
+		{"TLS peer cert, untrusted", "", "10.0.0.5:1234", true, "CN=peer", ""},
+	}
+
+	for _, tt := range tests {
+		auditTrustedProxies = tt.proxies
+		req := httptest.NewRequest(http.MethodPost, "http://localhost"+API_LOADCFG, nil)
+		req.RemoteAddr = tt.remote
+		req.Header.Set("X-Forwarded-Client-Cert", xfcc)
+		req.Header.Set("X-Forwarded-For", "192.168.1.1")
+		if tt.peer {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{peer}}
+		}
+
+		if cert := auditClientCert(req); cert != tt.cert {
+			t.Errorf("%s: expected client cert '%s', got '%s'", tt.name, tt.cert, cert)
+		}
+		fwdFor := ""
+		if auditFromTrustedProxy(req) {
+			fwdFor = req.Header.Get("X-Forwarded-For")
+		}
+		if fwdFor != tt.fwdFor {
+			t.Errorf("%s: expected forwarded for '%s', got '%s'", tt.name, tt.fwdFor, fwdFor)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// API authentication and authorization.  If SCSD_AUTH_ENABLE is true,
+// every request except liveness and readiness probes must carry a bearer
+// JWT signed by a key in the JWKS given by SCSD_AUTH_JWKS (a file, or an
+// http(s) URL).  The roles found in the token's role claims are mapped to
+// the reader, operator and admin access levels, and each route requires
+// one of them:
+//
+//   reader:   GETs and POSTs which only read, e.g. dumpcfg, fetchcerts.
+//   operator: everything which changes BMCs or stored certs.
+//   admin:    reading or setting creds and BIOS passwords, changing
+//             SCSD's parameters, and reading the audit log.
+
+type authLevel int
+
+const (
+	authNone authLevel = iota
+	authReader
+	authOperator
+	authAdmin
+)
+
+func (l authLevel) String() string {
+	switch l {
+	case authReader:
+		return "reader"
+	case authOperator:
+		return "operator"
+	case authAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+var authEnable = false
+var authJWKS = ""
+var authIssuer = ""
+var authAudience = ""
+var authRolesClaims = "roles,realm_access.roles"
+var authReaderRoles = "scsd-reader"
+var authOperatorRoles = "scsd-operator"
+var authAdminRoles = "scsd-admin"
+
+// The JWKS is fetched again when a token is signed with an unknown key,
+// but not more often than this.
+
+const authJWKSRefreshTime = time.Minute
+
+var authSigAlgs = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512, jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA}
+
+// Routes which don't need the default level.  Otherwise GETs need reader
+// and everything else operator.
+
+var authRouteLevels = map[string]authLevel{
+	"doLivenessGet":            authNone,
+	"doReadinessGet":           authNone,
+	"doParamsPatch":            authAdmin,
+	"doCredsGet":               authAdmin,
+	"doDiscreetCredsPost":      authAdmin,
+	"doGlobalCredsPost":        authAdmin,
+	"doCredsPostOne":           authAdmin,
+	"doBiosPasswordPost":       authAdmin,
+	"doBiosPasswordBulkPost":   authAdmin,
+	"doAuditGet":               authAdmin,
+	"doDumpCfgPost":            authReader,
+	"doBMCFetchCerts":          authReader,
+	"doBMCCertExpiryPost":      authReader,
+	"doBMCFetchTrustCertsPost": authReader,
+	"doBiosComparePost":        authReader,
+	"doBiosTpmStateDumpPost":   authReader,
+	"doBiosSecureBootDumpPost": authReader,
+	"doBiosFeatureDumpPost":    authReader,
+}
+
+// Who made a request, stored in its context once authorized.
+
+type authInfo struct {
+	Subject string
+	Roles   []string
+	Level   authLevel
+}
+
+type authCtxKeyType int
+
+const authCtxKey authCtxKeyType = 0
+
+var authLock sync.Mutex
+var authKeys *jose.JSONWebKeySet
+var authKeysTime time.Time
+var authClient = &http.Client{Timeout: 10 * time.Second}
+
+// Returns the access level a route needs.
+
+func routeAuthLevel(route Route) authLevel {
+	if lvl, ok := authRouteLevels[route.Name]; ok {
+		return lvl
+	}
+	if route.Method == http.MethodGet {
+		return authReader
+	}
+	return authOperator
+}
+
+// Returns the auth info of a request, or nil if it has none.
+
+func getAuthInfo(r *http.Request) *authInfo {
+	info, _ := r.Context().Value(authCtxKey).(*authInfo)
+	return info
+}
+
+// Read the JWKS from SCSD_AUTH_JWKS.
+
+func fetchJWKS() (*jose.JSONWebKeySet, error) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(authJWKS, "http://") || strings.HasPrefix(authJWKS, "https://") {
+		rsp, rerr := authClient.Get(authJWKS)
+		if rerr != nil {
+			return nil, rerr
+		}
+		defer rsp.Body.Close()
+		if !statusCodeOK(rsp.StatusCode) {
+			return nil, fmt.Errorf("Bad return status from '%s': %d",
+				authJWKS, rsp.StatusCode)
+		}
+		data, err = ioutil.ReadAll(rsp.Body)
+	} else {
+		data, err = ioutil.ReadFile(strings.TrimPrefix(authJWKS, "file://"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks jose.JSONWebKeySet
+	err = json.Unmarshal(data, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse JWKS from '%s': %v", authJWKS, err)
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, fmt.Errorf("No keys in JWKS from '%s'", authJWKS)
+	}
+	return &jwks, nil
+}
+
+// Returns the JWKS, fetching it if it was never fetched, or if refresh is
+// set and it wasn't fetched recently.
+
+func getJWKS(refresh bool) (*jose.JSONWebKeySet, error) {
+	authLock.Lock()
+	defer authLock.Unlock()
+
+	if (authKeys != nil) &&
+		(!refresh || (time.Since(authKeysTime) < authJWKSRefreshTime)) {
+		return authKeys, nil
+	}
+	if (authKeys == nil) && (time.Since(authKeysTime) < time.Second) {
+		return nil, fmt.Errorf("No JWKS loaded")
+	}
+	authKeysTime = time.Now()
+	jwks, err := fetchJWKS()
+	if err != nil {
+		logger.Errorf("Can't load JWKS: %v", err)
+		if authKeys == nil {
+			return nil, fmt.Errorf("No JWKS loaded")
+		}
+		return authKeys, nil
+	}
+	authKeys = jwks
+	return authKeys, nil
+}
+
+// Find the roles in a claim given by a dotted path, e.g.
+// "realm_access.roles".  The claim can be a list of strings or a space
+// separated string.
+
+func claimRoles(claims map[string]interface{}, path string) []string {
+	var val interface{} = claims
+	for _, elem := range strings.Split(path, ".") {
+		mval, ok := val.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		val = mval[elem]
+	}
+
+	var roles []string
+	switch tval := val.(type) {
+	case string:
+		roles = strings.Fields(tval)
+	case []interface{}:
+		for _, v := range tval {
+			if s, ok := v.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	}
+	return roles
+}
+
+func roleInList(role string, list string) bool {
+	for _, r := range strings.Split(list, ",") {
+		if strings.TrimSpace(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the highest access level of a set of token roles.
+
+func rolesAuthLevel(roles []string) authLevel {
+	lvl := authNone
+	for _, role := range roles {
+		switch {
+		case roleInList(role, authAdminRoles):
+			return authAdmin
+		case roleInList(role, authOperatorRoles):
+			lvl = authOperator
+		case roleInList(role, authReaderRoles):
+			if lvl < authReader {
+				lvl = authReader
+			}
+		}
+	}
+	return lvl
+}
+
+// Validate a bearer token and return who it belongs to.
+
+func checkAuthToken(raw string) (*authInfo, error) {
+	tok, err := jwt.ParseSigned(raw, authSigAlgs)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse token: %v", err)
+	}
+	jwks, err := getJWKS(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var std jwt.Claims
+	var claims map[string]interface{}
+	err = tok.Claims(jwks, &std, &claims)
+	if errors.Is(err, jose.ErrJWKSKidNotFound) {
+		jwks, err = getJWKS(true)
+		if err == nil {
+			err = tok.Claims(jwks, &std, &claims)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid token signature: %v", err)
+	}
+
+	if std.Expiry == nil {
+		return nil, fmt.Errorf("Token has no expiration time")
+	}
+	exp := jwt.Expected{Issuer: authIssuer, Time: time.Now()}
+	if authAudience != "" {
+		exp.AnyAudience = jwt.Audience{authAudience}
+	}
+	err = std.Validate(exp)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid token: %v", err)
+	}
+
+	info := &authInfo{Subject: std.Subject}
+	for _, path := range strings.Split(authRolesClaims, ",") {
+		info.Roles = append(info.Roles, claimRoles(claims, strings.TrimSpace(path))...)
+	}
+	info.Level = rolesAuthLevel(info.Roles)
+	return info, nil
+}
+
+// Returns the subject a token claims, without validating it, or "" if it
+// can't be parsed.  Only for the audit log of rejected calls.
+
+func claimedSubject(raw string) string {
+	tok, err := jwt.ParseSigned(raw, authSigAlgs)
+	if err != nil {
+		return ""
+	}
+	var std jwt.Claims
+	if tok.UnsafeClaimsWithoutVerification(&std) != nil {
+		return ""
+	}
+	return std.Subject
+}
+
+// Wrap a route's handler so it is only called for requests with a valid
+// token whose roles give at least the level the route needs.
+
+func authHandler(route Route, handler http.Handler) http.Handler {
+	need := routeAuthLevel(route)
+	if need == authNone {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hdr := r.Header.Get("Authorization")
+		if !strings.HasPrefix(hdr, "Bearer ") {
+			auditAuthResult(r, AuditAuthNoToken, "", "")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scsd"`)
+			sendErrorRsp(w, "Unauthorized", "Missing bearer token",
+				r.URL.Path, http.StatusUnauthorized)
+			return
+		}
+		raw := strings.TrimSpace(strings.TrimPrefix(hdr, "Bearer "))
+		info, err := checkAuthToken(raw)
+		if err != nil {
+			auditAuthResult(r, AuditAuthInvalidToken, "", claimedSubject(raw))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scsd", error="invalid_token"`)
+			sendErrorRsp(w, "Unauthorized", err.Error(),
+				r.URL.Path, http.StatusUnauthorized)
+			return
+		}
+		if info.Level < need {
+			auditAuthResult(r, AuditAuthForbidden, info.Subject, "")
+			sendErrorRsp(w, "Forbidden",
+				fmt.Sprintf("'%s' has %s access, %s %s needs %s access",
+					info.Subject, info.Level, r.Method, r.URL.Path, need),
+				r.URL.Path, http.StatusForbidden)
+			return
+		}
+		auditAuthResult(r, AuditAuthOK, info.Subject, "")
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authCtxKey, info)))
+	})
+}
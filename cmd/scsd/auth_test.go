@@ -0,0 +1,216 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+type testAuthKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestAuthKey(t *testing.T, kid string) testAuthKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testAuthKey{kid: kid, key: key}
+}
+
+func writeTestJWKS(t *testing.T, fname string, keys ...testAuthKey) {
+	var jwks jose.JSONWebKeySet
+	for _, k := range keys {
+		jwks.Keys = append(jwks.Keys, jose.JSONWebKey{Key: &k.key.PublicKey,
+			KeyID: k.kid, Algorithm: string(jose.RS256), Use: "sig"})
+	}
+	ba, _ := json.Marshal(&jwks)
+	err := ioutil.WriteFile(fname, ba, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func makeTestToken(t *testing.T, k testAuthKey, iss string, exp time.Duration,
+	roles []string) string {
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: k.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", k.kid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := jwt.Claims{Subject: "tester", Issuer: iss,
+		Expiry: jwt.NewNumericDate(time.Now().Add(exp))}
+	extra := map[string]interface{}{"realm_access": map[string]interface{}{"roles": roles}}
+	tok, err := jwt.Signed(sig).Claims(std).Claims(extra).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+func TestRouteAuthLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		level  authLevel
+	}{
+		{"doLivenessGet", http.MethodGet, authNone},
+		{"doHealthGet", http.MethodGet, authReader},
+		{"doDumpCfgPost", http.MethodPost, authReader},
+		{"doLoadCfgPost", http.MethodPost, authOperator},
+		{"doBMCSetCertsPost", http.MethodPost, authOperator},
+		{"doBMCTLSPinDelete", http.MethodDelete, authOperator},
+		{"doCredsGet", http.MethodGet, authAdmin},
+		{"doParamsPatch", http.MethodPatch, authAdmin},
+	}
+
+	for _, tt := range tests {
+		lvl := routeAuthLevel(Route{Name: tt.name, Method: tt.method})
+		if lvl != tt.level {
+			t.Errorf("%s: expected level %s, got %s", tt.name, tt.level, lvl)
+		}
+	}
+
+	claims := map[string]interface{}{
+		"scope":        "openid scsd-reader",
+		"realm_access": map[string]interface{}{"roles": []interface{}{"x", "scsd-operator"}},
+	}
+	if roles := claimRoles(claims, "scope"); len(roles) != 2 || roles[1] != "scsd-reader" {
+		t.Errorf("Unexpected scope roles: %v", roles)
+	}
+	if roles := claimRoles(claims, "realm_access.roles"); len(roles) != 2 || roles[1] != "scsd-operator" {
+		t.Errorf("Unexpected realm roles: %v", roles)
+	}
+	if roles := claimRoles(claims, "resource_access.scsd.roles"); len(roles) != 0 {
+		t.Errorf("Unexpected missing claim roles: %v", roles)
+	}
+	if lvl := rolesAuthLevel([]string{"scsd-reader", "scsd-operator"}); lvl != authOperator {
+		t.Errorf("Expected operator level, got %s", lvl)
+	}
+}
+
+func TestAuthHandler(t *testing.T) {
+	loggerSetup()
+	savedEnable, savedJWKS, savedIssuer := authEnable, authJWKS, authIssuer
+	defer func() {
+		authEnable, authJWKS, authIssuer = savedEnable, savedJWKS, savedIssuer
+		authKeys, authKeysTime = nil, time.Time{}
+	}()
+
+	key1 := newTestAuthKey(t, "key1")
+	key2 := newTestAuthKey(t, "key2")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeTestJWKS(t, jwksFile, key1)
+
+	authEnable = true
+	authJWKS = jwksFile
+	authIssuer = "https://auth.local"
+	authKeys, authKeysTime = nil, time.Time{}
+
+	//Route handlers are replaced, only the auth layer is tested.
+
+	routes := generateRoutes()
+	for ix := range routes {
+		routes[ix].HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			info := getAuthInfo(r)
+			if (r.URL.Path != API_LIVENESS) && ((info == nil) || (info.Subject != "tester")) {
+				t.Errorf("No auth info for %s", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+	router := newRouter(routes)
+
+	reader := makeTestToken(t, key1, authIssuer, time.Hour, []string{"scsd-reader"})
+	operator := makeTestToken(t, key1, authIssuer, time.Hour, []string{"scsd-operator"})
+	admin := makeTestToken(t, key1, authIssuer, time.Hour, []string{"scsd-admin"})
+	norole := makeTestToken(t, key1, authIssuer, time.Hour, []string{"other"})
+	expired := makeTestToken(t, key1, authIssuer, -time.Hour, []string{"scsd-admin"})
+	badIss := makeTestToken(t, key1, "https://other", time.Hour, []string{"scsd-admin"})
+	newKey := makeTestToken(t, key2, authIssuer, time.Hour, []string{"scsd-admin"})
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		token  string
+		code   int
+	}{
+		{"liveness, no token", http.MethodGet, API_LIVENESS, "", http.StatusOK},
+		{"health, no token", http.MethodGet, API_HEALTH, "", http.StatusUnauthorized},
+		{"health, bad token", http.MethodGet, API_HEALTH, "abc", http.StatusUnauthorized},
+		{"health, reader", http.MethodGet, API_HEALTH, reader, http.StatusOK},
+		{"health, no role", http.MethodGet, API_HEALTH, norole, http.StatusForbidden},
+		{"dumpcfg, reader", http.MethodPost, API_DUMPCFG, reader, http.StatusOK},
+		{"loadcfg, reader", http.MethodPost, API_LOADCFG, reader, http.StatusForbidden},
+		{"loadcfg, operator", http.MethodPost, API_LOADCFG, operator, http.StatusOK},
+		{"setcerts, operator", http.MethodPost, API_SET_CERTS, operator, http.StatusOK},
+		{"creds, operator", http.MethodGet, API_CREDS, operator, http.StatusForbidden},
+		{"creds, admin", http.MethodGet, API_CREDS, admin, http.StatusOK},
+		{"params, operator", http.MethodPatch, API_PARAMS, operator, http.StatusForbidden},
+		{"creds, expired", http.MethodGet, API_CREDS, expired, http.StatusUnauthorized},
+		{"creds, wrong issuer", http.MethodGet, API_CREDS, badIss, http.StatusUnauthorized},
+		{"creds, unknown key", http.MethodGet, API_CREDS, newKey, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.code,
+				w.Code, w.Body.String())
+		}
+		if (w.Code == http.StatusUnauthorized) && (w.Header().Get("WWW-Authenticate") == "") {
+			t.Errorf("%s: no WWW-Authenticate header", tt.name)
+		}
+	}
+
+	//Key rotation: the JWKS is fetched again for an unknown key.
+
+	writeTestJWKS(t, jwksFile, key1, key2)
+	authKeysTime = time.Now().Add(-2 * authJWKSRefreshTime)
+	req := httptest.NewRequest(http.MethodGet, API_CREDS, nil)
+	req.Header.Set("Authorization", "Bearer "+newKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Rotated key: expected status %d, got %d: %s", http.StatusOK,
+			w.Code, w.Body.String())
+	}
+}
@@ -97,6 +97,7 @@ type BiosTpmState struct {
 
 type BiosTpmStatePatch struct {
 	Future TpmState `json:"Future"`
+	BiosApplyOptions
 }
 
 // SCSD rest interface values
@@ -116,8 +117,17 @@ const (
 	TpmStateAttributeGigabyte BiosAttributeName = "TPM State"
 	TpmStateAttributeHpe      BiosAttributeName = "TpmState"
 	TpmStateAttributeIntel    BiosAttributeName = "TpmOperation"
+	Tpm2StateAttributeIntel   BiosAttributeName = "Tpm2Operation"
 )
 
+// Intel BIOSes expose the TPM under either TpmOperation or Tpm2Operation
+// depending on the TPM module installed, so a patch of one of these
+// attributes applies to whichever of them the BIOS reports.
+var intelAttributeAliases = map[BiosAttributeName][]BiosAttributeName{
+	TpmStateAttributeIntel:  {TpmStateAttributeIntel, Tpm2StateAttributeIntel},
+	Tpm2StateAttributeIntel: {TpmStateAttributeIntel, Tpm2StateAttributeIntel},
+}
+
 // BIOS Attribute Values from the redfish interface
 const (
 	EnabledCray      string = "Enabled"
@@ -144,23 +154,56 @@ type rfSystemsMember struct {
 }
 
 type rfSystem struct {
-	Bios rfBios `json:"Bios"`
+	Bios       rfBios           `json:"Bios"`
+	SecureBoot rfSecureBootLink `json:"SecureBoot"`
+	Actions    rfSystemActions  `json:"Actions"`
+}
+
+type rfSecureBootLink struct {
+	ID string `json:"@odata.id"`
+}
+
+type rfSystemActions struct {
+	Reset rfSystemResetAction `json:"#ComputerSystem.Reset"`
+}
+
+type rfSystemResetAction struct {
+	Target     string   `json:"target"`
+	ResetTypes []string `json:"ResetType@Redfish.AllowableValues"`
 }
 
 type rfBios struct {
 	ID string `json:"@odata.id"`
 }
 
+/*
+Example for rfBiosIntel from /redfish/v1/Systems/BQWF73500342/Bios
+The Attributes from /redfish/v1/Systems/BQWF73500342/Bios/Settings only
+contain the values that are going to change on reboot.
+{
+  "@Redfish.Settings": {
+    "@odata.type": "#Settings.v1_0_0.Settings",
+    "SettingsObject": {
+      "@odata.id": "/redfish/v1/Systems/BQWF73500342/Bios/Settings"
+    }
+  },
+  "@odata.etag": "W/\"1617062400\"",
+  "Attributes": {
+    "TpmOperation": 1,
+    "Tpm2Operation": 0,
+	...
+  }
+}
+*/
 type rfBiosIntel struct {
-	Attributes map[string]interface{}
+	Settings   rfRedfishSettings      `json:"@Redfish.Settings"`
+	ETag       string                 `json:"@odata.etag"`
+	Attributes map[string]interface{} `json:"Attributes"` // the values are usually ints
 }
 
 type rfBiosHpe struct {
-	Attributes rfBiosHpeAttributes
-}
-
-type rfBiosHpeAttributes struct {
-	TpmState string
+	Settings   rfRedfishSettings      `json:"@Redfish.Settings"`
+	Attributes map[string]interface{} `json:"Attributes"`
 }
 
 type PatchAttributeName struct {
@@ -177,6 +220,29 @@ type PatchAttributeValue struct {
 	intel    interface{}
 }
 
+// Returns the attribute name for the manufacturer, empty when the attribute
+// has no mapping for it
+func (name *PatchAttributeName) forVendor(m manufacturerType) BiosAttributeName {
+	switch m {
+	case cray:
+		return name.cray
+	case gigabyte:
+		return name.gigabyte
+	case hpe:
+		return name.hpe
+	case intel:
+		return name.intel
+	}
+	return ""
+}
+
+// A vendor specific redfish patch of the future BIOS settings
+type biosPatchRequest struct {
+	uri  string
+	body []byte
+	etag string // If-Match header, not sent when empty
+}
+
 /*
 Example for rfBiosGigabyte from /redfish/v1/Systems/Self/Bios
 {
@@ -242,6 +308,7 @@ Example for rfBiosCray
 }
 */
 type rfBiosCray struct {
+	Settings   rfRedfishSettings              `json:"@Redfish.Settings"`
 	ETag       string                         `json:"@odata.etag"`
 	Attributes map[string]rfBiosAttributeCray `json:"Attributes"`
 }
@@ -272,8 +339,9 @@ type rfBiosSDCray struct {
 }
 
 type rfRedfishSettings struct {
-	Type           string           `json:"@odata.type"`
-	SettingsObject rfSettingsObject `json:"SettingsObject"`
+	Type                string           `json:"@odata.type"`
+	SettingsObject      rfSettingsObject `json:"SettingsObject"`
+	SupportedApplyTimes []string         `json:"SupportedApplyTimes"`
 }
 
 type rfSettingsObject struct {
@@ -345,6 +413,22 @@ const (
 	intel
 )
 
+// The manufacturer names used as keys in the BIOS feature catalog
+var manufacturerNames = map[manufacturerType]string{
+	cray:     "cray",
+	gigabyte: "gigabyte",
+	hpe:      "hpe",
+	intel:    "intel",
+}
+
+func (m manufacturerType) String() string {
+	name, ok := manufacturerNames[m]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
 func toXnames(targets []targInfo) []string {
 	xnames := make([]string, len(targets), len(targets))
 	for i, target := range targets {
@@ -495,6 +579,28 @@ func parseResponseForHost(description string, host string, tasks []trsapi.HttpTa
 	return
 }
 
+func patchBiosRequest(biosCommon *BiosCommon, request *biosPatchRequest) (err error, httpCode int) {
+	etags := []string{}
+	if request.etag != "" {
+		etags = append(etags, request.etag)
+	}
+	tasks, err, httpCode := patchRedfishEtag(biosCommon.targets, request.uri, request.body, etags)
+	if err != nil {
+		return
+	}
+
+	for _, task := range tasks {
+		statusCode := getStatusCode(&task)
+		if !statusCodeOK(statusCode) {
+			err = fmt.Errorf("ERROR: Redfish patch failed %s %d", request.uri, statusCode)
+			httpCode = http.StatusInternalServerError
+			return
+		}
+	}
+
+	return
+}
+
 func getSystemUri(xname string, nodeNumber int, manufacturer manufacturerType, systems *rfSystems) (uri string, err error, httpCode int) {
 	httpCode = http.StatusOK
 	switch manufacturer {
@@ -609,6 +715,20 @@ func getAttribute(name string, attributes *rfBiosAttributesRegistry) (attribute
 	return attribute, false
 }
 
+// Returns the uri of the future BIOS settings. The settings object advertised
+// in the current BIOS settings is used when the BMC provides one.
+func getBiosFutureUri(biosCommon *BiosCommon, settings *rfRedfishSettings) string {
+	if settings != nil && settings.SettingsObject.ID != "" {
+		return settings.SettingsObject.ID
+	}
+	switch biosCommon.manufacturerType {
+	case cray, gigabyte:
+		return biosCommon.biosUri + "/SD"
+	default:
+		return biosCommon.biosUri + "/Settings"
+	}
+}
+
 func getBiosCommon(xname string) (bios *BiosCommon, err error, httpCode int) {
 	httpCode = http.StatusOK
 	bios = &BiosCommon{
@@ -700,7 +820,7 @@ func getBiosHpe(biosCommon *BiosCommon) (biosHpe *BiosHpe, err error, httpCode i
 
 	// ---- /redfish/v1/Systems/1/Bios/Settings ----
 
-	biosHpe.futureUri = biosCommon.biosUri + "/Settings"
+	biosHpe.futureUri = getBiosFutureUri(biosCommon, nil)
 
 	var future rfBiosHpe
 	err, httpCode = getRedfishAndParseResponse(
@@ -713,6 +833,25 @@ func getBiosHpe(biosCommon *BiosCommon) (biosHpe *BiosHpe, err error, httpCode i
 	return
 }
 
+func getBiosRegistryUriHpe(registries *rfRegistries) string {
+	for _, member := range registries.Members {
+		// looking for a uri like: /redfish/v1/Registries/BiosAttributeRegistryA43.v1_2_40
+		if strings.HasPrefix(strings.ToLower(member.ID), "/redfish/v1/registries/biosattributeregistry") {
+			return member.ID
+		}
+	}
+	return ""
+}
+
+func getBiosRegistryEnUriHpe(biosAttributesRegistries *rfBiosAttributesRegistries) string {
+	for _, location := range biosAttributesRegistries.Location {
+		if strings.ToLower(location.Language) == "en" {
+			return location.Uri
+		}
+	}
+	return ""
+}
+
 func getBiosRegistriesHpe(biosCommon *BiosCommon) (biosRegistries *BiosHpeRegistries, err error, httpCode int) {
 	httpCode = http.StatusOK
 	biosRegistries = &BiosHpeRegistries{}
@@ -727,13 +866,7 @@ func getBiosRegistriesHpe(biosCommon *BiosCommon) (biosRegistries *BiosHpeRegist
 	}
 	biosRegistries.registries = &registries
 
-	for _, member := range biosRegistries.registries.Members {
-		// looking for a uri like: /redfish/v1/Registries/BiosAttributeRegistryA43.v1_2_40
-		if strings.HasPrefix(strings.ToLower(member.ID), "/redfish/v1/registries/biosattributeregistry") {
-			biosRegistries.biosRegistryUri = member.ID
-			break
-		}
-	}
+	biosRegistries.biosRegistryUri = getBiosRegistryUriHpe(biosRegistries.registries)
 
 	if biosRegistries.biosRegistryUri == "" {
 		err = fmt.Errorf(
@@ -753,12 +886,7 @@ func getBiosRegistriesHpe(biosCommon *BiosCommon) (biosRegistries *BiosHpeRegist
 	}
 	biosRegistries.biosAttributesRegistries = &biosAttributesRegistries
 
-	for _, location := range biosAttributesRegistries.Location {
-		if strings.ToLower(location.Language) == "en" {
-			biosRegistries.biosRegistryEnUri = location.Uri
-			break
-		}
-	}
+	biosRegistries.biosRegistryEnUri = getBiosRegistryEnUriHpe(&biosAttributesRegistries)
 
 	if biosRegistries.biosRegistryEnUri == "" {
 		err = fmt.Errorf(
@@ -781,20 +909,12 @@ func getBiosRegistriesHpe(biosCommon *BiosCommon) (biosRegistries *BiosHpeRegist
 	return
 }
 
-func patchBiosHpe(biosCommon *BiosCommon, name PatchAttributeName, value PatchAttributeValue) (err error, httpCode int) {
-	biosHpe, err, httpCode := getBiosHpe(biosCommon)
-	if err != nil {
-		return
-	}
+func makePatchRequestHpe(biosHpe *BiosHpe, biosHpeRegistries *BiosHpeRegistries, name PatchAttributeName, value PatchAttributeValue) (request *biosPatchRequest, err error, httpCode int) {
+	httpCode = http.StatusOK
 
 	futureValue := fmt.Sprintf("%v", value.hpe)
 	attributeName := string(name.hpe)
 
-	biosHpeRegistries, err, httpCode := getBiosRegistriesHpe(biosCommon)
-	if err != nil {
-		return
-	}
-
 	hardwareSupportsFutureValue := false
 	for _, attribute := range biosHpeRegistries.biosAttributes.RegistryEntries.Attributes {
 		if strings.ToLower(attribute.AttributeName) == strings.ToLower(attributeName) {
@@ -818,19 +938,35 @@ func patchBiosHpe(biosCommon *BiosCommon, name PatchAttributeName, value PatchAt
 
 	rfRequestBody := "{\"Attributes\":{\"" + attributeName + "\":\"" + futureValue + "\"}}"
 
-	tasks, err, httpCode := patchRedfish(biosCommon.targets, biosHpe.futureUri, []byte(rfRequestBody))
+	request = &biosPatchRequest{
+		uri:  biosHpe.futureUri,
+		body: []byte(rfRequestBody),
+	}
+	return
+}
+
+func patchBiosHpe(biosCommon *BiosCommon, name PatchAttributeName, value PatchAttributeValue, applyTime string) (err error, httpCode int) {
+	biosHpe, err, httpCode := getBiosHpe(biosCommon)
 	if err != nil {
 		return
 	}
 
-	for _, task := range tasks {
-		statusCode := getStatusCode(&task)
-		if !statusCodeOK(statusCode) {
-			err = fmt.Errorf("ERROR: Redfish patch failed %s %d", biosHpe.futureUri, statusCode)
-			httpCode = http.StatusInternalServerError
-			return
-		}
+	biosHpeRegistries, err, httpCode := getBiosRegistriesHpe(biosCommon)
+	if err != nil {
+		return
+	}
+
+	request, err, httpCode := makePatchRequestHpe(biosHpe, biosHpeRegistries, name, value)
+	if err != nil {
+		return
+	}
+
+	err, httpCode = setBiosApplyTime(request, &biosHpe.current.Settings, applyTime)
+	if err != nil {
+		return
 	}
+
+	err, httpCode = patchBiosRequest(biosCommon, request)
 	return
 }
 
@@ -850,10 +986,7 @@ func getBiosGigabyte(biosCommon *BiosCommon) (biosGigabyte *BiosGigabyte, err er
 
 	// ---- /redfish/v1/Systems/Self/Bios/SD ----
 
-	biosGigabyte.futureUri = biosGigabyte.current.Settings.SettingsObject.ID
-	if biosGigabyte.futureUri == "" {
-		biosGigabyte.futureUri = biosCommon.biosUri + "/SD"
-	}
+	biosGigabyte.futureUri = getBiosFutureUri(biosCommon, &biosGigabyte.current.Settings)
 
 	biosFutureTasks, codes, err, httpCode := getRedfishNoCheck(biosCommon.targets, biosGigabyte.futureUri)
 	if err != nil {
@@ -893,11 +1026,9 @@ func getBiosGigabyte(biosCommon *BiosCommon) (biosGigabyte *BiosGigabyte, err er
 
 	// ---- /redfish/v1/Registries/BiosAttributeRegistry.json ----
 
-	registryUri := "/redfish/v1/Registries/BiosAttributeRegistry.json"
-
 	var biosAttributes rfBiosAttributesRegistry
 	err, httpCode = getRedfishAndParseResponse(
-		"BiosAttributeRegistry.json", biosCommon.bmcXname, biosCommon.targets, registryUri, &biosAttributes)
+		"BiosAttributeRegistry.json", biosCommon.bmcXname, biosCommon.targets, GB_BIOSREG_API, &biosAttributes)
 	if err != nil {
 		return
 	}
@@ -906,8 +1037,8 @@ func getBiosGigabyte(biosCommon *BiosCommon) (biosGigabyte *BiosGigabyte, err er
 	return
 }
 
-func patchBiosGigabyte(biosCommon *BiosCommon, attributeName PatchAttributeName, attrbiuteValue PatchAttributeValue) (err error, httpCode int) {
-	biosGigabyte, err, httpCode := getBiosGigabyte(biosCommon)
+func makePatchRequestGigabyte(biosGigabyte *BiosGigabyte, attributeName PatchAttributeName, attrbiuteValue PatchAttributeValue) (request *biosPatchRequest, err error, httpCode int) {
+	httpCode = http.StatusOK
 
 	name := string(attributeName.gigabyte)
 	futureValue := fmt.Sprintf("%v", attrbiuteValue.gigabyte)
@@ -943,20 +1074,32 @@ func patchBiosGigabyte(biosCommon *BiosCommon, attributeName PatchAttributeName,
 		// gigabyte will reject any patch request that does not have a If-Match header
 		etag = "*"
 	}
-	tasks, err, httpCode := patchRedfishEtag(biosCommon.targets, biosGigabyte.futureUri, []byte(rfRequestBody), []string{etag})
+
+	request = &biosPatchRequest{
+		uri:  biosGigabyte.futureUri,
+		body: []byte(rfRequestBody),
+		etag: etag,
+	}
+	return
+}
+
+func patchBiosGigabyte(biosCommon *BiosCommon, attributeName PatchAttributeName, attrbiuteValue PatchAttributeValue, applyTime string) (err error, httpCode int) {
+	biosGigabyte, err, httpCode := getBiosGigabyte(biosCommon)
 	if err != nil {
 		return
 	}
 
-	for _, task := range tasks {
-		statusCode := getStatusCode(&task)
-		if !statusCodeOK(statusCode) {
-			err = fmt.Errorf("ERROR: Redfish patch failed %s %d", biosGigabyte.futureUri, statusCode)
-			httpCode = http.StatusInternalServerError
-			return
-		}
+	request, err, httpCode := makePatchRequestGigabyte(biosGigabyte, attributeName, attrbiuteValue)
+	if err != nil {
+		return
+	}
+
+	err, httpCode = setBiosApplyTime(request, &biosGigabyte.current.Settings, applyTime)
+	if err != nil {
+		return
 	}
 
+	err, httpCode = patchBiosRequest(biosCommon, request)
 	return
 }
 
@@ -978,7 +1121,7 @@ func getBiosCray(biosCommon *BiosCommon) (biosCray *BiosCray, err error, httpCod
 	// ---- /redfish/v1/Systems/Node0/Bios/SD ----
 	// ---- /redfish/v1/Systems/Node1/Bios/SD ----
 
-	biosCray.futureUri = biosCommon.biosUri + "/SD"
+	biosCray.futureUri = getBiosFutureUri(biosCommon, nil)
 
 	biosFutureTasks, codes, err, httpCode := getRedfishNoCheck(biosCommon.targets, biosCray.futureUri)
 	if err != nil {
@@ -1019,11 +1162,8 @@ func getBiosCray(biosCommon *BiosCommon) (biosCray *BiosCray, err error, httpCod
 	return
 }
 
-func patchBiosCray(biosCommon *BiosCommon, attributeName PatchAttributeName, attrbiuteValue PatchAttributeValue) (err error, httpCode int) {
-	biosCray, err, httpCode := getBiosCray(biosCommon)
-	if err != nil {
-		return
-	}
+func makePatchRequestCray(biosCray *BiosCray, attributeName PatchAttributeName, attrbiuteValue PatchAttributeValue) (request *biosPatchRequest, err error, httpCode int) {
+	httpCode = http.StatusOK
 
 	name := string(attributeName.cray)
 	futureValue := fmt.Sprintf("%v", attrbiuteValue.cray)
@@ -1059,20 +1199,32 @@ func patchBiosCray(biosCommon *BiosCommon, attributeName PatchAttributeName, att
 		// This is not strictly required because cray hardware does not currently require the etag
 		etag = "*"
 	}
-	tasks, err, httpCode := patchRedfishEtag(biosCommon.targets, biosCray.futureUri, []byte(rfRequestBody), []string{etag})
+
+	request = &biosPatchRequest{
+		uri:  biosCray.futureUri,
+		body: []byte(rfRequestBody),
+		etag: etag,
+	}
+	return
+}
+
+func patchBiosCray(biosCommon *BiosCommon, attributeName PatchAttributeName, attrbiuteValue PatchAttributeValue, applyTime string) (err error, httpCode int) {
+	biosCray, err, httpCode := getBiosCray(biosCommon)
 	if err != nil {
 		return
 	}
 
-	for _, task := range tasks {
-		statusCode := getStatusCode(&task)
-		if !statusCodeOK(statusCode) {
-			err = fmt.Errorf("ERROR: Redfish patch failed %s %d", biosCray.futureUri, statusCode)
-			httpCode = http.StatusInternalServerError
-			return
-		}
+	request, err, httpCode := makePatchRequestCray(biosCray, attributeName, attrbiuteValue)
+	if err != nil {
+		return
+	}
+
+	err, httpCode = setBiosApplyTime(request, &biosCray.current.Settings, applyTime)
+	if err != nil {
+		return
 	}
 
+	err, httpCode = patchBiosRequest(biosCommon, request)
 	return
 }
 
@@ -1082,7 +1234,6 @@ func getBiosIntel(biosCommon *BiosCommon) (biosIntel *BiosIntel, err error, http
 	biosIntel = &BiosIntel{}
 	biosIntel.current = &rfBiosIntel{}
 	biosIntel.future = &rfBiosIntel{}
-	biosIntel.futureUri = biosCommon.biosUri + "/Settings"
 
 	// ---- /redfish/v1/Systems/BQWF73500342/Bios ----
 
@@ -1092,6 +1243,8 @@ func getBiosIntel(biosCommon *BiosCommon) (biosIntel *BiosIntel, err error, http
 		return
 	}
 
+	biosIntel.futureUri = getBiosFutureUri(biosCommon, &biosIntel.current.Settings)
+
 	// ---- /redfish/v1/Systems/BQWF73500342/Bios/Settings ----
 
 	err, httpCode = getRedfishAndParseResponse(
@@ -1103,6 +1256,99 @@ func getBiosIntel(biosCommon *BiosCommon) (biosIntel *BiosIntel, err error, http
 	return
 }
 
+// Returns the attribute names present in the Intel BIOS that a patch of the
+// given attribute should be applied to.
+func getAttributeNamesIntel(name BiosAttributeName, attributes map[string]interface{}) (names []string) {
+	candidates, ok := intelAttributeAliases[name]
+	if !ok {
+		candidates = []BiosAttributeName{name}
+	}
+	for _, candidate := range candidates {
+		if _, found := attributes[string(candidate)]; found {
+			names = append(names, string(candidate))
+		}
+	}
+	return
+}
+
+// Converts the value to the same JSON type as the current value of the
+// attribute. Intel BMCs reject patches where a number is sent as a string.
+func toAttributeValueIntel(currentValue interface{}, value interface{}) (result interface{}, err error) {
+	str := fmt.Sprintf("%v", value)
+	switch currentValue.(type) {
+	case float64:
+		result, err = strconv.Atoi(str)
+	case bool:
+		result, err = strconv.ParseBool(str)
+	case string:
+		result = str
+	default:
+		result = value
+	}
+	if err != nil {
+		err = fmt.Errorf("value %v does not match the type of the current value %v", value, currentValue)
+	}
+	return
+}
+
+func makePatchRequestIntel(biosIntel *BiosIntel, attributeName PatchAttributeName, attributeValue PatchAttributeValue) (request *biosPatchRequest, err error, httpCode int) {
+	httpCode = http.StatusOK
+
+	names := getAttributeNamesIntel(attributeName.intel, biosIntel.current.Attributes)
+	if len(names) == 0 {
+		err = fmt.Errorf("%s not supported in the BIOS", attributeName.intel)
+		httpCode = http.StatusMethodNotAllowed
+		return
+	}
+
+	attributes := make(map[string]interface{})
+	for _, name := range names {
+		var futureValue interface{}
+		futureValue, err = toAttributeValueIntel(biosIntel.current.Attributes[name], attributeValue.intel)
+		if err != nil {
+			logger.Errorf("Tried to set %s with redfish value %v: %v", name, attributeValue.intel, err)
+			err = fmt.Errorf("BIOS %s value %v is not supported", name, attributeValue.intel)
+			httpCode = http.StatusMethodNotAllowed
+			return
+		}
+		attributes[name] = futureValue
+	}
+
+	rfRequestBody, err := json.Marshal(map[string]interface{}{"Attributes": attributes})
+	if err != nil {
+		err = fmt.Errorf("ERROR: Failed to create the patch request for %s: %v", biosIntel.futureUri, err)
+		httpCode = http.StatusInternalServerError
+		return
+	}
+
+	request = &biosPatchRequest{
+		uri:  biosIntel.futureUri,
+		body: rfRequestBody,
+		etag: biosIntel.future.ETag,
+	}
+	return
+}
+
+func patchBiosIntel(biosCommon *BiosCommon, attributeName PatchAttributeName, attributeValue PatchAttributeValue, applyTime string) (err error, httpCode int) {
+	biosIntel, err, httpCode := getBiosIntel(biosCommon)
+	if err != nil {
+		return
+	}
+
+	request, err, httpCode := makePatchRequestIntel(biosIntel, attributeName, attributeValue)
+	if err != nil {
+		return
+	}
+
+	err, httpCode = setBiosApplyTime(request, &biosIntel.current.Settings, applyTime)
+	if err != nil {
+		return
+	}
+
+	err, httpCode = patchBiosRequest(biosCommon, request)
+	return
+}
+
 func getBios(r *http.Request) (bios *Bios, err error, httpCode int) {
 	bios = &Bios{}
 
@@ -1138,7 +1384,7 @@ func getBios(r *http.Request) (bios *Bios, err error, httpCode int) {
 	return
 }
 
-func patchBios(r *http.Request, attributeName PatchAttributeName, attributeValue PatchAttributeValue) (err error, httpCode int) {
+func patchBios(r *http.Request, attributeName PatchAttributeName, attributeValue PatchAttributeValue, options BiosApplyOptions) (rsp BiosApplyRsp, err error, httpCode int) {
 	mvars := mux.Vars(r)
 	xnameOriginal := mvars["xname"]
 
@@ -1152,20 +1398,21 @@ func patchBios(r *http.Request, attributeName PatchAttributeName, attributeValue
 		return
 	}
 
+	if biosCommon.manufacturerType != unknown && attributeName.forVendor(biosCommon.manufacturerType) == "" {
+		err = fmt.Errorf("BIOS setting not supported by the hardware at %s", xname)
+		httpCode = http.StatusMethodNotAllowed
+		return
+	}
+
 	switch biosCommon.manufacturerType {
 	case cray:
-		err, httpCode = patchBiosCray(biosCommon, attributeName, attributeValue)
+		err, httpCode = patchBiosCray(biosCommon, attributeName, attributeValue, options.ApplyTime)
 	case gigabyte:
-		err, httpCode = patchBiosGigabyte(biosCommon, attributeName, attributeValue)
+		err, httpCode = patchBiosGigabyte(biosCommon, attributeName, attributeValue, options.ApplyTime)
 	case hpe:
-		err, httpCode = patchBiosHpe(biosCommon, attributeName, attributeValue)
+		err, httpCode = patchBiosHpe(biosCommon, attributeName, attributeValue, options.ApplyTime)
 	case intel:
-		// todo implement this
-		logger.Errorf(
-			"Modifications for %s has not been implmented for intel hardware. xname: %s",
-			attributeName.intel, xname)
-		err = fmt.Errorf("Modifications not supported by BMC at %s", xname)
-		httpCode = http.StatusBadRequest
+		err, httpCode = patchBiosIntel(biosCommon, attributeName, attributeValue, options.ApplyTime)
 	default:
 		logger.Errorf(
 			"Modifications for %s has not been implmented for hardware. type: %d, xname: %s",
@@ -1173,6 +1420,11 @@ func patchBios(r *http.Request, attributeName PatchAttributeName, attributeValue
 		err = fmt.Errorf("Modifications not supported by BMC at %s", xname)
 		httpCode = http.StatusBadRequest
 	}
+	if err != nil {
+		return
+	}
+
+	rsp, err, httpCode = applyBiosSettings(biosCommon, options)
 	return
 }
 
@@ -1184,7 +1436,7 @@ func toTpmStateHpe(bios *BiosHpe) BiosTpmState {
 		Future:  TpmStateNotPresent,
 	}
 
-	switch bios.current.Attributes.TpmState {
+	switch fmt.Sprintf("%v", bios.current.Attributes[string(TpmStateAttributeHpe)]) {
 	case EnabledHpe:
 		state.Current = TpmStateEnabled
 	case DisabledHpe:
@@ -1193,7 +1445,7 @@ func toTpmStateHpe(bios *BiosHpe) BiosTpmState {
 		state.Current = TpmStateNotPresent
 	}
 
-	switch bios.future.Attributes.TpmState {
+	switch fmt.Sprintf("%v", bios.future.Attributes[string(TpmStateAttributeHpe)]) {
 	case EnabledHpe:
 		state.Future = TpmStateEnabled
 	case DisabledHpe:
@@ -1297,27 +1549,27 @@ func toTpmStateIntel(bios *BiosIntel) BiosTpmState {
 	// but should only be either 0 or 1
 	foundCurrentValue := false
 	tpmOperation := -1
-	value, ok := bios.current.Attributes["TpmOperation"]
+	value, ok := bios.current.Attributes[string(TpmStateAttributeIntel)]
 	if ok {
 		foundCurrentValue = true
 		tpmOperation = toInt(value, tpmOperation)
 	}
 
 	tpm2Operation := -1
-	value, ok = bios.current.Attributes["Tpm2Operation"]
+	value, ok = bios.current.Attributes[string(Tpm2StateAttributeIntel)]
 	if ok {
 		foundCurrentValue = true
 		tpm2Operation = toInt(value, tpm2Operation)
 	}
 
 	tpmOperationFuture := tpmOperation
-	value, ok = bios.future.Attributes["TpmOperation"]
+	value, ok = bios.future.Attributes[string(TpmStateAttributeIntel)]
 	if ok {
 		tpmOperationFuture = toInt(value, tpmOperationFuture)
 	}
 
 	tpm2OperationFuture := tpm2Operation
-	value, ok = bios.future.Attributes["Tpm2Operation"]
+	value, ok = bios.future.Attributes[string(Tpm2StateAttributeIntel)]
 	if ok {
 		tpm2OperationFuture = toInt(value, tpm2OperationFuture)
 	}
@@ -1340,16 +1592,7 @@ func toTpmStateIntel(bios *BiosIntel) BiosTpmState {
 	return state
 }
 
-func doBiosTpmStateGet(w http.ResponseWriter, r *http.Request) {
-	title := "Get BIOS TPM State"
-
-	defer base.DrainAndCloseRequestBody(r)
-
-	bios, err, httpCode := getBios(r)
-	if err != nil {
-		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
-		return
-	}
+func toTpmState(bios *Bios) BiosTpmState {
 	var tpmState BiosTpmState
 	tpmState.Current = TpmStateNotPresent
 	tpmState.Future = TpmStateNotPresent
@@ -1364,6 +1607,45 @@ func doBiosTpmStateGet(w http.ResponseWriter, r *http.Request) {
 	case intel:
 		tpmState = toTpmStateIntel(bios.intel)
 	}
+	return tpmState
+}
+
+// Returns the vendor specific BIOS attribute and value for a future TPM state
+func toTpmStatePatch(future TpmState) (attributeName PatchAttributeName, attributeValue PatchAttributeValue, err error) {
+	attributeName = PatchAttributeName{
+		cray:     TpmStateAttributeCray,
+		gigabyte: TpmStateAttributeGigabyte,
+		hpe:      TpmStateAttributeHpe,
+		intel:    TpmStateAttributeIntel,
+	}
+	switch future {
+	case TpmStateEnabled:
+		attributeValue.cray = EnabledCray
+		attributeValue.gigabyte = EnabledGigabyte
+		attributeValue.hpe = EnabledHpe
+		attributeValue.intel = EnabledIntel
+	case TpmStateDisabled:
+		attributeValue.cray = DisabledCray
+		attributeValue.gigabyte = DisabledGigabyte
+		attributeValue.hpe = DisabledHpe
+		attributeValue.intel = DisabledIntel
+	default:
+		err = fmt.Errorf("ERROR: Invalid future value: %s", future)
+	}
+	return
+}
+
+func doBiosTpmStateGet(w http.ResponseWriter, r *http.Request) {
+	title := "Get BIOS TPM State"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	bios, err, httpCode := getBios(r)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+	tpmState := toTpmState(bios)
 
 	ba, baerr := json.Marshal(tpmState)
 	if baerr != nil {
@@ -1391,36 +1673,20 @@ func doBiosTpmStatePatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	attributeName := PatchAttributeName{
-		cray:     TpmStateAttributeCray,
-		gigabyte: TpmStateAttributeGigabyte,
-		hpe:      TpmStateAttributeHpe,
-		intel:    TpmStateAttributeIntel,
+	attributeName, attributeValue, err := toTpmStatePatch(requestBody.Future)
+	if err == nil {
+		err = requestBody.BiosApplyOptions.validate()
 	}
-	var attributeValue PatchAttributeValue
-	switch requestBody.Future {
-	case TpmStateEnabled:
-		attributeValue.cray = EnabledCray
-		attributeValue.gigabyte = EnabledGigabyte
-		attributeValue.hpe = EnabledHpe
-		attributeValue.intel = EnabledIntel
-	case TpmStateDisabled:
-		attributeValue.cray = DisabledCray
-		attributeValue.gigabyte = DisabledGigabyte
-		attributeValue.hpe = DisabledHpe
-		attributeValue.intel = DisabledIntel
-	default:
-		emsg := fmt.Sprintf("ERROR: Invalid future value: %s", requestBody.Future)
-		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
 		return
 	}
 
-	err, httpCode := patchBios(r, attributeName, attributeValue)
+	rsp, err, httpCode := patchBios(r, attributeName, attributeValue, requestBody.BiosApplyOptions)
 	if err != nil {
 		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
 		return
 	}
 
-	w.Header().Set(CT_TYPE, CT_APPJSON)
-	w.WriteHeader(http.StatusNoContent)
+	sendBiosApplyRsp(w, r, rsp)
 }
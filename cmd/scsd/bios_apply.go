@@ -0,0 +1,275 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+// BIOS settings staged in the future settings object are applied when the
+// node is reset, unless the request asks the BMC for another apply time
+// with @Redfish.SettingsApplyTime. The BMC advertises the apply times it
+// supports in the @Redfish.Settings of the current BIOS settings.
+
+const (
+	ApplyTimeImmediate                  = "Immediate"
+	ApplyTimeOnReset                    = "OnReset"
+	ApplyTimeAtMaintenanceWindowStart   = "AtMaintenanceWindowStart"
+	ApplyTimeInMaintenanceWindowOnReset = "InMaintenanceWindowOnReset"
+)
+
+// ComputerSystem.Reset types to use when rebooting a node, in order of
+// preference
+var systemResetTypes = []string{"ForceRestart", "PowerCycle", "GracefulRestart"}
+
+// Optional fields of the requests of the BIOS-modifying endpoints
+
+type BiosApplyOptions struct {
+	ApplyTime string `json:"ApplyTime,omitempty"`
+	Reboot    bool   `json:"Reboot,omitempty"`
+}
+
+// Return of the BIOS-modifying endpoints
+
+type BiosApplyRsp struct {
+	ApplyTime    string `json:"ApplyTime,omitempty"`
+	ResetPending bool   `json:"ResetPending"` // the settings take effect on the next reset
+	Reset        bool   `json:"Reset"`        // the node was reset
+}
+
+func (options *BiosApplyOptions) isSet() bool {
+	return options.ApplyTime != "" || options.Reboot
+}
+
+func (options *BiosApplyOptions) validate() error {
+	switch options.ApplyTime {
+	case "", ApplyTimeImmediate, ApplyTimeOnReset,
+		ApplyTimeAtMaintenanceWindowStart, ApplyTimeInMaintenanceWindowOnReset:
+		return nil
+	}
+	return fmt.Errorf("ERROR: Invalid ApplyTime: %s", options.ApplyTime)
+}
+
+// The BIOS actions are carried out by the BIOS on the next reset of the
+// node, so they can not be given another apply time.
+func (options *BiosApplyOptions) validateAction() error {
+	if options.ApplyTime != "" && options.ApplyTime != ApplyTimeOnReset {
+		return fmt.Errorf("ERROR: ApplyTime %s is not supported by BIOS actions, only %s",
+			options.ApplyTime, ApplyTimeOnReset)
+	}
+	return options.validate()
+}
+
+// Whether the settings are still pending after they are staged with the
+// apply time. Only Immediate applies them right away; settings applied at a
+// maintenance window stay pending until it starts, and usually need a reset.
+func resetPendingAfter(applyTime string) bool {
+	return applyTime != ApplyTimeImmediate
+}
+
+func getBiosSettings(bios *Bios) *rfRedfishSettings {
+	switch bios.common.manufacturerType {
+	case cray:
+		return &bios.cray.current.Settings
+	case gigabyte:
+		return &bios.gigabyte.current.Settings
+	case hpe:
+		return &bios.hpe.current.Settings
+	case intel:
+		return &bios.intel.current.Settings
+	}
+	return &rfRedfishSettings{}
+}
+
+// Adds @Redfish.SettingsApplyTime to a patch of the future BIOS settings.
+// The apply time must be one the BMC advertises.
+func setBiosApplyTime(request *biosPatchRequest, settings *rfRedfishSettings, applyTime string) (err error, httpCode int) {
+	httpCode = http.StatusOK
+	if applyTime == "" {
+		return
+	}
+
+	supported := false
+	for _, st := range settings.SupportedApplyTimes {
+		if st == applyTime {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		err = fmt.Errorf("BIOS settings apply time %s is not supported by the BMC, supported: %v",
+			applyTime, settings.SupportedApplyTimes)
+		httpCode = http.StatusMethodNotAllowed
+		return
+	}
+
+	var body map[string]interface{}
+	err = json.Unmarshal(request.body, &body)
+	if err == nil {
+		body["@Redfish.SettingsApplyTime"] = map[string]interface{}{"ApplyTime": applyTime}
+		request.body, err = json.Marshal(body)
+	}
+	if err != nil {
+		err = fmt.Errorf("ERROR: Failed to add the apply time to the patch of %s: %v", request.uri, err)
+		httpCode = http.StatusInternalServerError
+	}
+	return
+}
+
+// Returns the ComputerSystem.Reset action request of the node's system
+func makeSystemResetRequest(biosCommon *BiosCommon) *biosPatchRequest {
+	var action rfSystemResetAction
+	if biosCommon.system != nil {
+		action = biosCommon.system.Actions.Reset
+	}
+	uri := action.Target
+	if uri == "" {
+		uri = biosCommon.systemUri + "/Actions/ComputerSystem.Reset"
+	}
+
+	resetType := systemResetTypes[0]
+	if len(action.ResetTypes) > 0 {
+		resetType = ""
+		for _, rt := range systemResetTypes {
+			for _, allowed := range action.ResetTypes {
+				if rt == allowed && resetType == "" {
+					resetType = rt
+				}
+			}
+		}
+		if resetType == "" {
+			resetType = action.ResetTypes[0]
+		}
+	}
+
+	return &biosPatchRequest{
+		uri:  uri,
+		body: []byte("{\"ResetType\":\"" + resetType + "\"}"),
+	}
+}
+
+// POSTs a Redfish action to the node's BMC
+func postBiosRequest(biosCommon *BiosCommon, request *biosPatchRequest) (err error, httpCode int) {
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodPost, "", nil)
+	tasks := tloc.CreateTaskList(&sourceTL, len(biosCommon.targets))
+	populateTaskList(tasks, toXnames(biosCommon.targets), request.uri, http.MethodPost, request.body)
+
+	err = doOp(tasks)
+	if err != nil {
+		err = fmt.Errorf("ERROR: Post call %s failed for %s: %v", request.uri, biosCommon.xname, err)
+		return err, http.StatusInternalServerError
+	}
+
+	for _, task := range tasks {
+		statusCode := getStatusCode(&task)
+		if !statusCodeOK(statusCode) {
+			err = fmt.Errorf("ERROR: Redfish post failed %s %d", request.uri, statusCode)
+			return err, http.StatusInternalServerError
+		}
+	}
+	return nil, http.StatusOK
+}
+
+// Called once the BIOS settings of a node are staged. Resets the node when
+// asked to.
+func applyBiosSettings(biosCommon *BiosCommon, options BiosApplyOptions) (rsp BiosApplyRsp, err error, httpCode int) {
+	httpCode = http.StatusOK
+	rsp.ApplyTime = options.ApplyTime
+	rsp.ResetPending = resetPendingAfter(options.ApplyTime)
+
+	if !options.Reboot {
+		return
+	}
+
+	err, httpCode = postBiosRequest(biosCommon, makeSystemResetRequest(biosCommon))
+	if err != nil {
+		err = fmt.Errorf("BIOS settings were staged but the reset of %s failed: %v", biosCommon.xname, err)
+		return
+	}
+	rsp.ResetPending = false
+	rsp.Reset = true
+	return
+}
+
+// Bulk version of applyBiosSettings() for the nodes that have not failed.
+// Returns whether a reset is pending for each of them.
+func applyBiosSettingsBulk(nodes []*biosBulkNode, options BiosApplyOptions) map[*biosBulkNode]bool {
+	resetPending := make(map[*biosBulkNode]bool)
+
+	var reqs []biosBulkRequest
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		resetPending[node] = resetPendingAfter(options.ApplyTime)
+		if options.Reboot {
+			request := makeSystemResetRequest(node.bios.common)
+			reqs = append(reqs, biosBulkRequest{
+				node: node,
+				uri:  request.uri,
+				body: request.body,
+			})
+		}
+	}
+
+	tasks, err := doBiosBulkRequests(http.MethodPost, reqs)
+	if err != nil {
+		for _, req := range reqs {
+			req.node.fail(http.StatusInternalServerError,
+				fmt.Errorf("BIOS settings were staged but the reset call %s failed: %v", req.uri, err))
+		}
+		return resetPending
+	}
+
+	for ii := range tasks {
+		statusCode := getStatusCode(&tasks[ii])
+		if !statusCodeOK(statusCode) {
+			reqs[ii].node.fail(http.StatusInternalServerError,
+				fmt.Errorf("BIOS settings were staged but the reset failed %s %d", reqs[ii].uri, statusCode))
+			continue
+		}
+		resetPending[reqs[ii].node] = false
+	}
+	return resetPending
+}
+
+// Responds to a BIOS-modifying request, saying whether a reset is pending
+func sendBiosApplyRsp(w http.ResponseWriter, r *http.Request, rsp BiosApplyRsp) {
+	ba, baerr := json.Marshal(rsp)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS apply data: %v", baerr)
+		sendErrorRsp(w, "BIOS Apply Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
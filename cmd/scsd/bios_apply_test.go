@@ -0,0 +1,134 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBiosApplyOptions(t *testing.T) {
+	options := BiosApplyOptions{}
+	if options.isSet() || options.validate() != nil {
+		t.Errorf("Expected empty options to be unset and valid")
+	}
+
+	options.ApplyTime = ApplyTimeOnReset
+	if !options.isSet() || options.validate() != nil {
+		t.Errorf("Expected %s to be set and valid", options.ApplyTime)
+	}
+
+	options.ApplyTime = "Later"
+	if options.validate() == nil {
+		t.Errorf("Expected an error for ApplyTime %s", options.ApplyTime)
+	}
+
+	options.ApplyTime = ApplyTimeOnReset
+	if options.validateAction() != nil {
+		t.Errorf("Expected %s to be valid for BIOS actions", options.ApplyTime)
+	}
+	options.ApplyTime = ApplyTimeImmediate
+	if options.validateAction() == nil {
+		t.Errorf("Expected an error for BIOS action ApplyTime %s", options.ApplyTime)
+	}
+
+	if !resetPendingAfter("") || !resetPendingAfter(ApplyTimeOnReset) || resetPendingAfter(ApplyTimeImmediate) ||
+		!resetPendingAfter(ApplyTimeAtMaintenanceWindowStart) || !resetPendingAfter(ApplyTimeInMaintenanceWindowOnReset) {
+		t.Errorf("Unexpected resetPendingAfter results")
+	}
+}
+
+func TestSetBiosApplyTime(t *testing.T) {
+	settings := &rfRedfishSettings{SupportedApplyTimes: []string{ApplyTimeOnReset, ApplyTimeImmediate}}
+
+	request := &biosPatchRequest{body: []byte(`{"Attributes":{"TpmOperation":1}}`)}
+	err, _ := setBiosApplyTime(request, settings, "")
+	if err != nil || string(request.body) != `{"Attributes":{"TpmOperation":1}}` {
+		t.Errorf("Expected the body to be unchanged, got %s, err: %v", request.body, err)
+	}
+
+	err, _ = setBiosApplyTime(request, settings, ApplyTimeImmediate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var body struct {
+		Attributes map[string]interface{}
+		ApplyTime  struct {
+			ApplyTime string
+		} `json:"@Redfish.SettingsApplyTime"`
+	}
+	if err := json.Unmarshal(request.body, &body); err != nil {
+		t.Fatalf("Bad request body %s: %v", request.body, err)
+	}
+	if body.ApplyTime.ApplyTime != ApplyTimeImmediate || body.Attributes["TpmOperation"] != float64(1) {
+		t.Errorf("Expected the attributes and apply time, got %s", request.body)
+	}
+
+	err, httpCode := setBiosApplyTime(request, &rfRedfishSettings{}, ApplyTimeOnReset)
+	if err == nil || httpCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for an apply time the BMC does not advertise, got %d, err: %v", httpCode, err)
+	}
+}
+
+func TestMakeSystemResetRequest(t *testing.T) {
+	biosCommon := &BiosCommon{systemUri: "/redfish/v1/Systems/Node0"}
+
+	request := makeSystemResetRequest(biosCommon)
+	if request.uri != "/redfish/v1/Systems/Node0/Actions/ComputerSystem.Reset" ||
+		string(request.body) != `{"ResetType":"ForceRestart"}` {
+		t.Errorf("Expected the standard action with ForceRestart, got %s %s", request.uri, request.body)
+	}
+
+	biosCommon.system = &rfSystem{Actions: rfSystemActions{Reset: rfSystemResetAction{
+		Target:     "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset",
+		ResetTypes: []string{"On", "GracefulRestart", "PowerCycle"},
+	}}}
+	request = makeSystemResetRequest(biosCommon)
+	if request.uri != "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset" ||
+		string(request.body) != `{"ResetType":"PowerCycle"}` {
+		t.Errorf("Expected the advertised action with PowerCycle, got %s %s", request.uri, request.body)
+	}
+}
+
+func TestSendBiosApplyRsp(t *testing.T) {
+	// A plain patch staged for the next reset still says so
+	rsp, err, _ := applyBiosSettings(&BiosCommon{}, BiosApplyOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPatch, "http://localhost"+API_BIOS+"/x0c0s0b0n0/tpmstate", nil)
+	w := httptest.NewRecorder()
+	sendBiosApplyRsp(w, req, rsp)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Bad response body %s: %v", w.Body.String(), err)
+	}
+	if body["ResetPending"] != true || body["Reset"] != false {
+		t.Errorf("Expected a pending reset, got %s", w.Body.String())
+	}
+}
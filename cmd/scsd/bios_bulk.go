@@ -0,0 +1,777 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// Used by /v1/bmc/bios/dump/tpmstate POST to fetch TPM state of many nodes
+
+type biosTpmStateDumpPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+}
+
+// Used by /v1/bmc/bios/load/tpmstate POST to set TPM state of many nodes
+
+type biosTpmStateLoadPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+	Future  TpmState `json:"Future"`
+	BiosApplyOptions
+}
+
+// Return of /v1/bmc/bios/dump/tpmstate and /v1/bmc/bios/load/tpmstate POST
+
+type biosTpmStateRspElem struct {
+	Xname        string   `json:"Xname"`
+	StatusCode   int      `json:"StatusCode"`
+	StatusMsg    string   `json:"StatusMsg"`
+	Current      TpmState `json:"Current,omitempty"`
+	Future       TpmState `json:"Future,omitempty"`
+	ResetPending *bool    `json:"ResetPending,omitempty"` // load only
+}
+
+type biosTpmStateRsp struct {
+	Targets []biosTpmStateRspElem `json:"Targets"`
+}
+
+// A node taking part in a bulk BIOS operation.  Each step of a bulk
+// operation is a single TRS task list covering all of the nodes that have
+// not failed a previous step.
+
+type biosBulkNode struct {
+	bios          *Bios
+	hpeRegistries *BiosHpeRegistries
+	statusCode    int
+	err           error
+}
+
+// A single Redfish request of a bulk BIOS operation
+
+type biosBulkRequest struct {
+	node *biosBulkNode
+	uri  string
+	body []byte
+	etag string // If-Match header, not sent when empty
+}
+
+func (node *biosBulkNode) ok() bool {
+	return node.err == nil
+}
+
+func (node *biosBulkNode) fail(httpCode int, err error) {
+	if node.err != nil {
+		return
+	}
+	logger.Errorf("BIOS operation failed for %s: %v", node.bios.common.xname, err)
+	node.statusCode = httpCode
+	node.err = err
+}
+
+func newBiosBulkNode(xname string) *biosBulkNode {
+	node := &biosBulkNode{
+		bios: &Bios{
+			common: &BiosCommon{
+				xname:    xname,
+				bmcXname: xnametypes.GetHMSCompParent(xname),
+			},
+		},
+		statusCode: http.StatusOK,
+	}
+
+	var err error
+	node.bios.common.nodeNumber, err = getNodeNumber(xname)
+	if err != nil {
+		node.fail(http.StatusBadRequest, err)
+	}
+	return node
+}
+
+// Expand a list of node XNames and HSM group names into the nodes of a
+// bulk BIOS operation, and verify the nodes' BMCs with HSM.  As with the
+// other bulk operations, groups are not expanded when force is set.
+//
+// targets: List of node XNames and/or HSM group names
+// force:   Skip the HSM verification of the BMCs
+// Return:  Nodes of the operation; nodes which could not be resolved or
+//          whose BMC is in a bad state are already marked as failed.
+
+func getBiosBulkNodes(targets []string, force bool) ([]*biosBulkNode, error) {
+	var nodes []*biosBulkNode
+	nodeMap := make(map[string]bool)
+	groupMap := make(map[string]bool)
+
+	addNode := func(xname string) {
+		xname = xnametypes.NormalizeHMSCompID(xname)
+		if !nodeMap[xname] {
+			nodeMap[xname] = true
+			nodes = append(nodes, newBiosBulkNode(xname))
+		}
+	}
+
+	for _, targ := range targets {
+		if xnametypes.VerifyNormalizeCompID(targ) == "" {
+			groupMap[targ] = false
+			continue
+		}
+		addNode(targ)
+	}
+
+	if (len(groupMap) > 0) && !force {
+		rsp, err := doHSMGet(appParams.SmdURL + "/groups")
+		if err != nil {
+			return nil, fmt.Errorf("Getting group info from HSM: %v", err)
+		}
+		var groupData hsmGroupList
+		err = json.Unmarshal(rsp, &groupData)
+		if err != nil {
+			return nil, fmt.Errorf("Problem unmarshalling HSM group data: %v", err)
+		}
+
+		for _, group := range groupData {
+			if _, ok := groupMap[group.Label]; !ok {
+				continue
+			}
+			for _, member := range group.Members.IDS {
+				if xnametypes.GetHMSType(member) == xnametypes.Node {
+					groupMap[group.Label] = true
+					addNode(member)
+				}
+			}
+		}
+	}
+
+	//Targets that are neither XNames nor HSM groups containing nodes are
+	//reported back as failures.
+
+	for targ, matched := range groupMap {
+		if matched {
+			continue
+		}
+		node := &biosBulkNode{bios: &Bios{common: &BiosCommon{xname: targ}}}
+		node.fail(http.StatusNotFound,
+			fmt.Errorf("Target '%s' is not a node XName or an HSM group containing nodes", targ))
+		nodes = append(nodes, node)
+	}
+
+	//Verify the BMCs of the nodes with HSM.
+
+	var bmcs []string
+	bmcMap := make(map[string]*targInfo)
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		if _, ok := bmcMap[node.bios.common.bmcXname]; !ok {
+			bmcMap[node.bios.common.bmcXname] = nil
+			bmcs = append(bmcs, node.bios.common.bmcXname)
+		}
+	}
+
+	if len(bmcs) > 0 {
+		bmcTargs, err := hsmVerify(makeTargData(bmcs), force, false)
+		if err != nil {
+			return nil, fmt.Errorf("Problem verifying target states: %v", err)
+		}
+		for ii := 0; ii < len(bmcTargs); ii++ {
+			bmcMap[bmcTargs[ii].target] = &bmcTargs[ii]
+		}
+	}
+
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		bmc := bmcMap[node.bios.common.bmcXname]
+		if (bmc == nil) || !goodHSMState(bmc.state.String()) {
+			state := base.StateUnknown
+			if bmc != nil {
+				state = bmc.state
+			}
+			node.fail(http.StatusUnprocessableEntity,
+				fmt.Errorf("Target '%s' in bad HSM state: %s", node.bios.common.bmcXname, string(state)))
+			continue
+		}
+		node.bios.common.targets = []targInfo{*bmc}
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].bios.common.xname < nodes[j].bios.common.xname
+	})
+	return nodes, nil
+}
+
+// Launch the requests of one step of a bulk BIOS operation as a single
+// TRS task list.  The returned tasks are in the same order as the requests.
+
+func doBiosBulkRequests(method string, reqs []biosBulkRequest) ([]trsapi.HttpTask, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	sourceTL.Request, _ = http.NewRequest(method, "", nil)
+	tasks := tloc.CreateTaskList(&sourceTL, len(reqs))
+
+	for ii, req := range reqs {
+		url := dfltProtocol + "://" + req.node.bios.common.targets[0].target + req.uri
+		if method == http.MethodGet {
+			tasks[ii].Request, _ = http.NewRequest(method, url, nil)
+		} else {
+			tasks[ii].Request, _ = http.NewRequest(method, url, bytes.NewBuffer(req.body))
+			tasks[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+		}
+		if req.etag != "" {
+			tasks[ii].Request.Header.Set("If-Match", req.etag)
+		}
+	}
+
+	err := doOp(tasks)
+	return tasks, err
+}
+
+// GET a Redfish resource from the nodes' BMCs as a single TRS task list.
+// Nodes that share a BMC only cause a single request.
+//
+// description: Description of the resource, used in error messages
+// nodes:       Nodes of the bulk operation.  Failed nodes are skipped.
+// perBmc:      The resource is per-BMC rather than per-node
+// uri:         Returns the URI to GET for a node
+// data:        Returns the struct to decode the response for a node into
+// assign:      Stores the decoded response in a node; found is false if
+//              the BMC returned a 404.  Not called for failed requests.
+
+func getBiosBulkResource(description string, nodes []*biosBulkNode, perBmc bool,
+	uri func(node *biosBulkNode) string,
+	data func(node *biosBulkNode) interface{},
+	assign func(node *biosBulkNode, data interface{}, found bool)) {
+
+	var reqs []biosBulkRequest
+	var reqNodes [][]*biosBulkNode
+	bmcIndex := make(map[string]int)
+
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		if perBmc {
+			if ix, ok := bmcIndex[node.bios.common.bmcXname]; ok {
+				reqNodes[ix] = append(reqNodes[ix], node)
+				continue
+			}
+			bmcIndex[node.bios.common.bmcXname] = len(reqs)
+		}
+		reqs = append(reqs, biosBulkRequest{node: node, uri: uri(node)})
+		reqNodes = append(reqNodes, []*biosBulkNode{node})
+	}
+
+	tasks, err := doBiosBulkRequests(http.MethodGet, reqs)
+	if err != nil {
+		for ii := range reqs {
+			for _, node := range reqNodes[ii] {
+				node.fail(http.StatusInternalServerError,
+					fmt.Errorf("ERROR: Call failed %s. %v", reqs[ii].uri, err))
+			}
+		}
+		return
+	}
+
+	for ii := range tasks {
+		code := getStatusCode(&tasks[ii])
+		found := code != http.StatusNotFound
+		var rspData interface{}
+
+		if found {
+			if !statusCodeOK(code) {
+				err = fmt.Errorf("ERROR: Unexpected http return code, %d, from %s %s",
+					code, reqs[ii].node.bios.common.bmcXname, reqs[ii].uri)
+			} else {
+				rspData = data(reqs[ii].node)
+				err, _ = parseResponse(description, &tasks[ii], rspData)
+			}
+		}
+
+		for _, node := range reqNodes[ii] {
+			if err != nil {
+				node.fail(http.StatusInternalServerError, err)
+				continue
+			}
+			assign(node, rspData, found)
+		}
+	}
+}
+
+func biosBulkNotFound(node *biosBulkNode, uri string) {
+	node.fail(http.StatusInternalServerError,
+		fmt.Errorf("ERROR: Unexpected http return code, %d, for %s from %s %s",
+			http.StatusNotFound, node.bios.common.xname, node.bios.common.bmcXname, uri))
+}
+
+// Bulk version of getBiosCommon().  Finds the manufacturer and the BIOS URI
+// of all of the nodes.
+
+func getBiosBulkCommon(nodes []*biosBulkNode) {
+	notFound := biosBulkNotFound
+
+	// ---- /redfish/v1/Chassis ----
+
+	getBiosBulkResource("Chassis", nodes, true,
+		func(node *biosBulkNode) string { return RFCHASSIS_API },
+		func(node *biosBulkNode) interface{} { return &rfChassis{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				notFound(node, RFCHASSIS_API)
+				return
+			}
+			common := node.bios.common
+			common.chassis = data.(*rfChassis)
+			common.manufacturerType = getManufacturerType(common.chassis)
+			if common.manufacturerType == unknown {
+				node.fail(http.StatusBadRequest,
+					fmt.Errorf("ERROR: BIOS calls not supported for this type of hardware. xname: %s ", common.xname))
+			}
+		})
+
+	// ---- /redfish/v1/Systems ----
+
+	getBiosBulkResource("Systems", nodes, true,
+		func(node *biosBulkNode) string { return RFSYSTEMS_API },
+		func(node *biosBulkNode) interface{} { return &rfSystems{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				notFound(node, RFSYSTEMS_API)
+				return
+			}
+			var err error
+			var httpCode int
+			common := node.bios.common
+			common.systems = data.(*rfSystems)
+			common.systemUri, err, httpCode = getSystemUri(
+				common.xname, common.nodeNumber, common.manufacturerType, common.systems)
+			if err != nil {
+				node.fail(httpCode, err)
+			}
+		})
+
+	// ---- /redfish/v1/Systems/{system} ----
+
+	getBiosBulkResource("System", nodes, false,
+		func(node *biosBulkNode) string { return node.bios.common.systemUri },
+		func(node *biosBulkNode) interface{} { return &rfSystem{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				notFound(node, node.bios.common.systemUri)
+				return
+			}
+			node.bios.common.system = data.(*rfSystem)
+			node.bios.common.biosUri = node.bios.common.system.Bios.ID
+		})
+
+}
+
+// Bulk version of getBios().  Fetches the current and future BIOS settings
+// of all of the nodes.
+
+func getBiosBulk(nodes []*biosBulkNode) {
+	notFound := biosBulkNotFound
+
+	getBiosBulkCommon(nodes)
+
+	// ---- /redfish/v1/Systems/{system}/Bios ----
+
+	getBiosBulkResource("Systems/*/Bios", nodes, false,
+		func(node *biosBulkNode) string { return node.bios.common.biosUri },
+		func(node *biosBulkNode) interface{} {
+			switch node.bios.common.manufacturerType {
+			case cray:
+				return &rfBiosCray{}
+			case gigabyte:
+				return &rfBiosGigabyte{}
+			case hpe:
+				return &rfBiosHpe{}
+			default:
+				return &rfBiosIntel{}
+			}
+		},
+		func(node *biosBulkNode, data interface{}, found bool) {
+			bios := node.bios
+			if !found {
+				notFound(node, bios.common.biosUri)
+				return
+			}
+			switch bios.common.manufacturerType {
+			case cray:
+				bios.cray = &BiosCray{current: data.(*rfBiosCray)}
+				bios.cray.futureUri = getBiosFutureUri(bios.common, nil)
+			case gigabyte:
+				bios.gigabyte = &BiosGigabyte{current: data.(*rfBiosGigabyte)}
+				bios.gigabyte.futureUri = getBiosFutureUri(bios.common, &bios.gigabyte.current.Settings)
+			case hpe:
+				bios.hpe = &BiosHpe{current: data.(*rfBiosHpe)}
+				bios.hpe.futureUri = getBiosFutureUri(bios.common, nil)
+			case intel:
+				bios.intel = &BiosIntel{current: data.(*rfBiosIntel)}
+				bios.intel.futureUri = getBiosFutureUri(bios.common, &bios.intel.current.Settings)
+			}
+		})
+
+	// ---- /redfish/v1/Systems/{system}/Bios/SD ----
+	// ---- /redfish/v1/Systems/{system}/Bios/Settings ----
+
+	getBiosBulkResource("Systems/*/Bios/Settings", nodes, false,
+		func(node *biosBulkNode) string {
+			switch node.bios.common.manufacturerType {
+			case cray:
+				return node.bios.cray.futureUri
+			case gigabyte:
+				return node.bios.gigabyte.futureUri
+			case hpe:
+				return node.bios.hpe.futureUri
+			default:
+				return node.bios.intel.futureUri
+			}
+		},
+		func(node *biosBulkNode) interface{} {
+			switch node.bios.common.manufacturerType {
+			case cray:
+				return &rfBiosSDCray{}
+			case gigabyte:
+				return &rfBiosSDGigabyte{}
+			case hpe:
+				return &rfBiosHpe{}
+			default:
+				return &rfBiosIntel{}
+			}
+		},
+		func(node *biosBulkNode, data interface{}, found bool) {
+			bios := node.bios
+			switch bios.common.manufacturerType {
+			case cray:
+				// If there are no pending changes to the bios the /SD redfish call can return 404
+				if !found {
+					data = &rfBiosSDCray{Attributes: make(map[string]interface{})}
+				}
+				bios.cray.future = data.(*rfBiosSDCray)
+			case gigabyte:
+				if !found {
+					data = &rfBiosSDGigabyte{Attributes: make(map[string]interface{})}
+				}
+				bios.gigabyte.future = data.(*rfBiosSDGigabyte)
+			case hpe:
+				if !found {
+					notFound(node, bios.hpe.futureUri)
+					return
+				}
+				bios.hpe.future = data.(*rfBiosHpe)
+			case intel:
+				if !found {
+					notFound(node, bios.intel.futureUri)
+					return
+				}
+				bios.intel.future = data.(*rfBiosIntel)
+			}
+		})
+
+	// ---- /redfish/v1/Registries/BiosAttributeRegistry.json ----
+
+	var gigabyteNodes []*biosBulkNode
+	for _, node := range nodes {
+		if node.ok() && (node.bios.common.manufacturerType == gigabyte) {
+			gigabyteNodes = append(gigabyteNodes, node)
+		}
+	}
+
+	getBiosBulkResource("BiosAttributeRegistry.json", gigabyteNodes, true,
+		func(node *biosBulkNode) string { return GB_BIOSREG_API },
+		func(node *biosBulkNode) interface{} { return &rfBiosAttributesRegistry{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				node.fail(http.StatusInternalServerError,
+					fmt.Errorf("ERROR: Could not find bios registry for %s from %s %s",
+						node.bios.common.xname, node.bios.common.bmcXname, GB_BIOSREG_API))
+				return
+			}
+			node.bios.gigabyte.biosAttributes = data.(*rfBiosAttributesRegistry)
+		})
+}
+
+// Bulk version of getBiosRegistriesHpe().  Only needed when patching.
+
+func getBiosBulkRegistries(nodes []*biosBulkNode) {
+	var hpeNodes []*biosBulkNode
+	for _, node := range nodes {
+		if node.ok() && (node.bios.common.manufacturerType == hpe) {
+			node.hpeRegistries = &BiosHpeRegistries{}
+			hpeNodes = append(hpeNodes, node)
+		}
+	}
+
+	// ---- /redfish/v1/Registries ----
+
+	getBiosBulkResource("Registries", hpeNodes, true,
+		func(node *biosBulkNode) string { return RFREGISTRIES_API },
+		func(node *biosBulkNode) interface{} { return &rfRegistries{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			registries := node.hpeRegistries
+			if found {
+				registries.registries = data.(*rfRegistries)
+				registries.biosRegistryUri = getBiosRegistryUriHpe(registries.registries)
+			}
+			if registries.biosRegistryUri == "" {
+				node.fail(http.StatusInternalServerError,
+					fmt.Errorf("ERROR: Could not find bios registries for %s from %s %s",
+						node.bios.common.xname, node.bios.common.bmcXname, RFREGISTRIES_API))
+			}
+		})
+
+	// ---- /redfish/v1/Registries/BiosAttributeRegistryA43.v1_2_40 ----
+
+	getBiosBulkResource("AttributesRegistries", hpeNodes, true,
+		func(node *biosBulkNode) string { return node.hpeRegistries.biosRegistryUri },
+		func(node *biosBulkNode) interface{} { return &rfBiosAttributesRegistries{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			registries := node.hpeRegistries
+			if found {
+				registries.biosAttributesRegistries = data.(*rfBiosAttributesRegistries)
+				registries.biosRegistryEnUri = getBiosRegistryEnUriHpe(registries.biosAttributesRegistries)
+			}
+			if registries.biosRegistryEnUri == "" {
+				node.fail(http.StatusInternalServerError,
+					fmt.Errorf("ERROR: Could not find bios registries english uri for %s from %s %s",
+						node.bios.common.xname, node.bios.common.bmcXname, registries.biosRegistryUri))
+			}
+		})
+
+	// ---- /redfish/v1/registrystore/registries/en/biosattributeregistrya43.v1_2_40 ----
+
+	getBiosBulkResource("AttributesRegistry", hpeNodes, true,
+		func(node *biosBulkNode) string { return node.hpeRegistries.biosRegistryEnUri },
+		func(node *biosBulkNode) interface{} { return &rfBiosAttributesRegistry{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				node.fail(http.StatusInternalServerError,
+					fmt.Errorf("ERROR: Could not find bios registry for %s from %s %s",
+						node.bios.common.xname, node.bios.common.bmcXname, node.hpeRegistries.biosRegistryEnUri))
+				return
+			}
+			node.hpeRegistries.biosAttributes = data.(*rfBiosAttributesRegistry)
+		})
+}
+
+// Bulk version of patchBios().  Returns whether a reset is pending for each
+// node whose settings were staged.
+
+func patchBiosBulk(nodes []*biosBulkNode, attributeName PatchAttributeName, attributeValue PatchAttributeValue, options BiosApplyOptions) map[*biosBulkNode]bool {
+	getBiosBulk(nodes)
+	getBiosBulkRegistries(nodes)
+
+	var reqs []biosBulkRequest
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+
+		var request *biosPatchRequest
+		var err error
+		var httpCode int
+		bios := node.bios
+
+		if attributeName.forVendor(bios.common.manufacturerType) == "" {
+			node.fail(http.StatusMethodNotAllowed,
+				fmt.Errorf("BIOS setting not supported by the hardware at %s", bios.common.xname))
+			continue
+		}
+
+		switch bios.common.manufacturerType {
+		case cray:
+			request, err, httpCode = makePatchRequestCray(bios.cray, attributeName, attributeValue)
+		case gigabyte:
+			request, err, httpCode = makePatchRequestGigabyte(bios.gigabyte, attributeName, attributeValue)
+		case hpe:
+			request, err, httpCode = makePatchRequestHpe(bios.hpe, node.hpeRegistries, attributeName, attributeValue)
+		case intel:
+			request, err, httpCode = makePatchRequestIntel(bios.intel, attributeName, attributeValue)
+		}
+		if err == nil {
+			err, httpCode = setBiosApplyTime(request, getBiosSettings(bios), options.ApplyTime)
+		}
+		if err != nil {
+			node.fail(httpCode, err)
+			continue
+		}
+
+		reqs = append(reqs, biosBulkRequest{
+			node: node,
+			uri:  request.uri,
+			body: request.body,
+			etag: request.etag,
+		})
+	}
+
+	tasks, err := doBiosBulkRequests(http.MethodPatch, reqs)
+	if err != nil {
+		for _, req := range reqs {
+			req.node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Patch call %s failed: %v", req.uri, err))
+		}
+		return nil
+	}
+
+	for ii := range tasks {
+		statusCode := getStatusCode(&tasks[ii])
+		if !statusCodeOK(statusCode) {
+			reqs[ii].node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Redfish patch failed %s %d", reqs[ii].uri, statusCode))
+		}
+	}
+
+	return applyBiosSettingsBulk(nodes, options)
+}
+
+func toBiosTpmStateRspElem(node *biosBulkNode) biosTpmStateRspElem {
+	elem := biosTpmStateRspElem{
+		Xname:      node.bios.common.xname,
+		StatusCode: node.statusCode,
+		StatusMsg:  statusMsg(node.statusCode),
+	}
+	if !node.ok() {
+		elem.StatusMsg = node.err.Error()
+		return elem
+	}
+	tpmState := toTpmState(node.bios)
+	elem.Current = tpmState.Current
+	elem.Future = tpmState.Future
+	return elem
+}
+
+func sendBiosTpmStateRsp(w http.ResponseWriter, r *http.Request, nodes []*biosBulkNode, future TpmState, resetPending map[*biosBulkNode]bool) {
+	var rspData biosTpmStateRsp
+	for _, node := range nodes {
+		elem := toBiosTpmStateRspElem(node)
+		if node.ok() && (future != "") {
+			elem.Future = future
+		}
+		if pending, ok := resetPending[node]; ok && node.ok() {
+			elem.ResetPending = &pending
+		}
+		rspData.Targets = append(rspData.Targets, elem)
+	}
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling TPM data: %v", baerr)
+		sendErrorRsp(w, "TPM State Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/dump/tpmstate POST
+
+func doBiosTpmStateDumpPost(w http.ResponseWriter, r *http.Request) {
+	title := "Get BIOS TPM State"
+	var jdata biosTpmStateDumpPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	getBiosBulk(nodes)
+	sendBiosTpmStateRsp(w, r, nodes, "", nil)
+}
+
+// /v1/bmc/bios/load/tpmstate POST
+
+func doBiosTpmStateLoadPost(w http.ResponseWriter, r *http.Request) {
+	title := "Patch BIOS TPM State"
+	var jdata biosTpmStateLoadPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	attributeName, attributeValue, err := toTpmStatePatch(jdata.Future)
+	if err == nil {
+		err = jdata.BiosApplyOptions.validate()
+	}
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	resetPending := patchBiosBulk(nodes, attributeName, attributeValue, jdata.BiosApplyOptions)
+	sendBiosTpmStateRsp(w, r, nodes, jdata.Future, resetPending)
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetBiosBulkNodes(t *testing.T) {
+	loggerSetup()
+	targets := []string{"x0c0s1b0n0", "x0c0s0b0n1", "x0c0s0b0n0", "x0c0s0b0n0", "x0c0s0b0", "nodegroup"}
+
+	// With force the group is not expanded and HSM is not contacted
+	nodes, err := getBiosBulkNodes(targets, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(nodes) != 5 {
+		t.Fatalf("Expected 5 nodes but instead got %d", len(nodes))
+	}
+
+	expected := []struct {
+		xname      string
+		statusCode int
+		nodeNumber int
+		bmc        string
+	}{
+		{"nodegroup", http.StatusNotFound, 0, ""},
+		{"x0c0s0b0", http.StatusBadRequest, 0, ""},
+		{"x0c0s0b0n0", http.StatusOK, 0, "x0c0s0b0"},
+		{"x0c0s0b0n1", http.StatusOK, 1, "x0c0s0b0"},
+		{"x0c0s1b0n0", http.StatusOK, 0, "x0c0s1b0"},
+	}
+	for ii, exp := range expected {
+		node := nodes[ii]
+		common := node.bios.common
+		if common.xname != exp.xname {
+			t.Errorf("Node %d: expected xname %s but instead got %s", ii, exp.xname, common.xname)
+			continue
+		}
+		if node.statusCode != exp.statusCode {
+			t.Errorf("Node %s: expected status %d but instead got %d (%v)",
+				exp.xname, exp.statusCode, node.statusCode, node.err)
+		}
+		if !node.ok() {
+			continue
+		}
+		if common.nodeNumber != exp.nodeNumber {
+			t.Errorf("Node %s: expected node number %d but instead got %d",
+				exp.xname, exp.nodeNumber, common.nodeNumber)
+		}
+		if (len(common.targets) != 1) || (common.targets[0].target != exp.bmc) {
+			t.Errorf("Node %s: expected BMC target %s but instead got %v",
+				exp.xname, exp.bmc, common.targets)
+		}
+	}
+}
+
+func TestToBiosTpmStateRspElem(t *testing.T) {
+	node := newBiosBulkNode("x0c0s0b0n0")
+	node.bios.common.manufacturerType = intel
+	node.bios.intel = &BiosIntel{
+		current: &rfBiosIntel{Attributes: map[string]interface{}{"TpmOperation": float64(0)}},
+		future:  &rfBiosIntel{Attributes: map[string]interface{}{"TpmOperation": float64(1)}},
+	}
+
+	elem := toBiosTpmStateRspElem(node)
+	if (elem.StatusCode != http.StatusOK) || (elem.StatusMsg != "OK") {
+		t.Errorf("Expected status 200 OK but instead got %d %s", elem.StatusCode, elem.StatusMsg)
+	}
+	if (elem.Current != TpmStateDisabled) || (elem.Future != TpmStateEnabled) {
+		t.Errorf("Expected Disabled/Enabled but instead got %s/%s", elem.Current, elem.Future)
+	}
+
+	node.fail(http.StatusMethodNotAllowed, fmt.Errorf("TpmOperation not supported in the BIOS"))
+	node.fail(http.StatusInternalServerError, fmt.Errorf("second failure is ignored"))
+	elem = toBiosTpmStateRspElem(node)
+	if elem.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d but instead got %d", http.StatusMethodNotAllowed, elem.StatusCode)
+	}
+	if elem.StatusMsg != "TpmOperation not supported in the BIOS" {
+		t.Errorf("Unexpected status message: %s", elem.StatusMsg)
+	}
+	if (elem.Current != "") || (elem.Future != "") {
+		t.Errorf("Expected no TPM state for a failed node but instead got %s/%s", elem.Current, elem.Future)
+	}
+}
@@ -0,0 +1,249 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// Used by /v1/bmc/bios/compare POST to compare the BIOS of many nodes with a
+// reference. The reference is either the xname of a known-good node or a
+// stored set of attributes. Ignore holds attribute names, which may be
+// path.Match patterns such as "*Mac*".
+
+type biosComparePost struct {
+	Force      bool                   `json:"Force"`
+	Targets    []string               `json:"Targets"`
+	Reference  string                 `json:"Reference,omitempty"`
+	Attributes map[string]interface{} `json:"Attributes,omitempty"`
+	Ignore     []string               `json:"Ignore,omitempty"`
+}
+
+type BiosAttributeDifference struct {
+	Attribute string      `json:"Attribute"`
+	Expected  interface{} `json:"Expected"`
+	Actual    interface{} `json:"Actual"`
+}
+
+// Return of /v1/bmc/bios/compare POST
+
+type biosCompareRspElem struct {
+	Xname       string                    `json:"Xname"`
+	StatusCode  int                       `json:"StatusCode"`
+	StatusMsg   string                    `json:"StatusMsg"`
+	Matches     bool                      `json:"Matches"`
+	Differences []BiosAttributeDifference `json:"Differences,omitempty"`
+}
+
+type biosCompareRsp struct {
+	Reference string               `json:"Reference,omitempty"`
+	Targets   []biosCompareRspElem `json:"Targets"`
+}
+
+// Returns the current values of the BIOS attributes of a node
+func getBiosCurrentAttributes(bios *Bios) map[string]interface{} {
+	switch bios.common.manufacturerType {
+	case cray:
+		current := make(map[string]interface{})
+		for name, attribute := range bios.cray.current.Attributes {
+			current[name] = attribute.CurrentValue
+		}
+		return current
+	case gigabyte:
+		return bios.gigabyte.current.Attributes
+	case hpe:
+		return bios.hpe.current.Attributes
+	case intel:
+		return bios.intel.current.Attributes
+	}
+	return map[string]interface{}{}
+}
+
+func biosAttributeIgnored(name string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the attributes whose actual value differs from the expected one.
+// Attributes missing from expected are only reported when strict is set,
+// since a stored attribute set usually covers a few attributes.
+func compareBiosAttributes(expected, actual map[string]interface{}, ignore []string, strict bool) []BiosAttributeDifference {
+	differences := []BiosAttributeDifference{}
+	for name, expectedValue := range expected {
+		if biosAttributeIgnored(name, ignore) {
+			continue
+		}
+		actualValue := actual[name]
+		if fmt.Sprintf("%v", expectedValue) != fmt.Sprintf("%v", actualValue) {
+			differences = append(differences, BiosAttributeDifference{
+				Attribute: name,
+				Expected:  expectedValue,
+				Actual:    actualValue,
+			})
+		}
+	}
+	if strict {
+		for name, actualValue := range actual {
+			if _, ok := expected[name]; ok || biosAttributeIgnored(name, ignore) {
+				continue
+			}
+			differences = append(differences, BiosAttributeDifference{
+				Attribute: name,
+				Actual:    actualValue,
+			})
+		}
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Attribute < differences[j].Attribute
+	})
+	return differences
+}
+
+func validateBiosComparePost(jdata *biosComparePost) error {
+	if len(jdata.Targets) == 0 {
+		return fmt.Errorf("ERROR: No targets in request.")
+	}
+	if (jdata.Reference == "") == (jdata.Attributes == nil) {
+		return fmt.Errorf("ERROR: Exactly one of Reference or Attributes is required.")
+	}
+	for _, pattern := range jdata.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ERROR: Invalid Ignore pattern '%s': %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Fetches the BIOS of the reference node
+func getBiosReference(xname string, force bool) (*Bios, error, int) {
+	nodes, err := getBiosBulkNodes([]string{xname}, force)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: %v", err), http.StatusInternalServerError
+	}
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("ERROR: Reference '%s' must be a single node", xname),
+			http.StatusBadRequest
+	}
+
+	node := nodes[0]
+	if node.ok() {
+		getBiosBulk(nodes)
+	}
+	if !node.ok() {
+		return nil, fmt.Errorf("ERROR: Unable to get the BIOS of reference '%s': %v", xname, node.err),
+			http.StatusBadRequest
+	}
+	return node.bios, nil, http.StatusOK
+}
+
+// /v1/bmc/bios/compare POST
+
+func doBiosComparePost(w http.ResponseWriter, r *http.Request) {
+	title := "Compare BIOS"
+	var jdata biosComparePost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	err = validateBiosComparePost(&jdata)
+	if err != nil {
+		sendErrorRsp(w, "Bad request", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	var rspData biosCompareRsp
+	var reference *Bios
+	expected := jdata.Attributes
+	if jdata.Reference != "" {
+		var httpCode int
+		reference, err, httpCode = getBiosReference(jdata.Reference, jdata.Force)
+		if err != nil {
+			sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+			return
+		}
+		expected = getBiosCurrentAttributes(reference)
+		rspData.Reference = reference.common.xname
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	getBiosBulk(nodes)
+
+	for _, node := range nodes {
+		// Attribute names and values are vendor specific
+		if node.ok() && (reference != nil) &&
+			(node.bios.common.manufacturerType != reference.common.manufacturerType) {
+			node.fail(http.StatusUnprocessableEntity,
+				fmt.Errorf("ERROR: BIOS of %s is from %s, the reference is from %s",
+					node.bios.common.xname, node.bios.common.manufacturerType,
+					reference.common.manufacturerType))
+		}
+
+		elem := biosCompareRspElem{
+			Xname:      node.bios.common.xname,
+			StatusCode: node.statusCode,
+			StatusMsg:  statusMsg(node.statusCode),
+		}
+		if !node.ok() {
+			elem.StatusMsg = node.err.Error()
+		} else {
+			elem.Differences = compareBiosAttributes(expected, getBiosCurrentAttributes(node.bios),
+				jdata.Ignore, reference != nil)
+			elem.Matches = len(elem.Differences) == 0
+		}
+		rspData.Targets = append(rspData.Targets, elem)
+	}
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS compare data: %v", baerr)
+		sendErrorRsp(w, "Compare BIOS Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
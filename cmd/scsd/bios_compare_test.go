@@ -0,0 +1,101 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCompareBiosAttributes(t *testing.T) {
+	expected := map[string]interface{}{
+		"ProcessorHyperThreadingDisable": float64(0),
+		"TpmOperation":                   float64(1),
+		"SerialNumber":                   "ABC",
+		"Nic1MacAddress":                 "00:11:22:33:44:55",
+	}
+	actual := map[string]interface{}{
+		"ProcessorHyperThreadingDisable": float64(0),
+		"TpmOperation":                   float64(0),
+		"SerialNumber":                   "XYZ",
+		"Nic1MacAddress":                 "00:11:22:33:44:66",
+		"Nic2MacAddress":                 "00:11:22:33:44:77",
+		"BootMode":                       "Uefi",
+	}
+	ignore := []string{"SerialNumber", "*MacAddress"}
+
+	differences := compareBiosAttributes(expected, actual, ignore, false)
+	if len(differences) != 1 {
+		t.Fatalf("Expected 1 difference, got %v", differences)
+	}
+	if differences[0].Attribute != "TpmOperation" || differences[0].Expected != float64(1) ||
+		differences[0].Actual != float64(0) {
+		t.Errorf("Unexpected difference %v", differences[0])
+	}
+
+	differences = compareBiosAttributes(expected, actual, ignore, true)
+	if len(differences) != 2 || differences[0].Attribute != "BootMode" || differences[0].Expected != nil {
+		t.Errorf("Expected the extra BootMode attribute to be reported, got %v", differences)
+	}
+
+	differences = compareBiosAttributes(expected, actual, nil, false)
+	if len(differences) != 3 {
+		t.Errorf("Expected 3 differences without ignores, got %v", differences)
+	}
+}
+
+func TestValidateBiosComparePost(t *testing.T) {
+	valid := []biosComparePost{
+		{Targets: []string{"x0c0s0b0n0"}, Reference: "x0c0s1b0n0"},
+		{Targets: []string{"compute"}, Attributes: map[string]interface{}{"BootMode": "Uefi"}, Ignore: []string{"*Mac*"}},
+	}
+	for _, jdata := range valid {
+		if err := validateBiosComparePost(&jdata); err != nil {
+			t.Errorf("Unexpected error for %v: %v", jdata, err)
+		}
+	}
+
+	invalid := []biosComparePost{
+		{Reference: "x0c0s1b0n0"},
+		{Targets: []string{"x0c0s0b0n0"}},
+		{Targets: []string{"x0c0s0b0n0"}, Reference: "x0c0s1b0n0", Attributes: map[string]interface{}{}},
+		{Targets: []string{"x0c0s0b0n0"}, Reference: "x0c0s1b0n0", Ignore: []string{"[Mac"}},
+	}
+	for _, jdata := range invalid {
+		if err := validateBiosComparePost(&jdata); err == nil {
+			t.Errorf("Expected an error for %v", jdata)
+		}
+	}
+}
+
+func TestGetBiosCurrentAttributes(t *testing.T) {
+	bios := &Bios{
+		common: &BiosCommon{manufacturerType: cray},
+		cray: &BiosCray{
+			current: &rfBiosCray{Attributes: map[string]rfBiosAttributeCray{"SMT Control": {CurrentValue: "Auto"}}},
+		},
+	}
+	attributes := getBiosCurrentAttributes(bios)
+	if attributes["SMT Control"] != "Auto" {
+		t.Errorf("Expected the cray current value, got %v", attributes)
+	}
+}
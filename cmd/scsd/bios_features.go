@@ -0,0 +1,623 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/gorilla/mux"
+)
+
+// A named BIOS feature is a vendor-neutral setting, such as smt, that is
+// mapped onto the BIOS attribute name and values of each manufacturer.
+// The built-in catalog can be extended, or its features replaced, by a JSON
+// file in the same format named by SCSD_BIOS_FEATURES_FILE.
+
+type biosFeatureCatalog struct {
+	Features map[string]*biosFeature `json:"Features"`
+}
+
+type biosFeature struct {
+	Description string                       `json:"Description"`
+	Values      []string                     `json:"Values"`
+	Vendors     map[string]biosFeatureVendor `json:"Vendors"` // keyed by manufacturer name
+}
+
+type biosFeatureVendor struct {
+	Attribute BiosAttributeName      `json:"Attribute"`
+	Values    map[string]interface{} `json:"Values"` // feature value -> redfish value
+}
+
+type BiosFeatureState struct {
+	Current string `json:"Current"`
+	Future  string `json:"Future"`
+}
+
+type BiosFeatureStatePatch struct {
+	Future string `json:"Future"`
+	BiosApplyOptions
+}
+
+const BiosFeatureNotPresent = "NotPresent"
+
+// Fixed path segments under /bmc/bios, including the BIOS fields with their
+// own handlers. A feature with one of these names would be shadowed by the
+// fixed routes.
+var biosFeatureReserved = map[string]bool{
+	"tpmstate":   true,
+	"secureboot": true,
+	"password":   true,
+	"reset":      true,
+	"pending":    true,
+	"compare":    true,
+	"features":   true,
+	"dump":       true,
+	"load":       true,
+}
+
+// Gigabyte attributes are named by their registry display name, the same
+// as TpmStateAttributeGigabyte. The Intel values are the integers the BMC
+// reports for the attributes.
+const defaultBiosFeatures = `{
+  "Features": {
+    "smt": {
+      "Description": "Simultaneous multithreading (hyperthreading)",
+      "Values": ["Enabled", "Disabled"],
+      "Vendors": {
+        "cray": {"Attribute": "SMT Control", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "gigabyte": {"Attribute": "SMT Control", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "hpe": {"Attribute": "ProcHyperthreading", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "intel": {"Attribute": "ProcessorHyperThreadingDisable", "Values": {"Enabled": 0, "Disabled": 1}}
+      }
+    },
+    "virtualization": {
+      "Description": "Processor virtualization extensions (VT-x or AMD-V)",
+      "Values": ["Enabled", "Disabled"],
+      "Vendors": {
+        "cray": {"Attribute": "SVM Mode", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "gigabyte": {"Attribute": "SVM Mode", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "hpe": {"Attribute": "ProcVirtualization", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "intel": {"Attribute": "ProcessorVmxEnable", "Values": {"Enabled": 1, "Disabled": 0}}
+      }
+    },
+    "sriov": {
+      "Description": "PCIe single root I/O virtualization",
+      "Values": ["Enabled", "Disabled"],
+      "Vendors": {
+        "cray": {"Attribute": "SR-IOV Support", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "gigabyte": {"Attribute": "SR-IOV Support", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}},
+        "hpe": {"Attribute": "Sriov", "Values": {"Enabled": "Enabled", "Disabled": "Disabled"}}
+      }
+    },
+    "bootmode": {
+      "Description": "Firmware boot mode",
+      "Values": ["UEFI", "Legacy"],
+      "Vendors": {
+        "gigabyte": {"Attribute": "Boot mode select", "Values": {"UEFI": "UEFI", "Legacy": "LEGACY"}},
+        "hpe": {"Attribute": "BootMode", "Values": {"UEFI": "Uefi", "Legacy": "LegacyBios"}}
+      }
+    },
+    "powerprofile": {
+      "Description": "Processor power and performance profile",
+      "Values": ["Performance", "Balanced", "PowerSaving"],
+      "Vendors": {
+        "hpe": {"Attribute": "PowerRegulator", "Values": {"Performance": "StaticHighPerf", "Balanced": "DynamicPowerSavings", "PowerSaving": "StaticLowPower"}}
+      }
+    }
+  }
+}`
+
+var biosFeaturesFile string
+var biosFeatures = defaultBiosFeatureCatalog()
+
+func defaultBiosFeatureCatalog() map[string]*biosFeature {
+	features, err := parseBiosFeatures([]byte(defaultBiosFeatures))
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in BIOS feature catalog: %v", err))
+	}
+	return features
+}
+
+func toManufacturerType(name string) manufacturerType {
+	for m, mname := range manufacturerNames {
+		if strings.EqualFold(mname, name) {
+			return m
+		}
+	}
+	return unknown
+}
+
+// Parses and validates a BIOS feature catalog. The feature names are
+// returned in lower case.
+func parseBiosFeatures(data []byte) (features map[string]*biosFeature, err error) {
+	var catalog biosFeatureCatalog
+	err = json.Unmarshal(data, &catalog)
+	if err != nil {
+		return
+	}
+
+	features = make(map[string]*biosFeature)
+	for name, feature := range catalog.Features {
+		fname := strings.ToLower(name)
+		if fname == "" || biosFeatureReserved[fname] || strings.Contains(fname, "/") {
+			err = fmt.Errorf("invalid feature name '%s'", name)
+			return
+		}
+		if feature == nil || len(feature.Values) == 0 {
+			err = fmt.Errorf("feature %s has no values", name)
+			return
+		}
+		for vname, vendor := range feature.Vendors {
+			if toManufacturerType(vname) == unknown {
+				err = fmt.Errorf("feature %s has unknown vendor %s", name, vname)
+				return
+			}
+			if vendor.Attribute == "" {
+				err = fmt.Errorf("feature %s has no attribute for vendor %s", name, vname)
+				return
+			}
+			values := make(map[string]interface{})
+			for value, rfValue := range vendor.Values {
+				fvalue, ok := feature.toValue(value)
+				if !ok {
+					err = fmt.Errorf("feature %s vendor %s maps unknown value %s", name, vname, value)
+					return
+				}
+				values[fvalue] = rfValue
+			}
+			vendor.Values = values
+			feature.Vendors[vname] = vendor
+		}
+		features[fname] = feature
+	}
+	return
+}
+
+// Loads a BIOS feature catalog file. Features in the file are added to the
+// catalog and replace any feature with the same name.
+func loadBiosFeatures(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	features, err := parseBiosFeatures(data)
+	if err != nil {
+		return err
+	}
+	for name, feature := range features {
+		biosFeatures[name] = feature
+	}
+	return nil
+}
+
+func getBiosFeature(name string) (feature *biosFeature, found bool) {
+	feature, found = biosFeatures[strings.ToLower(name)]
+	return
+}
+
+// Returns the catalog spelling of a feature value, matched without regard
+// to case.
+func (feature *biosFeature) toValue(value string) (string, bool) {
+	for _, v := range feature.Values {
+		if strings.EqualFold(v, value) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Returns the feature value of a redfish value. Redfish values that are not
+// in the mapping are returned as is.
+func (vendor *biosFeatureVendor) toFeatureValue(value interface{}) string {
+	str := fmt.Sprintf("%v", value)
+	for featureValue, rfValue := range vendor.Values {
+		if fmt.Sprintf("%v", rfValue) == str {
+			return featureValue
+		}
+	}
+	return str
+}
+
+// Returns the vendor specific BIOS attributes and values for a future
+// feature value. Vendors without a mapping for the value are left empty.
+func (feature *biosFeature) toPatch(future string) (attributeName PatchAttributeName, attributeValue PatchAttributeValue, err error) {
+	value, ok := feature.toValue(future)
+	if !ok {
+		err = fmt.Errorf("ERROR: Invalid future value: %s", future)
+		return
+	}
+	for vname, vendor := range feature.Vendors {
+		rfValue, ok := vendor.Values[value]
+		if !ok {
+			continue
+		}
+		switch toManufacturerType(vname) {
+		case cray:
+			attributeName.cray, attributeValue.cray = vendor.Attribute, rfValue
+		case gigabyte:
+			attributeName.gigabyte, attributeValue.gigabyte = vendor.Attribute, rfValue
+		case hpe:
+			attributeName.hpe, attributeValue.hpe = vendor.Attribute, rfValue
+		case intel:
+			attributeName.intel, attributeValue.intel = vendor.Attribute, rfValue
+		}
+	}
+	return
+}
+
+func getAttributeValue(currentAttributes, futureAttributes map[string]interface{}, key string) (current, future interface{}, found bool) {
+	current, found = currentAttributes[key]
+	if !found {
+		return
+	}
+	future, ok := futureAttributes[key]
+	if !ok {
+		future = current
+	}
+	return
+}
+
+// Returns the current and future redfish values of a BIOS attribute. The
+// future value is the current value when no change is pending.
+func getBiosAttributeValue(bios *Bios, name BiosAttributeName) (current, future interface{}, found bool) {
+	switch bios.common.manufacturerType {
+	case cray:
+		attribute, ok := bios.cray.current.Attributes[string(name)]
+		if !ok {
+			return
+		}
+		current, found = attribute.CurrentValue, true
+		future, ok = bios.cray.future.Attributes[string(name)]
+		if !ok {
+			future = current
+		}
+	case gigabyte:
+		attribute, ok := getAttribute(string(name), bios.gigabyte.biosAttributes)
+		if !ok {
+			return
+		}
+		current, future, found = getAttributeValue(
+			bios.gigabyte.current.Attributes, bios.gigabyte.future.Attributes, attribute.AttributeName)
+	case hpe:
+		current, future, found = getAttributeValue(
+			bios.hpe.current.Attributes, bios.hpe.future.Attributes, string(name))
+	case intel:
+		names := getAttributeNamesIntel(name, bios.intel.current.Attributes)
+		if len(names) == 0 {
+			return
+		}
+		current, future, found = getAttributeValue(
+			bios.intel.current.Attributes, bios.intel.future.Attributes, names[0])
+	}
+	return
+}
+
+func toBiosFeatureState(bios *Bios, feature *biosFeature) BiosFeatureState {
+	state := BiosFeatureState{
+		Current: BiosFeatureNotPresent,
+		Future:  BiosFeatureNotPresent,
+	}
+	vendor, ok := feature.Vendors[bios.common.manufacturerType.String()]
+	if !ok {
+		return state
+	}
+	current, future, found := getBiosAttributeValue(bios, vendor.Attribute)
+	if found {
+		state.Current = vendor.toFeatureValue(current)
+		state.Future = vendor.toFeatureValue(future)
+	}
+	return state
+}
+
+func sendBiosFeatureUnknown(w http.ResponseWriter, r *http.Request, name string) {
+	emsg := fmt.Sprintf("ERROR: Unknown BIOS feature: %s", name)
+	sendErrorRsp(w, "Unknown BIOS feature", emsg, r.URL.Path, http.StatusNotFound)
+}
+
+// /v1/bmc/bios/features GET
+
+type biosFeatureInfo struct {
+	Name        string   `json:"Name"`
+	Description string   `json:"Description"`
+	Values      []string `json:"Values"`
+	Vendors     []string `json:"Vendors"`
+}
+
+type biosFeaturesRsp struct {
+	Features []biosFeatureInfo `json:"Features"`
+}
+
+func doBiosFeaturesGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var rspData biosFeaturesRsp
+	for name, feature := range biosFeatures {
+		info := biosFeatureInfo{
+			Name:        name,
+			Description: feature.Description,
+			Values:      feature.Values,
+			Vendors:     []string{},
+		}
+		for vname := range feature.Vendors {
+			info.Vendors = append(info.Vendors, vname)
+		}
+		sort.Strings(info.Vendors)
+		rspData.Features = append(rspData.Features, info)
+	}
+	sort.Slice(rspData.Features, func(i, j int) bool {
+		return rspData.Features[i].Name < rspData.Features[j].Name
+	})
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS feature data: %v", baerr)
+		sendErrorRsp(w, "Get BIOS Features Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/{xname}/{feature} GET
+
+func doBiosFeatureGet(w http.ResponseWriter, r *http.Request) {
+	title := "Get BIOS Feature"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["feature"]
+	feature, ok := getBiosFeature(name)
+	if !ok {
+		sendBiosFeatureUnknown(w, r, name)
+		return
+	}
+
+	bios, err, httpCode := getBios(r)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+	state := toBiosFeatureState(bios, feature)
+
+	ba, baerr := json.Marshal(state)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS feature data: %v", baerr)
+		sendErrorRsp(w, "Get BIOS Feature Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/{xname}/{feature} PATCH
+
+func doBiosFeaturePatch(w http.ResponseWriter, r *http.Request) {
+	title := "Patch BIOS Feature"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["feature"]
+	feature, ok := getBiosFeature(name)
+	if !ok {
+		sendBiosFeatureUnknown(w, r, name)
+		return
+	}
+
+	var requestBody BiosFeatureStatePatch
+
+	err := getReqData(title, r, &requestBody)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	attributeName, attributeValue, err := feature.toPatch(requestBody.Future)
+	if err == nil {
+		err = requestBody.BiosApplyOptions.validate()
+	}
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	rsp, err, httpCode := patchBios(r, attributeName, attributeValue, requestBody.BiosApplyOptions)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	sendBiosApplyRsp(w, r, rsp)
+}
+
+// Used by /v1/bmc/bios/dump/{feature} POST to fetch a feature of many nodes
+
+type biosFeatureDumpPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+}
+
+// Used by /v1/bmc/bios/load/{feature} POST to set a feature of many nodes
+
+type biosFeatureLoadPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+	Future  string   `json:"Future"`
+	BiosApplyOptions
+}
+
+// Return of /v1/bmc/bios/dump/{feature} and /v1/bmc/bios/load/{feature} POST
+
+type biosFeatureRspElem struct {
+	Xname        string `json:"Xname"`
+	StatusCode   int    `json:"StatusCode"`
+	StatusMsg    string `json:"StatusMsg"`
+	Current      string `json:"Current,omitempty"`
+	Future       string `json:"Future,omitempty"`
+	ResetPending *bool  `json:"ResetPending,omitempty"` // load only
+}
+
+type biosFeatureRsp struct {
+	Targets []biosFeatureRspElem `json:"Targets"`
+}
+
+func toBiosFeatureRspElem(node *biosBulkNode, feature *biosFeature) biosFeatureRspElem {
+	elem := biosFeatureRspElem{
+		Xname:      node.bios.common.xname,
+		StatusCode: node.statusCode,
+		StatusMsg:  statusMsg(node.statusCode),
+	}
+	if !node.ok() {
+		elem.StatusMsg = node.err.Error()
+		return elem
+	}
+	state := toBiosFeatureState(node.bios, feature)
+	elem.Current = state.Current
+	elem.Future = state.Future
+	return elem
+}
+
+func sendBiosFeatureRsp(w http.ResponseWriter, r *http.Request, nodes []*biosBulkNode, feature *biosFeature, future string, resetPending map[*biosBulkNode]bool) {
+	var rspData biosFeatureRsp
+	for _, node := range nodes {
+		elem := toBiosFeatureRspElem(node, feature)
+		if node.ok() && (future != "") {
+			elem.Future = future
+		}
+		if pending, ok := resetPending[node]; ok && node.ok() {
+			elem.ResetPending = &pending
+		}
+		rspData.Targets = append(rspData.Targets, elem)
+	}
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS feature data: %v", baerr)
+		sendErrorRsp(w, "BIOS Feature Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/dump/{feature} POST
+
+func doBiosFeatureDumpPost(w http.ResponseWriter, r *http.Request) {
+	title := "Get BIOS Feature"
+	var jdata biosFeatureDumpPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["feature"]
+	feature, ok := getBiosFeature(name)
+	if !ok {
+		sendBiosFeatureUnknown(w, r, name)
+		return
+	}
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	getBiosBulk(nodes)
+	sendBiosFeatureRsp(w, r, nodes, feature, "", nil)
+}
+
+// /v1/bmc/bios/load/{feature} POST
+
+func doBiosFeatureLoadPost(w http.ResponseWriter, r *http.Request) {
+	title := "Patch BIOS Feature"
+	var jdata biosFeatureLoadPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["feature"]
+	feature, ok := getBiosFeature(name)
+	if !ok {
+		sendBiosFeatureUnknown(w, r, name)
+		return
+	}
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	attributeName, attributeValue, err := feature.toPatch(jdata.Future)
+	if err == nil {
+		err = jdata.BiosApplyOptions.validate()
+	}
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	resetPending := patchBiosBulk(nodes, attributeName, attributeValue, jdata.BiosApplyOptions)
+	future, _ := feature.toValue(jdata.Future)
+	sendBiosFeatureRsp(w, r, nodes, feature, future, resetPending)
+}
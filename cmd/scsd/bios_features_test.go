@@ -0,0 +1,157 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBiosFeatures(t *testing.T) {
+	for _, name := range []string{"smt", "virtualization", "sriov", "bootmode", "powerprofile"} {
+		if _, ok := getBiosFeature(name); !ok {
+			t.Errorf("Expected built-in feature %s", name)
+		}
+	}
+
+	features, err := parseBiosFeatures([]byte(`{"Features":{"NUMA":{"Values":["Enabled","Disabled"],
+		"Vendors":{"hpe":{"Attribute":"NumaGroupSizeOpt","Values":{"enabled":"Clustered"}}}}}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	feature, ok := features["numa"]
+	if !ok {
+		t.Fatalf("Expected the feature name in lower case, got %v", features)
+	}
+	if feature.Vendors["hpe"].Values["Enabled"] != "Clustered" {
+		t.Errorf("Expected the value mapped to the catalog spelling, got %v", feature.Vendors["hpe"].Values)
+	}
+
+	invalid := []string{
+		`{"Features":{"tpmstate":{"Values":["Enabled"]}}}`,
+		`{"Features":{"SecureBoot":{"Values":["Enabled"]}}}`,
+		`{"Features":{"password":{"Values":["Enabled"]}}}`,
+		`{"Features":{"Reset":{"Values":["Enabled"]}}}`,
+		`{"Features":{"compare":{"Values":["Enabled"]}}}`,
+		`{"Features":{"features":{"Values":["Enabled"]}}}`,
+		`{"Features":{"dump":{"Values":["Enabled"]}}}`,
+		`{"Features":{"smt":{"Values":[]}}}`,
+		`{"Features":{"smt":{"Values":["On"],"Vendors":{"dell":{"Attribute":"X"}}}}}`,
+		`{"Features":{"smt":{"Values":["On"],"Vendors":{"hpe":{"Attribute":""}}}}}`,
+		`{"Features":{"smt":{"Values":["On"],"Vendors":{"hpe":{"Attribute":"X","Values":{"Off":"0"}}}}}}`,
+		`{"Features":`,
+	}
+	for _, data := range invalid {
+		if _, err := parseBiosFeatures([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+}
+
+func TestLoadBiosFeatures(t *testing.T) {
+	saved := biosFeatures
+	defer func() { biosFeatures = saved }()
+	biosFeatures = defaultBiosFeatureCatalog()
+
+	path := filepath.Join(t.TempDir(), "features.json")
+	data := `{"Features":{"smt":{"Values":["Enabled","Disabled"],
+		"Vendors":{"intel":{"Attribute":"HyperThreading","Values":{"Enabled":1,"Disabled":0}}}},
+		"turbo":{"Values":["Enabled","Disabled"],
+		"Vendors":{"hpe":{"Attribute":"ProcTurbo","Values":{"Enabled":"Enabled","Disabled":"Disabled"}}}}}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadBiosFeatures(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := getBiosFeature("turbo"); !ok {
+		t.Errorf("Expected the turbo feature to be added")
+	}
+	smt, _ := getBiosFeature("smt")
+	if _, ok := smt.Vendors["hpe"]; ok || smt.Vendors["intel"].Attribute != "HyperThreading" {
+		t.Errorf("Expected the smt feature to be replaced, got %v", smt.Vendors)
+	}
+	if _, ok := getBiosFeature("bootmode"); !ok {
+		t.Errorf("Expected the built-in bootmode feature to be kept")
+	}
+
+	if err := loadBiosFeatures(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestBiosFeatureToPatch(t *testing.T) {
+	feature, _ := getBiosFeature("bootmode")
+
+	name, value, err := feature.toPatch("legacy")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name.hpe != "BootMode" || value.hpe != "LegacyBios" {
+		t.Errorf("Expected hpe BootMode LegacyBios, got %s %v", name.hpe, value.hpe)
+	}
+	if name.forVendor(intel) != "" || name.forVendor(cray) != "" {
+		t.Errorf("Expected no intel or cray attribute, got %v", name)
+	}
+
+	_, _, err = feature.toPatch("Enabled")
+	if err == nil {
+		t.Errorf("Expected an error for an invalid value")
+	}
+}
+
+func TestToBiosFeatureState(t *testing.T) {
+	smt, _ := getBiosFeature("smt")
+
+	bios := &Bios{
+		common: &BiosCommon{manufacturerType: intel},
+		intel: &BiosIntel{
+			current: &rfBiosIntel{Attributes: map[string]interface{}{"ProcessorHyperThreadingDisable": float64(0)}},
+			future:  &rfBiosIntel{Attributes: map[string]interface{}{"ProcessorHyperThreadingDisable": float64(1)}},
+		},
+	}
+	state := toBiosFeatureState(bios, smt)
+	if state.Current != "Enabled" || state.Future != "Disabled" {
+		t.Errorf("Expected Enabled/Disabled, got %v", state)
+	}
+
+	bios = &Bios{
+		common: &BiosCommon{manufacturerType: cray},
+		cray: &BiosCray{
+			current: &rfBiosCray{Attributes: map[string]rfBiosAttributeCray{"SMT Control": {CurrentValue: "Auto"}}},
+			future:  &rfBiosSDCray{},
+		},
+	}
+	state = toBiosFeatureState(bios, smt)
+	if state.Current != "Auto" || state.Future != "Auto" {
+		t.Errorf("Expected unmapped values to be returned as is, got %v", state)
+	}
+
+	powerProfile, _ := getBiosFeature("powerprofile")
+	state = toBiosFeatureState(bios, powerProfile)
+	if state.Current != BiosFeatureNotPresent || state.Future != BiosFeatureNotPresent {
+		t.Errorf("Expected NotPresent for a vendor without a mapping, got %v", state)
+	}
+}
@@ -0,0 +1,415 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/gorilla/mux"
+)
+
+/*
+Example of the ChangePassword action in /redfish/v1/Systems/1/Bios
+
+	{
+	  "Actions": {
+	    "#Bios.ChangePassword": {
+	      "target": "/redfish/v1/Systems/1/Bios/Actions/Bios.ChangePassword"
+	    }
+	  },
+	  ...
+	}
+*/
+type rfBiosChangePasswordAction struct {
+	Target string `json:"target"`
+}
+
+type rfBiosChangePassword struct {
+	PasswordName string `json:"PasswordName"`
+	OldPassword  string `json:"OldPassword"`
+	NewPassword  string `json:"NewPassword"`
+}
+
+const BiosPasswordNameDefault = "AdminPassword"
+
+// Generated passwords avoid characters that are easily confused and
+// characters some BIOS setup screens cannot enter.
+const biosPasswordLength = 16
+const biosPasswordChars = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+
+// BIOS passwords are stored in Vault under VaultBiosKeypath, apart from the
+// BMC creds.
+
+type biosCreds struct {
+	Xname    string `json:"Xname"`
+	Password string `json:"Password"`
+}
+
+// Used by /v1/bmc/bios/{xname}/password POST to change the BIOS password
+
+type BiosPasswordPost struct {
+	PasswordName string `json:"PasswordName,omitempty"`
+	OldPassword  string `json:"OldPassword,omitempty"`
+	NewPassword  string `json:"NewPassword,omitempty"`
+}
+
+// Used by /v1/bmc/bios/password POST to change the BIOS password of many nodes
+
+type biosPasswordBulkPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+	BiosPasswordPost
+}
+
+func vaultEnabled() bool {
+	return (appParams.VaultEnable != nil) && *appParams.VaultEnable && (biosCredStore != nil)
+}
+
+// Returns a random password with at least one upper case letter, one lower
+// case letter and one digit.
+func generateBiosPassword() (string, error) {
+	max := big.NewInt(int64(len(biosPasswordChars)))
+	for {
+		pw := make([]byte, biosPasswordLength)
+		for ii := range pw {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("ERROR: Problem generating a BIOS password: %v", err)
+			}
+			pw[ii] = biosPasswordChars[n.Int64()]
+		}
+		password := string(pw)
+		if strings.ContainsAny(password, "ABCDEFGHJKLMNPQRSTUVWXYZ") &&
+			strings.ContainsAny(password, "abcdefghijkmnopqrstuvwxyz") &&
+			strings.ContainsAny(password, "23456789") {
+			return password, nil
+		}
+	}
+}
+
+// Returns the BIOS password of a node from Vault, or an empty string if
+// there is none.
+func getBiosPassword(xname string) (string, error) {
+	if !vaultEnabled() {
+		return "", nil
+	}
+
+	var creds biosCreds
+	err := biosCredStore.Lookup(VaultBiosKeypath+"/"+xname, &creds)
+	if err != nil {
+		return "", fmt.Errorf("ERROR: Unable to read BIOS password from vault for '%s': %v",
+			xname, err)
+	}
+	return creds.Password, nil
+}
+
+// Update the BIOS password in vault for a given node.
+
+func storeBiosPassword(xname, password string) error {
+	if !vaultEnabled() {
+		return nil
+	}
+
+	err := biosCredStore.Store(VaultBiosKeypath+"/"+xname,
+		biosCreds{Xname: xname, Password: password})
+	if err != nil {
+		return fmt.Errorf("ERROR: BIOS password of '%s' changed, unable to write it to vault: %v",
+			xname, err)
+	}
+	return nil
+}
+
+// Fills in the defaults of a password change for a node. The old password
+// comes from Vault and the new one is generated when they are not given.
+func makeBiosChangePassword(xname string, post BiosPasswordPost) (change rfBiosChangePassword, err error, httpCode int) {
+	change = rfBiosChangePassword{
+		PasswordName: post.PasswordName,
+		OldPassword:  post.OldPassword,
+		NewPassword:  post.NewPassword,
+	}
+	if change.PasswordName == "" {
+		change.PasswordName = BiosPasswordNameDefault
+	}
+
+	if change.OldPassword == "" {
+		change.OldPassword, err = getBiosPassword(xname)
+		if err != nil {
+			return change, err, http.StatusInternalServerError
+		}
+	}
+
+	if change.NewPassword == "" {
+		change.NewPassword, err = generateBiosPassword()
+		if err != nil {
+			return change, err, http.StatusInternalServerError
+		}
+	}
+	return change, nil, http.StatusOK
+}
+
+// Checks the request before any node is contacted. A generated password
+// would be lost without Vault.
+func validateBiosPasswordPost(post BiosPasswordPost) error {
+	if (post.NewPassword == "") && !vaultEnabled() {
+		return fmt.Errorf("ERROR: NewPassword is required when Vault is disabled")
+	}
+	return nil
+}
+
+// Returns the Bios.ChangePassword action request. The action is assumed to
+// be at the standard location when the BMC does not advertise it.
+func makeChangePasswordRequest(biosCommon *BiosCommon, actions *rfBiosActionsResource, change rfBiosChangePassword) (*biosPatchRequest, error) {
+	uri := actions.Actions.ChangePassword.Target
+	if uri == "" {
+		uri = biosCommon.biosUri + "/Actions/Bios.ChangePassword"
+	}
+
+	body, err := json.Marshal(change)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: Problem marshaling BIOS password change: %v", err)
+	}
+
+	return &biosPatchRequest{
+		uri:  uri,
+		body: body,
+	}, nil
+}
+
+func changeBiosPassword(r *http.Request, post BiosPasswordPost) (err error, httpCode int) {
+	xname, err, httpCode := validateXname(mux.Vars(r)["xname"])
+	if err != nil {
+		return
+	}
+
+	biosCommon, err, httpCode := getBiosCommon(xname)
+	if err != nil {
+		return
+	}
+
+	// ---- /redfish/v1/Systems/{system}/Bios ----
+
+	var actions rfBiosActionsResource
+	err, httpCode = getRedfishAndParseResponse(
+		"Systems/*/Bios", biosCommon.bmcXname, biosCommon.targets, biosCommon.biosUri, &actions)
+	if err != nil {
+		return
+	}
+
+	change, err, httpCode := makeBiosChangePassword(biosCommon.xname, post)
+	if err != nil {
+		return
+	}
+
+	request, err := makeChangePasswordRequest(biosCommon, &actions, change)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+
+	// ---- /redfish/v1/Systems/{system}/Bios/Actions/Bios.ChangePassword ----
+
+	err, httpCode = postBiosRequest(biosCommon, request)
+	if err != nil {
+		return
+	}
+
+	logger.Infof("INFO: BIOS password for '%s' successfully updated.", biosCommon.xname)
+	err = storeBiosPassword(biosCommon.xname, change.NewPassword)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusNoContent
+}
+
+// Bulk version of changeBiosPassword()
+
+func changeBiosPasswordBulk(nodes []*biosBulkNode, post BiosPasswordPost) {
+	getBiosBulkCommon(nodes)
+
+	// ---- /redfish/v1/Systems/{system}/Bios ----
+
+	actions := make(map[*biosBulkNode]*rfBiosActionsResource)
+	getBiosBulkResource("Systems/*/Bios", nodes, false,
+		func(node *biosBulkNode) string { return node.bios.common.biosUri },
+		func(node *biosBulkNode) interface{} { return &rfBiosActionsResource{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				biosBulkNotFound(node, node.bios.common.biosUri)
+				return
+			}
+			actions[node] = data.(*rfBiosActionsResource)
+		})
+
+	// ---- /redfish/v1/Systems/{system}/Bios/Actions/Bios.ChangePassword ----
+
+	passwords := make(map[*biosBulkNode]string)
+	var reqs []biosBulkRequest
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		change, err, httpCode := makeBiosChangePassword(node.bios.common.xname, post)
+		if err != nil {
+			node.fail(httpCode, err)
+			continue
+		}
+		request, err := makeChangePasswordRequest(node.bios.common, actions[node], change)
+		if err != nil {
+			node.fail(http.StatusInternalServerError, err)
+			continue
+		}
+		passwords[node] = change.NewPassword
+		reqs = append(reqs, biosBulkRequest{
+			node: node,
+			uri:  request.uri,
+			body: request.body,
+		})
+	}
+
+	tasks, err := doBiosBulkRequests(http.MethodPost, reqs)
+	if err != nil {
+		for _, req := range reqs {
+			req.node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Change BIOS password call %s failed: %v", req.uri, err))
+		}
+		return
+	}
+
+	//Only store the passwords of the nodes that took the change.
+
+	for ii := range tasks {
+		node := reqs[ii].node
+		statusCode := getStatusCode(&tasks[ii])
+		if !statusCodeOK(statusCode) {
+			logger.Infof("INFO: BIOS password change failed for '%s', password unchanged.",
+				node.bios.common.xname)
+			node.fail(statusCode,
+				fmt.Errorf("ERROR: Redfish change BIOS password failed %s %d", reqs[ii].uri, statusCode))
+			continue
+		}
+		logger.Infof("INFO: BIOS password for '%s' successfully updated.", node.bios.common.xname)
+		err = storeBiosPassword(node.bios.common.xname, passwords[node])
+		if err != nil {
+			logger.Errorf("%v", err)
+			node.fail(http.StatusInternalServerError, err)
+		}
+	}
+}
+
+// /v1/bmc/bios/{xname}/password POST
+
+func doBiosPasswordPost(w http.ResponseWriter, r *http.Request) {
+	title := "Change BIOS Password"
+	var jdata BiosPasswordPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	err = validateBiosPasswordPost(jdata)
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	err, httpCode := changeBiosPassword(r, jdata)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// /v1/bmc/bios/password POST
+
+func doBiosPasswordBulkPost(w http.ResponseWriter, r *http.Request) {
+	title := "Change BIOS Password"
+	var jdata biosPasswordBulkPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	err = validateBiosPasswordPost(jdata.BiosPasswordPost)
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	changeBiosPasswordBulk(nodes, jdata.BiosPasswordPost)
+
+	var retData loadCfgPostRsp
+	for _, node := range nodes {
+		elem := loadCfgPostRspElem{
+			Xname:      node.bios.common.xname,
+			StatusCode: node.statusCode,
+			StatusMsg:  statusMsg(node.statusCode),
+		}
+		if !node.ok() {
+			elem.StatusMsg = node.err.Error()
+		}
+		retData.Targets = append(retData.Targets, elem)
+	}
+
+	ba, baerr := json.Marshal(&retData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS password return data: %v", baerr)
+		sendErrorRsp(w, "Change BIOS Password Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
@@ -0,0 +1,123 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+func TestGenerateBiosPassword(t *testing.T) {
+	seen := make(map[string]bool)
+	for ii := 0; ii < 20; ii++ {
+		pw, err := generateBiosPassword()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pw) != biosPasswordLength {
+			t.Errorf("Expected a %d character password, got '%s'", biosPasswordLength, pw)
+		}
+		if !strings.ContainsAny(pw, "0123456789") || (strings.ToLower(pw) == pw) || (strings.ToUpper(pw) == pw) {
+			t.Errorf("Expected upper case, lower case and digits, got '%s'", pw)
+		}
+		if seen[pw] {
+			t.Errorf("Duplicate password '%s'", pw)
+		}
+		seen[pw] = true
+	}
+}
+
+func TestMakeBiosChangePassword(t *testing.T) {
+	savedStore, savedPath := biosCredStore, VaultBiosKeypath
+	defer func() {
+		biosCredStore, VaultBiosKeypath = savedStore, savedPath
+		appParams.VaultEnable = new(bool)
+	}()
+
+	//Without Vault the new password must be given.
+
+	appParams.VaultEnable = new(bool)
+	if err := validateBiosPasswordPost(BiosPasswordPost{}); err == nil {
+		t.Errorf("Expected an error for a missing NewPassword without Vault")
+	}
+	change, err, _ := makeBiosChangePassword("x0c0s0b0n0", BiosPasswordPost{NewPassword: "new"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if change.PasswordName != BiosPasswordNameDefault || change.OldPassword != "" || change.NewPassword != "new" {
+		t.Errorf("Unexpected password change %v", change)
+	}
+
+	//With Vault the old password is looked up and the new one generated.
+
+	ss, adapter := sstorage.NewMockAdapter()
+	biosCredStore = ss
+	VaultBiosKeypath = "secret/hms-bios-creds"
+	adapter.LookupData = []sstorage.MockLookup{
+		{Output: sstorage.OutputLookup{Output: &biosCreds{Xname: "x0c0s0b0n0", Password: "old"}}},
+	}
+	adapter.StoreData = []sstorage.MockStore{{}}
+	ve := true
+	appParams.VaultEnable = &ve
+
+	if err := validateBiosPasswordPost(BiosPasswordPost{}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	change, err, _ = makeBiosChangePassword("x0c0s0b0n0", BiosPasswordPost{PasswordName: "SETUP001"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if adapter.LookupData[0].Input.Key != "secret/hms-bios-creds/x0c0s0b0n0" {
+		t.Errorf("Unexpected Vault key '%s'", adapter.LookupData[0].Input.Key)
+	}
+	if change.PasswordName != "SETUP001" || change.OldPassword != "old" || len(change.NewPassword) != biosPasswordLength {
+		t.Errorf("Unexpected password change %v", change)
+	}
+
+	if err := storeBiosPassword("x0c0s0b0n0", change.NewPassword); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stored, ok := adapter.StoreData[0].Input.Value.(biosCreds)
+	if !ok || stored.Password != change.NewPassword {
+		t.Errorf("Expected the new password to be stored, got %v", adapter.StoreData[0].Input.Value)
+	}
+}
+
+func TestMakeChangePasswordRequest(t *testing.T) {
+	biosCommon := &BiosCommon{biosUri: "/redfish/v1/Systems/1/Bios"}
+	change := rfBiosChangePassword{PasswordName: "AdminPassword", NewPassword: "new"}
+
+	request, err := makeChangePasswordRequest(biosCommon, &rfBiosActionsResource{}, change)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if request.uri != "/redfish/v1/Systems/1/Bios/Actions/Bios.ChangePassword" {
+		t.Errorf("Expected the standard action uri, got %s", request.uri)
+	}
+	exp := `{"PasswordName":"AdminPassword","OldPassword":"","NewPassword":"new"}`
+	if string(request.body) != exp {
+		t.Errorf("Expected body %s, got %s", exp, string(request.body))
+	}
+}
@@ -0,0 +1,336 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+// Pending BIOS settings are the attributes of the future settings object
+// whose value differs from the current value. They take effect on the next
+// reboot of the node.
+
+type BiosPendingAttribute struct {
+	Attribute   string      `json:"Attribute"`
+	DisplayName string      `json:"DisplayName,omitempty"` // gigabyte only
+	Current     interface{} `json:"Current"`
+	Future      interface{} `json:"Future"`
+}
+
+type BiosPending struct {
+	Pending []BiosPendingAttribute `json:"Pending"`
+}
+
+func getPendingAttributes(current, future map[string]interface{}) (pending []BiosPendingAttribute) {
+	pending = []BiosPendingAttribute{}
+	for name, futureValue := range future {
+		currentValue := current[name]
+		if fmt.Sprintf("%v", currentValue) != fmt.Sprintf("%v", futureValue) {
+			pending = append(pending, BiosPendingAttribute{
+				Attribute: name,
+				Current:   currentValue,
+				Future:    futureValue,
+			})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Attribute < pending[j].Attribute
+	})
+	return
+}
+
+// Returns the attributes that will change on the next reboot
+func getBiosPending(bios *Bios) (pending []BiosPendingAttribute) {
+	switch bios.common.manufacturerType {
+	case cray:
+		pending = getPendingAttributes(getBiosCurrentAttributes(bios), bios.cray.future.Attributes)
+	case gigabyte:
+		pending = getPendingAttributes(bios.gigabyte.current.Attributes, bios.gigabyte.future.Attributes)
+		for i := range pending {
+			for _, attribute := range bios.gigabyte.biosAttributes.RegistryEntries.Attributes {
+				if attribute.AttributeName == pending[i].Attribute {
+					pending[i].DisplayName = attribute.DisplayName
+					break
+				}
+			}
+		}
+	case hpe:
+		pending = getPendingAttributes(bios.hpe.current.Attributes, bios.hpe.future.Attributes)
+	case intel:
+		pending = getPendingAttributes(bios.intel.current.Attributes, bios.intel.future.Attributes)
+	default:
+		pending = []BiosPendingAttribute{}
+	}
+	return
+}
+
+// Result of clearing one pending attribute.  StatusCode is 200 when the
+// attribute was cleared, 409 when it is still pending.
+
+type BiosPendingClearAttribute struct {
+	Attribute  string `json:"Attribute"`
+	StatusCode int    `json:"StatusCode"`
+	StatusMsg  string `json:"StatusMsg"`
+}
+
+// Return of /v1/bmc/bios/{xname} DELETE
+
+type BiosPendingClearRsp struct {
+	Attributes []BiosPendingClearAttribute `json:"Attributes"`
+	*BiosApplyRsp
+}
+
+// Sets the uri and etag of a request to the future settings object.
+func setBiosFutureTarget(bios *Bios, request *biosPatchRequest) {
+	switch bios.common.manufacturerType {
+	case cray:
+		request.uri = bios.cray.futureUri
+		request.etag = bios.cray.future.ETag
+		if request.etag == "" {
+			request.etag = "*"
+		}
+	case gigabyte:
+		request.uri = bios.gigabyte.futureUri
+		request.etag = bios.gigabyte.future.ETag
+		if request.etag == "" {
+			request.etag = "*"
+		}
+	case hpe:
+		request.uri = bios.hpe.futureUri
+	case intel:
+		request.uri = bios.intel.futureUri
+		request.etag = bios.intel.future.ETag
+	}
+}
+
+// Returns a patch of the future settings that sets the pending attributes
+// back to their current values, and the pending attributes which have no
+// current value to be set back to. Returns a nil request when there is
+// nothing to set back.
+func makeClearPendingRequest(bios *Bios) (request *biosPatchRequest, notCleared []string, err error, httpCode int) {
+	httpCode = http.StatusOK
+
+	attributes := make(map[string]interface{})
+	for _, attribute := range getBiosPending(bios) {
+		if attribute.Current != nil {
+			attributes[attribute.Attribute] = attribute.Current
+		} else {
+			notCleared = append(notCleared, attribute.Attribute)
+		}
+	}
+	if len(attributes) == 0 {
+		return
+	}
+
+	rfRequestBody, err := json.Marshal(map[string]interface{}{"Attributes": attributes})
+	if err != nil {
+		err = fmt.Errorf("ERROR: Failed to create the request to clear pending BIOS settings: %v", err)
+		httpCode = http.StatusInternalServerError
+		return
+	}
+
+	request = &biosPatchRequest{body: rfRequestBody}
+	setBiosFutureTarget(bios, request)
+	return
+}
+
+// Deletes the future settings object of a node, which discards all the
+// staged settings on BMCs that support it. Returns the status code of the
+// BMC; an error only when the request could not be sent.
+func deleteBiosFuture(bios *Bios) (statusCode int, err error) {
+	var request biosPatchRequest
+	setBiosFutureTarget(bios, &request)
+
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodDelete, "", nil)
+	tasks := tloc.CreateTaskList(&sourceTL, len(bios.common.targets))
+	populateTaskList(tasks, toXnames(bios.common.targets), request.uri, http.MethodDelete, nil)
+	if request.etag != "" {
+		for ii := range tasks {
+			tasks[ii].Request.Header.Set("If-Match", request.etag)
+		}
+	}
+
+	err = doOp(tasks)
+	if err != nil {
+		err = fmt.Errorf("ERROR: Delete call %s failed for %s: %v", request.uri, bios.common.xname, err)
+		return http.StatusInternalServerError, err
+	}
+
+	statusCode = http.StatusOK
+	for ii := range tasks {
+		code := getStatusCode(&tasks[ii])
+		if !statusCodeOK(code) {
+			statusCode = code
+		}
+	}
+	return
+}
+
+// Returns the per-attribute result of clearing the pending attributes and
+// the HTTP status of the response: 207 when some are still pending.
+func makeBiosPendingClearRsp(pending []BiosPendingAttribute, notCleared []string) (rsp BiosPendingClearRsp, httpCode int) {
+	httpCode = http.StatusOK
+	rsp.Attributes = []BiosPendingClearAttribute{}
+	for _, attribute := range pending {
+		elem := BiosPendingClearAttribute{Attribute: attribute.Attribute,
+			StatusCode: http.StatusOK, StatusMsg: "Cleared"}
+		for _, name := range notCleared {
+			if name == attribute.Attribute {
+				elem.StatusCode = http.StatusConflict
+				elem.StatusMsg = "No current value to set it back to, still pending"
+				httpCode = http.StatusMultiStatus
+				break
+			}
+		}
+		rsp.Attributes = append(rsp.Attributes, elem)
+	}
+	return
+}
+
+// /v1/bmc/bios/{xname} GET
+
+func doBiosPendingGet(w http.ResponseWriter, r *http.Request) {
+	title := "Get Pending BIOS Settings"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	bios, err, httpCode := getBios(r)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+	rspData := BiosPending{Pending: getBiosPending(bios)}
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling pending BIOS data: %v", baerr)
+		sendErrorRsp(w, "Get Pending BIOS Settings Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/{xname} DELETE
+
+func doBiosPendingDelete(w http.ResponseWriter, r *http.Request) {
+	title := "Clear Pending BIOS Settings"
+	var options BiosApplyOptions
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	if r.ContentLength != 0 {
+		err := getReqData(title, r, &options)
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+			sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := options.validate()
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	bios, err, httpCode := getBios(r)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	// Deleting the future settings object discards everything staged. BMCs
+	// which don't support it get the pending attributes set back to their
+	// current values instead, which is also how an apply time is honored.
+	pending := getBiosPending(bios)
+	deleted := false
+	if len(pending) > 0 && options.ApplyTime == "" {
+		statusCode, err := deleteBiosFuture(bios)
+		if err != nil {
+			sendErrorRsp(w, title, err.Error(), r.URL.Path, statusCode)
+			return
+		}
+		deleted = statusCodeOK(statusCode)
+		if !deleted {
+			logger.Infof("Deleting the BIOS settings object of %s failed with %d, setting the pending attributes back instead",
+				bios.common.xname, statusCode)
+		}
+	}
+
+	var notCleared []string
+	if !deleted {
+		var request *biosPatchRequest
+		request, notCleared, err, httpCode = makeClearPendingRequest(bios)
+		if err == nil && request != nil {
+			err, httpCode = setBiosApplyTime(request, getBiosSettings(bios), options.ApplyTime)
+		}
+		if err != nil {
+			sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+			return
+		}
+
+		if request != nil {
+			err, httpCode = patchBiosRequest(bios.common, request)
+			if err != nil {
+				sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+				return
+			}
+		}
+	}
+
+	applyRsp, err, httpCode := applyBiosSettings(bios.common, options)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+	if !applyRsp.Reset {
+		// Only the attributes which could not be cleared still change on
+		// the next reset
+		applyRsp.ResetPending = len(notCleared) > 0
+	}
+
+	rsp, httpCode := makeBiosPendingClearRsp(pending, notCleared)
+	rsp.BiosApplyRsp = &applyRsp
+	ba, baerr := json.Marshal(rsp)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS clear data: %v", baerr)
+		sendErrorRsp(w, title, emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(httpCode)
+	w.Write(ba)
+}
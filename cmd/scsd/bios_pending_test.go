@@ -0,0 +1,131 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetBiosPending(t *testing.T) {
+	bios := &Bios{
+		common: &BiosCommon{manufacturerType: cray},
+		cray: &BiosCray{
+			current: &rfBiosCray{Attributes: map[string]rfBiosAttributeCray{
+				"TPM Control": {CurrentValue: "Enabled"},
+				"SMT Control": {CurrentValue: "Enabled"},
+			}},
+			future: &rfBiosSDCray{Attributes: map[string]interface{}{
+				"TPM Control": "Disabled",
+				"SMT Control": "Enabled",
+			}},
+			futureUri: "/redfish/v1/Systems/Node0/Bios/SD",
+		},
+	}
+
+	pending := getBiosPending(bios)
+	if len(pending) != 1 || pending[0].Attribute != "TPM Control" ||
+		pending[0].Current != "Enabled" || pending[0].Future != "Disabled" {
+		t.Errorf("Expected only the TPM Control change, got %v", pending)
+	}
+
+	request, notCleared, err, _ := makeClearPendingRequest(bios)
+	if err != nil || request == nil || len(notCleared) != 0 {
+		t.Fatalf("Expected a clear request, got %v, not cleared %v, err: %v", request, notCleared, err)
+	}
+	if request.uri != bios.cray.futureUri || request.etag != "*" {
+		t.Errorf("Unexpected uri or etag, got %s %s", request.uri, request.etag)
+	}
+	var body struct {
+		Attributes map[string]interface{}
+	}
+	if err := json.Unmarshal(request.body, &body); err != nil {
+		t.Fatalf("Bad request body %s: %v", request.body, err)
+	}
+	if len(body.Attributes) != 1 || body.Attributes["TPM Control"] != "Enabled" {
+		t.Errorf("Expected TPM Control set back to Enabled, got %s", request.body)
+	}
+
+	bios.cray.future.Attributes["TPM Control"] = "Enabled"
+	request, notCleared, err, _ = makeClearPendingRequest(bios)
+	if err != nil || request != nil || len(notCleared) != 0 {
+		t.Errorf("Expected no request when nothing is pending, got %v, err: %v", request, err)
+	}
+
+	// An attribute with no current value can't be set back
+	bios.cray.future.Attributes["New Option"] = "Enabled"
+	request, notCleared, err, _ = makeClearPendingRequest(bios)
+	if err != nil || request != nil || len(notCleared) != 1 || notCleared[0] != "New Option" {
+		t.Errorf("Expected New Option not cleared and no request, got %v %v, err: %v",
+			request, notCleared, err)
+	}
+}
+
+func TestMakeBiosPendingClearRsp(t *testing.T) {
+	pending := []BiosPendingAttribute{
+		{Attribute: "New Option", Future: "Enabled"},
+		{Attribute: "TPM Control", Current: "Enabled", Future: "Disabled"},
+	}
+
+	rsp, httpCode := makeBiosPendingClearRsp(pending, nil)
+	if httpCode != http.StatusOK || len(rsp.Attributes) != 2 ||
+		rsp.Attributes[0].StatusCode != http.StatusOK || rsp.Attributes[1].StatusCode != http.StatusOK {
+		t.Errorf("Expected all cleared with 200, got %d %+v", httpCode, rsp.Attributes)
+	}
+
+	rsp, httpCode = makeBiosPendingClearRsp(pending, []string{"New Option"})
+	if httpCode != http.StatusMultiStatus || len(rsp.Attributes) != 2 ||
+		rsp.Attributes[0].StatusCode != http.StatusConflict || rsp.Attributes[1].StatusCode != http.StatusOK {
+		t.Errorf("Expected New Option not cleared with 207, got %d %+v", httpCode, rsp.Attributes)
+	}
+
+	rsp, httpCode = makeBiosPendingClearRsp(nil, nil)
+	if httpCode != http.StatusOK || rsp.Attributes == nil || len(rsp.Attributes) != 0 {
+		t.Errorf("Expected an empty list with 200, got %d %+v", httpCode, rsp.Attributes)
+	}
+}
+
+func TestGetBiosPendingGigabyte(t *testing.T) {
+	bios := &Bios{
+		common: &BiosCommon{manufacturerType: gigabyte},
+		gigabyte: &BiosGigabyte{
+			current: &rfBiosGigabyte{Attributes: map[string]interface{}{"TCG001": "Enabled"}},
+			future:  &rfBiosSDGigabyte{Attributes: map[string]interface{}{"TCG001": "Disabled"}},
+			biosAttributes: &rfBiosAttributesRegistry{RegistryEntries: rfRegistryEntry{
+				Attributes: []rfRegistryAttribute{{AttributeName: "TCG001", DisplayName: "TPM State"}},
+			}},
+		},
+	}
+
+	pending := getBiosPending(bios)
+	if len(pending) != 1 || pending[0].DisplayName != "TPM State" {
+		t.Errorf("Expected the TCG001 change with its display name, got %v", pending)
+	}
+
+	bios.gigabyte.future.Attributes = nil
+	pending = getBiosPending(bios)
+	if pending == nil || len(pending) != 0 {
+		t.Errorf("Expected an empty list without future settings, got %v", pending)
+	}
+}
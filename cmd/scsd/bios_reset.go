@@ -0,0 +1,316 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/gorilla/mux"
+)
+
+/*
+Example of the ResetBios action in /redfish/v1/Systems/Self/Bios
+AMI based BMCs require one of the advertised ResetType values.
+
+	{
+	  "Actions": {
+	    "#Bios.ResetBios": {
+	      "ResetType@Redfish.AllowableValues": [
+	        "Reset"
+	      ],
+	      "target": "/redfish/v1/Systems/Self/Bios/Actions/Bios.ResetBios"
+	    }
+	  },
+	  ...
+	}
+*/
+type rfBiosActionsResource struct {
+	Actions rfBiosActions `json:"Actions"`
+}
+
+type rfBiosActions struct {
+	ResetBios      rfBiosResetAction          `json:"#Bios.ResetBios"`
+	ChangePassword rfBiosChangePasswordAction `json:"#Bios.ChangePassword"`
+}
+
+type rfBiosResetAction struct {
+	Target     string   `json:"target"`
+	ResetTypes []string `json:"ResetType@Redfish.AllowableValues"`
+}
+
+// Return of /v1/bmc/bios/{xname}/reset POST.  The BiosApplyRsp fields are
+// only returned when BiosApplyOptions are given.
+
+type BiosResetRsp struct {
+	RebootRequired bool `json:"RebootRequired"`
+	*BiosApplyRsp
+}
+
+// Used by /v1/bmc/bios/reset POST to reset the BIOS of many nodes
+
+type biosResetPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+	BiosApplyOptions
+}
+
+// Return of /v1/bmc/bios/reset POST
+
+type biosResetRspElem struct {
+	Xname          string `json:"Xname"`
+	StatusCode     int    `json:"StatusCode"`
+	StatusMsg      string `json:"StatusMsg"`
+	RebootRequired bool   `json:"RebootRequired"`
+}
+
+type biosResetRsp struct {
+	Targets []biosResetRspElem `json:"Targets"`
+}
+
+// Returns the Bios.ResetBios action request. The action is assumed to be at
+// the standard location when the BMC does not advertise it.
+func makeResetBiosRequest(biosCommon *BiosCommon, actions *rfBiosActionsResource) *biosPatchRequest {
+	action := actions.Actions.ResetBios
+	uri := action.Target
+	if uri == "" {
+		uri = biosCommon.biosUri + "/Actions/Bios.ResetBios"
+	}
+
+	body := "{}"
+	if len(action.ResetTypes) > 0 {
+		resetType := action.ResetTypes[0]
+		for _, rt := range action.ResetTypes {
+			if rt == "Reset" {
+				resetType = rt
+				break
+			}
+		}
+		body = "{\"ResetType\":\"" + resetType + "\"}"
+	}
+
+	return &biosPatchRequest{
+		uri:  uri,
+		body: []byte(body),
+	}
+}
+
+func resetBios(r *http.Request, options BiosApplyOptions) (rsp BiosResetRsp, err error, httpCode int) {
+	xname, err, httpCode := validateXname(mux.Vars(r)["xname"])
+	if err != nil {
+		return
+	}
+
+	biosCommon, err, httpCode := getBiosCommon(xname)
+	if err != nil {
+		return
+	}
+
+	// ---- /redfish/v1/Systems/{system}/Bios ----
+
+	var actions rfBiosActionsResource
+	err, httpCode = getRedfishAndParseResponse(
+		"Systems/*/Bios", biosCommon.bmcXname, biosCommon.targets, biosCommon.biosUri, &actions)
+	if err != nil {
+		return
+	}
+
+	// ---- /redfish/v1/Systems/{system}/Bios/Actions/Bios.ResetBios ----
+
+	request := makeResetBiosRequest(biosCommon, &actions)
+	err, httpCode = postBiosRequest(biosCommon, request)
+	if err != nil {
+		return
+	}
+
+	// The BIOS applies the defaults the next time the node boots
+
+	applyRsp, err, httpCode := applyBiosSettings(biosCommon, options)
+	if err != nil {
+		return
+	}
+	rsp.RebootRequired = applyRsp.ResetPending
+	if options.isSet() {
+		rsp.BiosApplyRsp = &applyRsp
+	}
+	return
+}
+
+// Bulk version of resetBios()
+
+func resetBiosBulk(nodes []*biosBulkNode, options BiosApplyOptions) map[*biosBulkNode]bool {
+	getBiosBulkCommon(nodes)
+
+	// ---- /redfish/v1/Systems/{system}/Bios ----
+
+	requests := make(map[*biosBulkNode]*biosPatchRequest)
+	getBiosBulkResource("Systems/*/Bios", nodes, false,
+		func(node *biosBulkNode) string { return node.bios.common.biosUri },
+		func(node *biosBulkNode) interface{} { return &rfBiosActionsResource{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				biosBulkNotFound(node, node.bios.common.biosUri)
+				return
+			}
+			requests[node] = makeResetBiosRequest(node.bios.common, data.(*rfBiosActionsResource))
+		})
+
+	// ---- /redfish/v1/Systems/{system}/Bios/Actions/Bios.ResetBios ----
+
+	var reqs []biosBulkRequest
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		reqs = append(reqs, biosBulkRequest{
+			node: node,
+			uri:  requests[node].uri,
+			body: requests[node].body,
+		})
+	}
+
+	tasks, err := doBiosBulkRequests(http.MethodPost, reqs)
+	if err != nil {
+		for _, req := range reqs {
+			req.node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Reset BIOS call %s failed: %v", req.uri, err))
+		}
+		return nil
+	}
+
+	for ii := range tasks {
+		statusCode := getStatusCode(&tasks[ii])
+		if !statusCodeOK(statusCode) {
+			reqs[ii].node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Redfish reset BIOS failed %s %d", reqs[ii].uri, statusCode))
+		}
+	}
+	return applyBiosSettingsBulk(nodes, options)
+}
+
+// /v1/bmc/bios/{xname}/reset POST
+
+func doBiosResetPost(w http.ResponseWriter, r *http.Request) {
+	title := "Reset BIOS"
+	var options BiosApplyOptions
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	if r.ContentLength != 0 {
+		err := getReqData(title, r, &options)
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+			sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := options.validateAction()
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	rsp, err, httpCode := resetBios(r, options)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	ba, baerr := json.Marshal(rsp)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS reset data: %v", baerr)
+		sendErrorRsp(w, "Reset BIOS Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/reset POST
+
+func doBiosResetBulkPost(w http.ResponseWriter, r *http.Request) {
+	title := "Reset BIOS"
+	var jdata biosResetPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	err = jdata.BiosApplyOptions.validateAction()
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	rebootRequired := resetBiosBulk(nodes, jdata.BiosApplyOptions)
+
+	var rspData biosResetRsp
+	for _, node := range nodes {
+		elem := biosResetRspElem{
+			Xname:      node.bios.common.xname,
+			StatusCode: node.statusCode,
+			StatusMsg:  statusMsg(node.statusCode),
+		}
+		if !node.ok() {
+			elem.StatusMsg = node.err.Error()
+		} else {
+			elem.RebootRequired = rebootRequired[node]
+		}
+		rspData.Targets = append(rspData.Targets, elem)
+	}
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling BIOS reset data: %v", baerr)
+		sendErrorRsp(w, "Reset BIOS Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
@@ -0,0 +1,70 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMakeResetBiosRequest(t *testing.T) {
+	biosCommon := &BiosCommon{biosUri: "/redfish/v1/Systems/1/Bios"}
+
+	var actions rfBiosActionsResource
+	request := makeResetBiosRequest(biosCommon, &actions)
+	if request.uri != "/redfish/v1/Systems/1/Bios/Actions/Bios.ResetBios" || string(request.body) != "{}" {
+		t.Errorf("Expected the standard action without a body, got %s %s", request.uri, request.body)
+	}
+
+	data := `{"Actions":{"#Bios.ResetBios":{
+		"ResetType@Redfish.AllowableValues":["Default","Reset"],
+		"target":"/redfish/v1/Systems/Self/Bios/Actions/Bios.ResetBios"}}}`
+	if err := json.Unmarshal([]byte(data), &actions); err != nil {
+		t.Fatal(err)
+	}
+	request = makeResetBiosRequest(biosCommon, &actions)
+	if request.uri != "/redfish/v1/Systems/Self/Bios/Actions/Bios.ResetBios" {
+		t.Errorf("Expected the advertised target, got %s", request.uri)
+	}
+	if string(request.body) != `{"ResetType":"Reset"}` {
+		t.Errorf("Expected the Reset ResetType, got %s", request.body)
+	}
+
+	actions.Actions.ResetBios.ResetTypes = []string{"Default"}
+	request = makeResetBiosRequest(biosCommon, &actions)
+	if string(request.body) != `{"ResetType":"Default"}` {
+		t.Errorf("Expected the only advertised ResetType, got %s", request.body)
+	}
+}
+
+func TestBiosResetRsp(t *testing.T) {
+	ba, _ := json.Marshal(BiosResetRsp{RebootRequired: true})
+	if string(ba) != `{"RebootRequired":true}` {
+		t.Errorf("Expected only RebootRequired without apply options, got %s", ba)
+	}
+
+	ba, _ = json.Marshal(BiosResetRsp{BiosApplyRsp: &BiosApplyRsp{Reset: true}})
+	if string(ba) != `{"RebootRequired":false,"ResetPending":false,"Reset":true}` {
+		t.Errorf("Expected the apply fields with apply options, got %s", ba)
+	}
+}
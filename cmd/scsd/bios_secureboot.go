@@ -0,0 +1,403 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/gorilla/mux"
+)
+
+/*
+Example for rfSecureBoot from /redfish/v1/Systems/1/SecureBoot
+
+	{
+	  "@odata.etag": "W/\"1652393956\"",
+	  "@odata.id": "/redfish/v1/Systems/1/SecureBoot",
+	  "SecureBootCurrentBoot": "Enabled",
+	  "SecureBootEnable": true,
+	  "SecureBootMode": "UserMode"
+	}
+*/
+type rfSecureBoot struct {
+	ETag                  string            `json:"@odata.etag"`
+	SecureBootEnable      *bool             `json:"SecureBootEnable"`
+	SecureBootCurrentBoot string            `json:"SecureBootCurrentBoot"`
+	SecureBootMode        string            `json:"SecureBootMode"`
+	Settings              rfRedfishSettings `json:"@Redfish.Settings"`
+}
+
+type BiosSecureBoot struct {
+	SecureBootEnable      bool   `json:"SecureBootEnable"`
+	SecureBootCurrentBoot string `json:"SecureBootCurrentBoot"`
+	SecureBootMode        string `json:"SecureBootMode"`
+}
+
+type BiosSecureBootPatch struct {
+	SecureBootEnable *bool `json:"SecureBootEnable"`
+	BiosApplyOptions
+}
+
+// Used by /v1/bmc/bios/dump/secureboot POST to fetch Secure Boot of many nodes
+
+type biosSecureBootDumpPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+}
+
+// Used by /v1/bmc/bios/load/secureboot POST to set Secure Boot of many nodes
+
+type biosSecureBootLoadPost struct {
+	Force            bool     `json:"Force"`
+	Targets          []string `json:"Targets"`
+	SecureBootEnable *bool    `json:"SecureBootEnable"`
+	BiosApplyOptions
+}
+
+// Return of /v1/bmc/bios/dump/secureboot and /v1/bmc/bios/load/secureboot POST
+
+type biosSecureBootRspElem struct {
+	Xname                 string `json:"Xname"`
+	StatusCode            int    `json:"StatusCode"`
+	StatusMsg             string `json:"StatusMsg"`
+	SecureBootEnable      *bool  `json:"SecureBootEnable,omitempty"`
+	SecureBootCurrentBoot string `json:"SecureBootCurrentBoot,omitempty"`
+	SecureBootMode        string `json:"SecureBootMode,omitempty"`
+	ResetPending          *bool  `json:"ResetPending,omitempty"` // load only
+}
+
+type biosSecureBootRsp struct {
+	Targets []biosSecureBootRspElem `json:"Targets"`
+}
+
+// Returns the uri of the system's SecureBoot resource. The standard location
+// is used when the system does not link to it.
+func getSecureBootUri(biosCommon *BiosCommon) string {
+	if biosCommon.system != nil && biosCommon.system.SecureBoot.ID != "" {
+		return biosCommon.system.SecureBoot.ID
+	}
+	return biosCommon.systemUri + "/SecureBoot"
+}
+
+func toBiosSecureBoot(secureBoot *rfSecureBoot) BiosSecureBoot {
+	state := BiosSecureBoot{
+		SecureBootCurrentBoot: secureBoot.SecureBootCurrentBoot,
+		SecureBootMode:        secureBoot.SecureBootMode,
+	}
+	if secureBoot.SecureBootEnable != nil {
+		state.SecureBootEnable = *secureBoot.SecureBootEnable
+	}
+	return state
+}
+
+// Returns the Secure Boot patch with the apply time, which must be one the
+// BMC advertises in the @Redfish.Settings of the SecureBoot resource
+func makeSecureBootPatchRequest(biosCommon *BiosCommon, secureBoot *rfSecureBoot, enable bool, applyTime string) (request *biosPatchRequest, err error, httpCode int) {
+	request = &biosPatchRequest{
+		uri:  getSecureBootUri(biosCommon),
+		body: []byte(fmt.Sprintf("{\"SecureBootEnable\":%t}", enable)),
+		etag: secureBoot.ETag,
+	}
+	err, httpCode = setBiosApplyTime(request, &secureBoot.Settings, applyTime)
+	return
+}
+
+func getSecureBoot(r *http.Request) (biosCommon *BiosCommon, secureBoot *rfSecureBoot, err error, httpCode int) {
+	xname, err, httpCode := validateXname(mux.Vars(r)["xname"])
+	if err != nil {
+		return
+	}
+
+	biosCommon, err, httpCode = getBiosCommon(xname)
+	if err != nil {
+		return
+	}
+
+	// ---- /redfish/v1/Systems/{system}/SecureBoot ----
+
+	secureBoot = &rfSecureBoot{}
+	err, httpCode = getRedfishAndParseResponse(
+		"Systems/*/SecureBoot", biosCommon.bmcXname, biosCommon.targets, getSecureBootUri(biosCommon), secureBoot)
+	return
+}
+
+// Bulk version of getSecureBoot()
+
+func getSecureBootBulk(nodes []*biosBulkNode) map[*biosBulkNode]*rfSecureBoot {
+	secureBoots := make(map[*biosBulkNode]*rfSecureBoot)
+
+	getBiosBulkCommon(nodes)
+
+	// ---- /redfish/v1/Systems/{system}/SecureBoot ----
+
+	getBiosBulkResource("Systems/*/SecureBoot", nodes, false,
+		func(node *biosBulkNode) string { return getSecureBootUri(node.bios.common) },
+		func(node *biosBulkNode) interface{} { return &rfSecureBoot{} },
+		func(node *biosBulkNode, data interface{}, found bool) {
+			if !found {
+				node.fail(http.StatusMethodNotAllowed,
+					fmt.Errorf("Secure Boot not supported by the BMC of %s", node.bios.common.xname))
+				return
+			}
+			secureBoots[node] = data.(*rfSecureBoot)
+		})
+
+	return secureBoots
+}
+
+// Bulk version of the Secure Boot patch.  Also returns whether a reset is
+// pending for each node whose Secure Boot was patched.
+
+func patchSecureBootBulk(nodes []*biosBulkNode, enable bool, options BiosApplyOptions) (map[*biosBulkNode]*rfSecureBoot, map[*biosBulkNode]bool) {
+	secureBoots := getSecureBootBulk(nodes)
+
+	var reqs []biosBulkRequest
+	for _, node := range nodes {
+		if !node.ok() {
+			continue
+		}
+		request, err, httpCode := makeSecureBootPatchRequest(node.bios.common, secureBoots[node], enable, options.ApplyTime)
+		if err != nil {
+			node.fail(httpCode, err)
+			continue
+		}
+		reqs = append(reqs, biosBulkRequest{
+			node: node,
+			uri:  request.uri,
+			body: request.body,
+			etag: request.etag,
+		})
+	}
+
+	tasks, err := doBiosBulkRequests(http.MethodPatch, reqs)
+	if err != nil {
+		for _, req := range reqs {
+			req.node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Patch call %s failed: %v", req.uri, err))
+		}
+		return secureBoots, nil
+	}
+
+	for ii := range tasks {
+		statusCode := getStatusCode(&tasks[ii])
+		if !statusCodeOK(statusCode) {
+			reqs[ii].node.fail(http.StatusInternalServerError,
+				fmt.Errorf("ERROR: Redfish patch failed %s %d", reqs[ii].uri, statusCode))
+			continue
+		}
+		secureBoots[reqs[ii].node].SecureBootEnable = &enable
+	}
+	return secureBoots, applyBiosSettingsBulk(nodes, options)
+}
+
+func sendBiosSecureBootRsp(w http.ResponseWriter, r *http.Request, nodes []*biosBulkNode, secureBoots map[*biosBulkNode]*rfSecureBoot, resetPending map[*biosBulkNode]bool) {
+	var rspData biosSecureBootRsp
+	for _, node := range nodes {
+		elem := biosSecureBootRspElem{
+			Xname:      node.bios.common.xname,
+			StatusCode: node.statusCode,
+			StatusMsg:  statusMsg(node.statusCode),
+		}
+		if !node.ok() {
+			elem.StatusMsg = node.err.Error()
+		} else if secureBoot, ok := secureBoots[node]; ok {
+			state := toBiosSecureBoot(secureBoot)
+			elem.SecureBootEnable = &state.SecureBootEnable
+			elem.SecureBootCurrentBoot = state.SecureBootCurrentBoot
+			elem.SecureBootMode = state.SecureBootMode
+		}
+		if pending, ok := resetPending[node]; ok && node.ok() {
+			elem.ResetPending = &pending
+		}
+		rspData.Targets = append(rspData.Targets, elem)
+	}
+
+	ba, baerr := json.Marshal(rspData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling Secure Boot data: %v", baerr)
+		sendErrorRsp(w, "Secure Boot Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/{xname}/secureboot GET
+
+func doBiosSecureBootGet(w http.ResponseWriter, r *http.Request) {
+	title := "Get Secure Boot"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	_, secureBoot, err, httpCode := getSecureBoot(r)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	ba, baerr := json.Marshal(toBiosSecureBoot(secureBoot))
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling Secure Boot data: %v", baerr)
+		sendErrorRsp(w, "Get Secure Boot Error", emsg, r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/bios/{xname}/secureboot PATCH
+
+func doBiosSecureBootPatch(w http.ResponseWriter, r *http.Request) {
+	title := "Patch Secure Boot"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	var requestBody BiosSecureBootPatch
+
+	err := getReqData(title, r, &requestBody)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if requestBody.SecureBootEnable == nil {
+		sendErrorRsp(w, "Bad request data", "ERROR: Missing SecureBootEnable", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	err = requestBody.BiosApplyOptions.validate()
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	biosCommon, secureBoot, err, httpCode := getSecureBoot(r)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	request, err, httpCode := makeSecureBootPatchRequest(biosCommon, secureBoot,
+		*requestBody.SecureBootEnable, requestBody.ApplyTime)
+	if err == nil {
+		err, httpCode = patchBiosRequest(biosCommon, request)
+	}
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+
+	rsp, err, httpCode := applyBiosSettings(biosCommon, requestBody.BiosApplyOptions)
+	if err != nil {
+		sendErrorRsp(w, title, err.Error(), r.URL.Path, httpCode)
+		return
+	}
+	sendBiosApplyRsp(w, r, rsp)
+}
+
+// /v1/bmc/bios/dump/secureboot POST
+
+func doBiosSecureBootDumpPost(w http.ResponseWriter, r *http.Request) {
+	title := "Get Secure Boot"
+	var jdata biosSecureBootDumpPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	secureBoots := getSecureBootBulk(nodes)
+	sendBiosSecureBootRsp(w, r, nodes, secureBoots, nil)
+}
+
+// /v1/bmc/bios/load/secureboot POST
+
+func doBiosSecureBootLoadPost(w http.ResponseWriter, r *http.Request) {
+	title := "Patch Secure Boot"
+	var jdata biosSecureBootLoadPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(title, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request", "ERROR: No targets in request.", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	if jdata.SecureBootEnable == nil {
+		sendErrorRsp(w, "Bad request data", "ERROR: Missing SecureBootEnable", r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	err = jdata.BiosApplyOptions.validate()
+	if err != nil {
+		sendErrorRsp(w, "Bad request data", err.Error(), r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := getBiosBulkNodes(jdata.Targets, jdata.Force)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM verification failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	secureBoots, resetPending := patchSecureBootBulk(nodes, *jdata.SecureBootEnable, jdata.BiosApplyOptions)
+	sendBiosSecureBootRsp(w, r, nodes, secureBoots, resetPending)
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetSecureBootUri(t *testing.T) {
+	biosCommon := &BiosCommon{systemUri: "/redfish/v1/Systems/1", system: &rfSystem{}}
+	if uri := getSecureBootUri(biosCommon); uri != "/redfish/v1/Systems/1/SecureBoot" {
+		t.Errorf("Expected the standard SecureBoot uri, got %s", uri)
+	}
+
+	biosCommon.system.SecureBoot.ID = "/redfish/v1/Systems/Self/SecureBoot"
+	if uri := getSecureBootUri(biosCommon); uri != "/redfish/v1/Systems/Self/SecureBoot" {
+		t.Errorf("Expected the linked SecureBoot uri, got %s", uri)
+	}
+}
+
+func TestMakeSecureBootPatchRequest(t *testing.T) {
+	biosCommon := &BiosCommon{systemUri: "/redfish/v1/Systems/1", system: &rfSystem{}}
+	secureBoot := &rfSecureBoot{ETag: "W/\"2\""}
+
+	request, err, _ := makeSecureBootPatchRequest(biosCommon, secureBoot, true, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(request.body) != `{"SecureBootEnable":true}` {
+		t.Errorf("Unexpected body %s", string(request.body))
+	}
+	if request.etag != "W/\"2\"" || request.uri != "/redfish/v1/Systems/1/SecureBoot" {
+		t.Errorf("Unexpected request %s %s", request.uri, request.etag)
+	}
+
+	_, err, httpCode := makeSecureBootPatchRequest(biosCommon, secureBoot, true, ApplyTimeImmediate)
+	if err == nil || httpCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for an apply time the BMC does not advertise, got %d, err: %v", httpCode, err)
+	}
+
+	secureBoot.Settings.SupportedApplyTimes = []string{ApplyTimeImmediate}
+	request, err, _ = makeSecureBootPatchRequest(biosCommon, secureBoot, false, ApplyTimeImmediate)
+	if err != nil || string(request.body) != `{"@Redfish.SettingsApplyTime":{"ApplyTime":"Immediate"},"SecureBootEnable":false}` {
+		t.Errorf("Expected the apply time in the patch, got %s, err: %v", request.body, err)
+	}
+
+	state := toBiosSecureBoot(secureBoot)
+	if state.SecureBootEnable {
+		t.Errorf("Expected a missing SecureBootEnable to be reported as false")
+	}
+}
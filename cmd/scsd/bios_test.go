@@ -278,3 +278,58 @@ func TestToInt(t *testing.T) {
 		t.Errorf("Expected %v but instead got %d for the string '%v'", defaultValue, i, value)
 	}
 }
+
+func TestGetAttributeNamesIntel(t *testing.T) {
+	attributes := map[string]interface{}{
+		"Tpm2Operation": float64(0),
+		"OtherSetting":  "value",
+	}
+
+	names := getAttributeNamesIntel(TpmStateAttributeIntel, attributes)
+	if len(names) != 1 || names[0] != "Tpm2Operation" {
+		t.Errorf("Expected [Tpm2Operation] but instead got %v", names)
+	}
+
+	attributes["TpmOperation"] = float64(1)
+	names = getAttributeNamesIntel(TpmStateAttributeIntel, attributes)
+	if len(names) != 2 {
+		t.Errorf("Expected both TPM attributes but instead got %v", names)
+	}
+
+	names = getAttributeNamesIntel("OtherSetting", attributes)
+	if len(names) != 1 || names[0] != "OtherSetting" {
+		t.Errorf("Expected [OtherSetting] but instead got %v", names)
+	}
+
+	names = getAttributeNamesIntel("Missing", attributes)
+	if len(names) != 0 {
+		t.Errorf("Expected no attributes but instead got %v", names)
+	}
+}
+
+func TestToAttributeValueIntel(t *testing.T) {
+	value, err := toAttributeValueIntel(float64(0), EnabledIntel)
+	if err != nil || value != 1 {
+		t.Errorf("Expected int 1 but instead got %v (%T), err: %v", value, value, err)
+	}
+
+	value, err = toAttributeValueIntel(float64(0), "1")
+	if err != nil || value != 1 {
+		t.Errorf("Expected int 1 from a string but instead got %v (%T), err: %v", value, value, err)
+	}
+
+	value, err = toAttributeValueIntel("Disabled", "Enabled")
+	if err != nil || value != "Enabled" {
+		t.Errorf("Expected string Enabled but instead got %v (%T), err: %v", value, value, err)
+	}
+
+	value, err = toAttributeValueIntel(false, "true")
+	if err != nil || value != true {
+		t.Errorf("Expected bool true but instead got %v (%T), err: %v", value, value, err)
+	}
+
+	_, err = toAttributeValueIntel(float64(0), "Enabled")
+	if err == nil {
+		t.Errorf("Expected an error when setting a numeric attribute to a string")
+	}
+}
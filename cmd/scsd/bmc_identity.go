@@ -0,0 +1,269 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// BMC identity verification.  SCSD reaches BMCs by XName or hostname, so a
+// mis-wired DNS entry or a swapped IP would have it send BMC creds,
+// passwords, SSH keys or private keys to the wrong device.  When enabled,
+// the Redfish service UUID of each target is read from its service root
+// before any request is sent to it, and compared with the UUID HSM
+// recorded for that XName when it discovered the BMC.  The service root
+// doesn't need authentication, so no creds are sent to unverified targets.
+// Targets which don't match, or whose identity can't be checked, are
+// refused.
+//
+// Verified targets are not checked again for bmcIdentityCacheTime, so that
+// the several Redfish operations of an API call don't each verify them.
+
+var bmcIdentityCheck = false
+
+const bmcIdentityCacheTime = time.Minute
+
+type hsmRedfishEndpoint struct {
+	ID      string `json:"ID"`
+	Type    string `json:"Type"`
+	FQDN    string `json:"FQDN"`
+	UUID    string `json:"UUID"`
+	MACAddr string `json:"MACAddr"`
+}
+
+type hsmRedfishEndpointList struct {
+	RedfishEndpoints []hsmRedfishEndpoint `json:"RedfishEndpoints"`
+}
+
+var errBMCIdentity = errors.New("BMC identity not verified")
+
+// Max number of BMCs looked up per HSM Redfish endpoint query, to keep
+// query URLs short.
+
+var hsmRedfishEndpointBatch = 100
+
+var bmcIdentityLock sync.Mutex
+var bmcIdentityVerified = make(map[string]time.Time)
+
+// Get the HSM Redfish endpoints of a list of BMCs, in batches of at most
+// hsmRedfishEndpointBatch.
+//
+// xnames(in): BMC XNames.
+// Return:     Map of XName to Redfish endpoint; error if HSM can't be read.
+
+func getHSMRedfishEndpoints(xnames []string) (map[string]hsmRedfishEndpoint, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, xname := range xnames {
+		if !seen[xname] {
+			seen[xname] = true
+			ids = append(ids, xname)
+		}
+	}
+
+	eps := make(map[string]hsmRedfishEndpoint)
+	for start := 0; start < len(ids); start += hsmRedfishEndpointBatch {
+		end := start + hsmRedfishEndpointBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		var qparams []string
+		for _, id := range ids[start:end] {
+			qparams = append(qparams, "id="+url.QueryEscape(id))
+		}
+		rsp, err := doHSMGet(appParams.SmdURL + "/Inventory/RedfishEndpoints?" +
+			strings.Join(qparams, "&"))
+		if err != nil {
+			return nil, fmt.Errorf("Problem getting Redfish endpoints from HSM: %v", err)
+		}
+		var epData hsmRedfishEndpointList
+		err = json.Unmarshal(rsp, &epData)
+		if err != nil {
+			return nil, fmt.Errorf("Problem unmarshalling HSM Redfish endpoint data: %v", err)
+		}
+		for _, ep := range epData.RedfishEndpoints {
+			eps[xnametypes.NormalizeHMSCompID(ep.ID)] = ep
+		}
+	}
+	return eps, nil
+}
+
+// Read the Redfish service UUID of a target from its service root, without
+// creds.  The target's TLS cert is validated as it is for the Redfish
+// requests: against the CA bundle in strict mode, and against its pin, if
+// it has one, with TLS pinning.
+//
+// scheme(in): http or https.
+// host(in):   Target, with an optional :port.
+// Return:     Service UUID; error if it can't be read.
+
+func getServiceRootUUID(scheme string, host string) (string, error) {
+	rfClient := getRFIdentityClient()
+	if rfClient == nil {
+		return "", fmt.Errorf("No CA bundle loaded, %w in strict TLS mode",
+			errRFTLSRefused)
+	}
+	req, _ := http.NewRequestWithContext(withTLSPinTarg(context.Background(), host),
+		http.MethodGet, scheme+"://"+host+RFROOT_API, nil)
+	base.SetHTTPUserAgent(req, serviceName)
+	client := *rfClient
+	client.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	rsp, err := client.Do(req)
+	defer base.DrainAndCloseResponseBody(rsp)
+	if err != nil {
+		return "", fmt.Errorf("Can't read the Redfish service root of '%s': %v", host, err)
+	}
+	if !statusCodeOK(rsp.StatusCode) {
+		return "", fmt.Errorf("Can't read the Redfish service root of '%s': %s",
+			host, http.StatusText(rsp.StatusCode))
+	}
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Can't read the Redfish service root of '%s': %v", host, err)
+	}
+	var root rfServiceRoot
+	err = json.Unmarshal(body, &root)
+	if err != nil {
+		return "", fmt.Errorf("Can't unmarshal the Redfish service root of '%s': %v", host, err)
+	}
+	if root.UUID == "" {
+		return "", fmt.Errorf("The Redfish service root of '%s' has no UUID", host)
+	}
+	return root.UUID, nil
+}
+
+// Compare the Redfish service UUID of a target with the one in HSM.
+//
+// host(in):  Target, with an optional :port.
+// uuid(in):  Service UUID read from the target.
+// ep(in):    HSM Redfish endpoint of the target; nil if there is none.
+// Return:    Error if the identity doesn't match.
+
+func checkBMCIdentity(host string, uuid string, ep *hsmRedfishEndpoint) error {
+	if ep == nil {
+		return fmt.Errorf("%w: '%s' has no Redfish endpoint in HSM", errBMCIdentity, host)
+	}
+	if ep.UUID == "" {
+		return fmt.Errorf("%w: '%s' has no UUID in HSM", errBMCIdentity, host)
+	}
+	if !strings.EqualFold(uuid, ep.UUID) {
+		return fmt.Errorf("%w: '%s' has Redfish service UUID %s, HSM has %s for %s",
+			errBMCIdentity, host, uuid, ep.UUID, ep.ID)
+	}
+	return nil
+}
+
+// Verify the identity of the targets of a task list about to be launched.
+// Targets which don't match HSM, or can't be verified, are refused.  The
+// service roots are read on the shared BMC dial workers, once per target.
+//
+// taskList(in): Tasks about to be launched.
+// Return:       Indexes of the refused tasks.
+
+func bmcIdentityPreflight(taskList []trsapi.HttpTask) []int {
+	var refused []int
+	if !bmcIdentityCheck {
+		return refused
+	}
+
+	//Skip targets verified recently.  Each target is checked once however
+	//many tasks it has.
+
+	var hosts []string
+	var xnames []string
+	hostTasks := make(map[string][]int)
+	now := time.Now()
+	bmcIdentityLock.Lock()
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore || (taskList[ii].Request == nil) {
+			continue
+		}
+		host := taskList[ii].Request.URL.Host
+		if exp, ok := bmcIdentityVerified[host]; ok && now.Before(exp) {
+			continue
+		}
+		if _, ok := hostTasks[host]; !ok {
+			hosts = append(hosts, host)
+			xnames = append(xnames, xnametypes.NormalizeHMSCompID(stripPort(host)))
+		}
+		hostTasks[host] = append(hostTasks[host], ii)
+	}
+	bmcIdentityLock.Unlock()
+	if len(hosts) == 0 {
+		return refused
+	}
+
+	eps, err := getHSMRedfishEndpoints(xnames)
+	if err != nil {
+		for _, host := range hosts {
+			for _, ii := range hostTasks[host] {
+				refuseTask(&taskList[ii], fmt.Errorf("%w, %w: %v",
+					errBMCIdentity, errRFTLSRefused, err))
+				refused = append(refused, ii)
+			}
+		}
+		return refused
+	}
+
+	errs := make([]error, len(hosts))
+	runBMCDials(len(hosts), func(jj int) {
+		tURL := taskList[hostTasks[hosts[jj]][0]].Request.URL
+		uuid, err := getServiceRootUUID(tURL.Scheme, tURL.Host)
+		if err != nil {
+			errs[jj] = fmt.Errorf("%w: %v", errBMCIdentity, err)
+			return
+		}
+		var epp *hsmRedfishEndpoint
+		if ep, ok := eps[xnames[jj]]; ok {
+			epp = &ep
+		}
+		errs[jj] = checkBMCIdentity(tURL.Host, uuid, epp)
+	})
+
+	bmcIdentityLock.Lock()
+	defer bmcIdentityLock.Unlock()
+	for jj, host := range hosts {
+		if errs[jj] == nil {
+			bmcIdentityVerified[host] = time.Now().Add(bmcIdentityCacheTime)
+			continue
+		}
+		for _, ii := range hostTasks[host] {
+			refuseTask(&taskList[ii], fmt.Errorf("%v; %w", errs[jj], errRFTLSRefused))
+			refused = append(refused, ii)
+		}
+	}
+	return refused
+}
@@ -0,0 +1,408 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+func TestCheckBMCIdentity(t *testing.T) {
+	ep := hsmRedfishEndpoint{ID: "x0c0s0b0", UUID: "d4c6d22f-6983-42d8-8e6e-e1fd6d675c10"}
+	noUUID := hsmRedfishEndpoint{ID: "x0c0s0b0"}
+
+	tests := []struct {
+		name string
+		uuid string
+		ep   *hsmRedfishEndpoint
+		ok   bool
+	}{
+		{"match", ep.UUID, &ep, true},
+		{"match, other case", strings.ToUpper(ep.UUID), &ep, true},
+		{"mismatch", "d4c6d22f-6983-42d8-8e6e-e1fd6d675c11", &ep, false},
+		{"no endpoint", ep.UUID, nil, false},
+		{"no HSM UUID", ep.UUID, &noUUID, false},
+	}
+
+	for _, tt := range tests {
+		err := checkBMCIdentity("x0c0s0b0", tt.uuid, tt.ep)
+		if tt.ok && (err != nil) {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.ok && (err == nil) {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestBMCIdentityPreflight(t *testing.T) {
+	loggerSetup()
+	savedCheck, savedURL := bmcIdentityCheck, appParams.SmdURL
+	savedProto, savedVault := dfltProtocol, appParams.VaultEnable
+	defer func() {
+		bmcIdentityCheck, appParams.SmdURL = savedCheck, savedURL
+		dfltProtocol, appParams.VaultEnable = savedProto, savedVault
+		bmcIdentityVerified = make(map[string]time.Time)
+	}()
+
+	const uuid = "d4c6d22f-6983-42d8-8e6e-e1fd6d675c10"
+	var hsmUUID, bmcUUID string
+	var hsmMissing, hsmFail bool
+	var nReqs int32
+
+	hsm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hsmFail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if (r.URL.Path != "/Inventory/RedfishEndpoints") || (r.URL.Query().Get("id") != "127.0.0.1") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(CT_TYPE, CT_APPJSON)
+		if hsmMissing {
+			w.Write([]byte(`{"RedfishEndpoints":[]}`))
+			return
+		}
+		fmt.Fprintf(w, `{"RedfishEndpoints":[{"ID":"127.0.0.1","Type":"NodeBMC","UUID":"%s"}]}`,
+			hsmUUID)
+	}))
+	defer hsm.Close()
+	bmc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == RFROOT_API {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("Creds sent with the service root request")
+			}
+			fmt.Fprintf(w, `{"@odata.id":"/redfish/v1/","UUID":"%s"}`, bmcUUID)
+			return
+		}
+		atomic.AddInt32(&nReqs, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer bmc.Close()
+	targ := strings.TrimPrefix(bmc.URL, "http://")
+
+	bmcIdentityCheck = true
+	appParams.SmdURL = hsm.URL
+	appParams.VaultEnable = nil
+	dfltProtocol = "http"
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("TRS init failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hsmUUID    string
+		bmcUUID    string
+		hsmMissing bool
+		hsmFail    bool
+		keepCache  bool
+		sent       bool
+	}{
+		{"match", uuid, uuid, false, false, false, true},
+		{"mismatch, verified recently", "other", uuid, false, false, true, true},
+		{"mismatch", "other", uuid, false, false, false, false},
+		{"no HSM endpoint", uuid, uuid, true, false, false, false},
+		{"no BMC UUID", uuid, "", false, false, false, false},
+		{"HSM failure", uuid, uuid, false, true, false, false},
+	}
+
+	for _, tt := range tests {
+		hsmUUID, bmcUUID = tt.hsmUUID, tt.bmcUUID
+		hsmMissing, hsmFail = tt.hsmMissing, tt.hsmFail
+		if !tt.keepCache {
+			bmcIdentityVerified = make(map[string]time.Time)
+		}
+
+		var sourceTL trsapi.HttpTask
+		sourceTL.Timeout = 5 * time.Second
+		sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+		taskList := tloc.CreateTaskList(&sourceTL, 1)
+		populateTaskList(taskList, []string{targ}, RFMANAGERS_API, http.MethodGet, nil)
+
+		before := atomic.LoadInt32(&nReqs)
+		err := doOp(taskList)
+		if err != nil {
+			t.Fatalf("%s: doOp() failed: %v", tt.name, err)
+		}
+		sent := atomic.LoadInt32(&nReqs) != before
+		code := getStatusCode(&taskList[0])
+
+		if sent != tt.sent {
+			t.Errorf("%s: expected sent %t, got %t", tt.name, tt.sent, sent)
+		}
+		if !tt.sent {
+			if code != http.StatusServiceUnavailable {
+				t.Errorf("%s: expected status %d, got %d", tt.name,
+					http.StatusServiceUnavailable, code)
+			}
+			if msg := getStatusMsg(&taskList[0]); !strings.Contains(msg, "identity") {
+				t.Errorf("%s: unexpected status message '%s'", tt.name, msg)
+			}
+		}
+	}
+}
+
+func TestGetHSMRedfishEndpoints(t *testing.T) {
+	loggerSetup()
+	savedURL, savedBatch := appParams.SmdURL, hsmRedfishEndpointBatch
+	defer func() {
+		appParams.SmdURL, hsmRedfishEndpointBatch = savedURL, savedBatch
+	}()
+
+	var nQueries int32
+	hsm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nQueries, 1)
+		ids := r.URL.Query()["id"]
+		if len(ids) > hsmRedfishEndpointBatch {
+			t.Errorf("Expected at most %d IDs per query, got %d",
+				hsmRedfishEndpointBatch, len(ids))
+		}
+		var eps []string
+		for _, id := range ids {
+			eps = append(eps, fmt.Sprintf(`{"ID":"%s","UUID":"uuid-%s"}`, id, id))
+		}
+		w.Header().Set(CT_TYPE, CT_APPJSON)
+		fmt.Fprintf(w, `{"RedfishEndpoints":[%s]}`, strings.Join(eps, ","))
+	}))
+	defer hsm.Close()
+	appParams.SmdURL = hsm.URL
+	hsmRedfishEndpointBatch = 2
+
+	xnames := []string{"x0c0s0b0", "x0c0s1b0", "x0c0s2b0", "x0c0s1b0", "x0c0s3b0", "x0c0s4b0"}
+	eps, err := getHSMRedfishEndpoints(xnames)
+	if err != nil {
+		t.Fatalf("getHSMRedfishEndpoints() failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&nQueries); n != 3 {
+		t.Errorf("Expected 3 HSM queries, got %d", n)
+	}
+	if len(eps) != 5 {
+		t.Errorf("Expected 5 endpoints, got %d", len(eps))
+	}
+	for _, xname := range xnames {
+		if eps[xname].UUID != "uuid-"+xname {
+			t.Errorf("Unexpected endpoint for %s: %+v", xname, eps[xname])
+		}
+	}
+}
+
+// Several targets are verified in parallel, sharing the service root client,
+// each once however many tasks it has.
+
+func TestBMCIdentityPreflightMulti(t *testing.T) {
+	loggerSetup()
+	savedCheck, savedURL := bmcIdentityCheck, appParams.SmdURL
+	defer func() {
+		bmcIdentityCheck, appParams.SmdURL = savedCheck, savedURL
+		bmcIdentityVerified = make(map[string]time.Time)
+	}()
+
+	const uuid = "d4c6d22f-6983-42d8-8e6e-e1fd6d675c10"
+	hsm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(CT_TYPE, CT_APPJSON)
+		fmt.Fprintf(w, `{"RedfishEndpoints":[{"ID":"127.0.0.1","Type":"NodeBMC","UUID":"%s"}]}`,
+			uuid)
+	}))
+	defer hsm.Close()
+
+	var targs []string
+	nRoots := make([]int32, 6)
+	for ii := 0; ii < 6; ii++ {
+		bmcUUID := uuid
+		if ii == 3 {
+			bmcUUID = "other"
+		}
+		nRoot := &nRoots[ii]
+		bmc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(nRoot, 1)
+			fmt.Fprintf(w, `{"@odata.id":"/redfish/v1/","UUID":"%s"}`, bmcUUID)
+		}))
+		defer bmc.Close()
+		targs = append(targs, strings.TrimPrefix(bmc.URL, "http://"))
+	}
+
+	bmcIdentityCheck = true
+	bmcIdentityVerified = make(map[string]time.Time)
+	appParams.SmdURL = hsm.URL
+	tloc = &tlocLocal
+
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = 5 * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, 2*len(targs))
+	for ii := range taskList {
+		taskList[ii].Request.URL.Scheme = "http"
+		taskList[ii].Request.URL.Host = targs[ii%len(targs)]
+		taskList[ii].Request.URL.Path = RFMANAGERS_API
+	}
+
+	refused := bmcIdentityPreflight(taskList)
+	sort.Ints(refused)
+	if (len(refused) != 2) || (refused[0] != 3) || (refused[1] != 3+len(targs)) {
+		t.Fatalf("Expected only the tasks of target 3 refused, got %v", refused)
+	}
+	for ii, targ := range targs {
+		_, ok := bmcIdentityVerified[targ]
+		if ok == (ii == 3) {
+			t.Errorf("%s: unexpected verified state %t", targ, ok)
+		}
+		if n := atomic.LoadInt32(&nRoots[ii]); n != 1 {
+			t.Errorf("%s: expected the service root read once, got %d", targ, n)
+		}
+	}
+}
+
+// The Redfish client lock isn't held while targets are verified.
+
+func TestBMCIdentityDoOpUnlocked(t *testing.T) {
+	loggerSetup()
+	savedCheck, savedURL := bmcIdentityCheck, appParams.SmdURL
+	savedProto, savedVault := dfltProtocol, appParams.VaultEnable
+	defer func() {
+		bmcIdentityCheck, appParams.SmdURL = savedCheck, savedURL
+		dfltProtocol, appParams.VaultEnable = savedProto, savedVault
+		bmcIdentityVerified = make(map[string]time.Time)
+	}()
+
+	const uuid = "d4c6d22f-6983-42d8-8e6e-e1fd6d675c10"
+	var lockHeld, nReqs int32
+	hsm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(CT_TYPE, CT_APPJSON)
+		fmt.Fprintf(w, `{"RedfishEndpoints":[{"ID":"127.0.0.1","Type":"NodeBMC","UUID":"%s"}]}`,
+			uuid)
+	}))
+	defer hsm.Close()
+	bmc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == RFROOT_API {
+			if rfClientLock.TryLock() {
+				rfClientLock.Unlock()
+			} else {
+				atomic.AddInt32(&lockHeld, 1)
+			}
+			fmt.Fprintf(w, `{"@odata.id":"/redfish/v1/","UUID":"%s"}`, uuid)
+			return
+		}
+		atomic.AddInt32(&nReqs, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer bmc.Close()
+
+	bmcIdentityCheck = true
+	bmcIdentityVerified = make(map[string]time.Time)
+	appParams.SmdURL = hsm.URL
+	appParams.VaultEnable = nil
+	dfltProtocol = "http"
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("TRS init failed: %v", err)
+	}
+
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = 5 * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, 1)
+	populateTaskList(taskList, []string{strings.TrimPrefix(bmc.URL, "http://")},
+		RFMANAGERS_API, http.MethodGet, nil)
+
+	err = doOp(taskList)
+	if err != nil {
+		t.Fatalf("doOp() failed: %v", err)
+	}
+	if atomic.LoadInt32(&nReqs) != 1 {
+		t.Errorf("Expected the request sent once, got %d", nReqs)
+	}
+	if atomic.LoadInt32(&lockHeld) != 0 {
+		t.Errorf("Redfish client lock held during identity verification")
+	}
+}
+
+func TestServiceRootUUIDTLS(t *testing.T) {
+	defer strictTLSSetup(t)()
+	defer tlsPinSetup(t)()
+	var nReads int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nReads, 1)
+		fmt.Fprintf(w, `{"@odata.id":"/redfish/v1/","UUID":"%s"}`, "1234")
+	}))
+	defer srv.Close()
+	targ := strings.TrimPrefix(srv.URL, "https://")
+	srvCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: srv.Certificate().Raw}))
+	_, otherCA, _ := makeTestCert(t, "Other CA", time.Hour)
+
+	tests := []struct {
+		name   string
+		strict bool
+		ca     string
+		pinned bool
+		pin    string
+		ok     bool
+	}{
+		{"permissive", false, "", false, "", true},
+		{"strict, no CA", true, "", false, "", false},
+		{"strict, wrong CA", true, otherCA, false, "", false},
+		{"strict, right CA", true, srvCA, false, "", true},
+		{"pinned, no pin", false, "", true, "", true},
+		{"pinned, right pin", false, "", true, certFingerprint(srv.Certificate()), true},
+		{"pinned, wrong pin", false, "", true, "0123", false},
+		{"strict and pinned, wrong CA", true, otherCA, true, "", false},
+	}
+
+	for _, tt := range tests {
+		strictTLS, tlsPinEnable = tt.strict, tt.pinned
+		if err := setRFTLSCA(tt.ca); err != nil {
+			t.Fatalf("%s: error setting CA bundle: %v", tt.name, err)
+		}
+		tlsPins = make(map[string]tlsPin)
+		if tt.pin != "" {
+			tlsPins[targ] = tlsPin{ID: targ, Fingerprint: tt.pin, Source: TLSPinFirstUse}
+		}
+
+		before := atomic.LoadInt32(&nReads)
+		uuid, err := getServiceRootUUID("https", targ)
+		read := atomic.LoadInt32(&nReads) != before
+		if tt.ok && ((err != nil) || (uuid != "1234")) {
+			t.Errorf("%s: expected UUID 1234, got '%s', error %v", tt.name, uuid, err)
+		}
+		if !tt.ok && ((err == nil) || read) {
+			t.Errorf("%s: expected refusal, read %t, error %v", tt.name, read, err)
+		}
+		if (tt.pin == "") && (len(tlsPins) != 0) {
+			t.Errorf("%s: identity check pinned the target: %+v", tt.name, tlsPins)
+		}
+	}
+	setRFTLSCA("")
+}
@@ -24,7 +24,6 @@ package main
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -1155,10 +1154,6 @@ func doHSMDiscover(xnames []string) ([]byte, error) {
 	if len(xnames) == 0 {
 		return nil, nil
 	}
-	if hsmClient == nil {
-		hsmTransport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
-		hsmClient = &http.Client{Transport: hsmTransport}
-	}
 	url := appParams.SmdURL + "/Inventory/Discover"
 
 	var payloadS discoverPayload
@@ -1169,27 +1164,5 @@ func doHSMDiscover(xnames []string) ([]byte, error) {
 		logger.Errorf("attemtped to marshal JSON payload but failed: %s", marErr)
 		return nil, marErr
 	}
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(payload))
-	base.SetHTTPUserAgent(req, serviceName)
-	req.Header.Add("Content-Type", "application/json")
-	rsp, err := hsmClient.Do(req)
-	defer base.DrainAndCloseResponseBody(rsp)
-	if err != nil {
-		logger.Errorf("Problem contacting state manager: %v", err)
-		return nil, err
-	}
-	rspPayload, plerr := ioutil.ReadAll(rsp.Body)
-	if plerr != nil {
-		logger.Errorf("Problem reading request body: %v", plerr)
-		return nil, plerr
-	}
-
-	if !statusCodeOK(rsp.StatusCode) {
-		emsg := fmt.Errorf("Bad return status from state manager: %d",
-			rsp.StatusCode)
-		logger.Println(emsg)
-		return nil, emsg
-	}
-
-	return rspPayload, nil
+	return getHSMRequester().Do(http.MethodPost, url, payload)
 }
@@ -36,6 +36,8 @@ type healthData struct {
 	TaskRunnerStatus string `json:"TaskRunnerStatus"`
 	TaskRunnerMode   string `json:"TaskRunnerMode"`
 	VaultStatus      string `json:"VaultStatus"`
+	RedfishTLSStatus string `json:"RedfishTLSStatus"`
+	RedfishTLSMode   string `json:"RedfishTLSMode"`
 }
 
 type versionData struct {
@@ -162,6 +164,11 @@ func doHealthGet(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Infof("Health: Vault status: %s",health.VaultStatus)
 
+	health.RedfishTLSStatus = redfishTLSStatus()
+	health.RedfishTLSMode = redfishTLSMode()
+	logger.Infof("Health: Redfish TLS status: %s, mode: %s",
+		health.RedfishTLSStatus,health.RedfishTLSMode)
+
 	ba,baerr := json.Marshal(health)
 	if (baerr != nil) {
 		emsg := fmt.Sprintf("ERROR: Problem marshal health data: %v",baerr)
@@ -200,6 +207,14 @@ func doReadinessGet(w http.ResponseWriter, r *http.Request) {
 		ready = false
 	}
 
+	//In strict TLS mode no Redfish operation can be done until outbound
+	//Redfish TLS is validated.
+
+	if (strictTLS && (redfishTLSStatus() != RFTLSValidated)) {
+		logger.Infof("Readiness check: strict TLS mode, Redfish TLS not validated")
+		ready = false
+	}
+
 	if (ready) {
 		w.WriteHeader(http.StatusNoContent)
 	} else {
@@ -0,0 +1,249 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// HSM client.  All HSM calls go through an hsmRequester, so they can be
+// pointed at a local fake in tests.
+//
+// The default one validates HSM's cert with the CA bundle loaded for
+// Redfish, and picks up CA bundle updates along with the Redfish
+// transports.  Until a bundle is loaded, HSM's cert is not validated.  If
+// SCSD_HSM_CLIENT_CERT and SCSD_HSM_CLIENT_KEY are set, that cert is
+// presented to HSM (mTLS).  Idempotent requests which fail to get through,
+// or get a 5xx response, are retried SCSD_HSM_RETRIES times, waiting
+// SCSD_HSM_RETRY_BACKOFF milliseconds before the first retry and twice as
+// long before each one after.
+
+type hsmRequester interface {
+	// Sends a request to HSM and returns the response payload, or an
+	// error if it couldn't be sent or got a non-2xx status.
+	Do(method string, url string, pld []byte) ([]byte, error)
+}
+
+var hsmTimeout = 30 //seconds
+var hsmRetries = 3
+var hsmRetryBackoff = 500 //milliseconds
+var hsmClientCertFile = ""
+var hsmClientKeyFile = ""
+
+type hsmHTTPClient struct {
+	lock    sync.Mutex
+	client  *http.Client
+	cert    *tls.Certificate
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+var hsmLock sync.Mutex
+var hsmReq hsmRequester
+
+// Create an HSM client.
+//
+// caPool(in):  CA certs to validate HSM's cert with; nil means don't validate.
+// cert(in):    Client cert to present to HSM, or nil.
+// timeout(in): Per-request timeout.
+// retries(in): Number of retries of failed idempotent requests.
+// backoff(in): Wait before the first retry.
+// Return:      HSM client.
+
+func newHSMHTTPClient(caPool *x509.CertPool, cert *tls.Certificate,
+	timeout time.Duration, retries int, backoff time.Duration) *hsmHTTPClient {
+	c := &hsmHTTPClient{cert: cert, timeout: timeout, retries: retries,
+		backoff: backoff}
+	c.setCA(caPool)
+	return c
+}
+
+// Replace the CA certs HSM's cert is validated with.  Requests in flight
+// finish on the old transport.
+
+func (c *hsmHTTPClient) setCA(caPool *x509.CertPool) {
+	tcfg := &tls.Config{RootCAs: caPool, InsecureSkipVerify: (caPool == nil)}
+	if c.cert != nil {
+		tcfg.Certificates = []tls.Certificate{*c.cert}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tcfg},
+		Timeout: c.timeout}
+
+	c.lock.Lock()
+	old := c.client
+	c.client = client
+	c.lock.Unlock()
+
+	if old != nil {
+		old.CloseIdleConnections()
+	}
+}
+
+func (c *hsmHTTPClient) getClient() *http.Client {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.client
+}
+
+func hsmRetryable(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (c *hsmHTTPClient) Do(method string, url string, pld []byte) ([]byte, error) {
+	var err error
+	var rspPayload []byte
+	var status int
+
+	tries := 1
+	if hsmRetryable(method) {
+		tries += c.retries
+	}
+	wait := c.backoff
+
+	for try := 1; try <= tries; try++ {
+		if try > 1 {
+			logger.Warnf("Retrying %s %s in %s (attempt %d of %d).",
+				method, url, wait, try, tries)
+			time.Sleep(wait)
+			wait *= 2
+		}
+		rspPayload, status, err = c.send(method, url, pld)
+		if (err == nil) && (status < http.StatusInternalServerError) {
+			break
+		}
+	}
+
+	if err != nil {
+		logger.Errorf("Problem contacting state manager: %v", err)
+		return nil, err
+	}
+	if !statusCodeOK(status) {
+		emsg := fmt.Errorf("Bad return status from state manager: %d", status)
+		logger.Println(emsg)
+		return nil, emsg
+	}
+	return rspPayload, nil
+}
+
+func (c *hsmHTTPClient) send(method string, url string, pld []byte) ([]byte, int, error) {
+	var body *bytes.Reader
+	if pld == nil {
+		body = bytes.NewReader([]byte{})
+	} else {
+		body = bytes.NewReader(pld)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	base.SetHTTPUserAgent(req, serviceName)
+	if pld != nil {
+		req.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	rsp, err := c.getClient().Do(req)
+	defer base.DrainAndCloseResponseBody(rsp)
+	if err != nil {
+		return nil, 0, err
+	}
+	rspPayload, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Problem reading response body: %v", err)
+	}
+	return rspPayload, rsp.StatusCode, nil
+}
+
+// Returns the HSM requester, creating the default one with the current
+// settings if there isn't one yet.
+
+func getHSMRequester() hsmRequester {
+	hsmLock.Lock()
+	defer hsmLock.Unlock()
+	if hsmReq == nil {
+		hsmReq = newHSMHTTPClient(getRFTLSPool(), nil,
+			time.Duration(hsmTimeout)*time.Second, hsmRetries,
+			time.Duration(hsmRetryBackoff)*time.Millisecond)
+	}
+	return hsmReq
+}
+
+// Replace the HSM requester.  Returns the previous one.
+
+func setHSMRequester(req hsmRequester) hsmRequester {
+	hsmLock.Lock()
+	defer hsmLock.Unlock()
+	prev := hsmReq
+	hsmReq = req
+	return prev
+}
+
+// Create the default HSM requester from the SCSD_HSM_* settings.  If a
+// client cert is configured but can't be loaded, no requester is created
+// and an error is returned, since HSM may refuse requests without it.
+
+func setupHSMClient() error {
+	var cert *tls.Certificate
+
+	if hsmTimeout <= 0 {
+		logger.Errorf("Invalid HSM timeout %d, using 30 seconds.", hsmTimeout)
+		hsmTimeout = 30
+	}
+	if hsmRetries < 0 {
+		hsmRetries = 0
+	}
+	if (hsmClientCertFile != "") || (hsmClientKeyFile != "") {
+		kp, err := tls.LoadX509KeyPair(hsmClientCertFile, hsmClientKeyFile)
+		if err != nil {
+			return fmt.Errorf("Can't load HSM client cert '%s', key '%s': %v",
+				hsmClientCertFile, hsmClientKeyFile, err)
+		}
+		cert = &kp
+	}
+
+	setHSMRequester(newHSMHTTPClient(getRFTLSPool(), cert,
+		time.Duration(hsmTimeout)*time.Second, hsmRetries,
+		time.Duration(hsmRetryBackoff)*time.Millisecond))
+	return nil
+}
+
+// Update the CA certs the default HSM requester validates HSM with.
+
+func setHSMCA(caPool *x509.CertPool) {
+	if c, ok := getHSMRequester().(*hsmHTTPClient); ok {
+		c.setCA(caPool)
+	}
+}
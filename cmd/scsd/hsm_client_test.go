@@ -0,0 +1,264 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeHSMReq struct {
+	method string
+	url    string
+	pld    []byte
+}
+
+type fakeHSM struct {
+	reqs []fakeHSMReq
+	rsp  []byte
+}
+
+func (f *fakeHSM) Do(method string, url string, pld []byte) ([]byte, error) {
+	f.reqs = append(f.reqs, fakeHSMReq{method: method, url: url, pld: pld})
+	return f.rsp, nil
+}
+
+func TestHSMRequester(t *testing.T) {
+	fake := &fakeHSM{rsp: []byte(`{}`)}
+	prev := setHSMRequester(fake)
+	defer setHSMRequester(prev)
+	savedURL := appParams.SmdURL
+	defer func() { appParams.SmdURL = savedURL }()
+	appParams.SmdURL = "https://hsm/hsm/v2"
+
+	doHSMGet(appParams.SmdURL + "/groups")
+	doHSMPutPostPatchDel(appParams.SmdURL+"/Inventory/RedfishEndpoints", "post", []byte(`{}`))
+	doHSMDiscover([]string{"x0c0s0b0"})
+	doHSMDiscover([]string{})
+
+	if len(fake.reqs) != 3 {
+		t.Fatalf("Expected 3 HSM requests, got %d", len(fake.reqs))
+	}
+	exp := []fakeHSMReq{
+		{http.MethodGet, "https://hsm/hsm/v2/groups", nil},
+		{http.MethodPost, "https://hsm/hsm/v2/Inventory/RedfishEndpoints", []byte(`{}`)},
+		{http.MethodPost, "https://hsm/hsm/v2/Inventory/Discover", nil},
+	}
+	for ix, req := range fake.reqs {
+		if (req.method != exp[ix].method) || (req.url != exp[ix].url) {
+			t.Errorf("Request %d: expected %s %s, got %s %s", ix,
+				exp[ix].method, exp[ix].url, req.method, req.url)
+		}
+	}
+	var dpld discoverPayload
+	err := json.Unmarshal(fake.reqs[2].pld, &dpld)
+	if (err != nil) || (len(dpld.Xnames) != 1) || (dpld.Xnames[0] != "x0c0s0b0") || !dpld.Force {
+		t.Errorf("Unexpected discover payload '%s'", string(fake.reqs[2].pld))
+	}
+}
+
+func TestHSMClientRetry(t *testing.T) {
+	loggerSetup()
+	var nReqs, nFails int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nReqs, 1)
+		if atomic.AddInt32(&nFails, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	client := newHSMHTTPClient(nil, nil, 5*time.Second, 2, time.Millisecond)
+
+	tests := []struct {
+		name   string
+		method string
+		fails  int32
+		reqs   int32
+		ok     bool
+	}{
+		{"GET, no failures", http.MethodGet, 0, 1, true},
+		{"GET, 2 failures", http.MethodGet, 2, 3, true},
+		{"GET, 3 failures", http.MethodGet, 3, 3, false},
+		{"PUT, 1 failure", http.MethodPut, 1, 2, true},
+		{"POST, 1 failure", http.MethodPost, 1, 1, false},
+	}
+
+	for _, tt := range tests {
+		atomic.StoreInt32(&nReqs, 0)
+		atomic.StoreInt32(&nFails, tt.fails)
+		rsp, err := client.Do(tt.method, srv.URL, []byte(`{}`))
+		if tt.ok && ((err != nil) || (string(rsp) != `{"ok":true}`)) {
+			t.Errorf("%s: unexpected result '%s', %v", tt.name, string(rsp), err)
+		}
+		if !tt.ok && (err == nil) {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if n := atomic.LoadInt32(&nReqs); n != tt.reqs {
+			t.Errorf("%s: expected %d requests, got %d", tt.name, tt.reqs, n)
+		}
+	}
+
+	//Timeout
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer slow.Close()
+	client = newHSMHTTPClient(nil, nil, 100*time.Millisecond, 0, time.Millisecond)
+	_, err := client.Do(http.MethodGet, slow.URL, nil)
+	if err == nil {
+		t.Errorf("Expected a timeout error")
+	}
+}
+
+func TestHSMClientTLS(t *testing.T) {
+	loggerSetup()
+	_, certPEM, keyPEM := makeTestCert(t, "127.0.0.1", time.Hour)
+	clientCert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPool := x509.NewCertPool()
+	clientPool.AppendCertsFromPEM([]byte(certPEM))
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: clientPool}
+	srv.StartTLS()
+	defer srv.Close()
+
+	srvPool := x509.NewCertPool()
+	srvPool.AddCert(srv.Certificate())
+	_, otherPEM, _ := makeTestCert(t, "127.0.0.1", time.Hour)
+	otherPool := x509.NewCertPool()
+	otherPool.AppendCertsFromPEM([]byte(otherPEM))
+
+	//No CA: not validated
+
+	client := newHSMHTTPClient(nil, nil, 5*time.Second, 0, time.Millisecond)
+	_, err = client.Do(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Errorf("Unvalidated request failed: %v", err)
+	}
+
+	//Wrong CA, then the CA is updated
+
+	client = newHSMHTTPClient(otherPool, nil, 5*time.Second, 0, time.Millisecond)
+	_, err = client.Do(http.MethodGet, srv.URL, nil)
+	if err == nil {
+		t.Errorf("Expected a TLS validation error")
+	}
+	client.setCA(srvPool)
+	_, err = client.Do(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Errorf("Validated request failed: %v", err)
+	}
+
+	//mTLS
+
+	srv.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	srv.CloseClientConnections()
+	client = newHSMHTTPClient(srvPool, nil, 5*time.Second, 0, time.Millisecond)
+	_, err = client.Do(http.MethodGet, srv.URL, nil)
+	if err == nil {
+		t.Errorf("Expected a client cert error")
+	}
+	client = newHSMHTTPClient(srvPool, &clientCert, 5*time.Second, 0, time.Millisecond)
+	_, err = client.Do(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Errorf("mTLS request failed: %v", err)
+	}
+}
+
+// A client cert which can't be loaded fails the setup, leaving the
+// requester alone.
+
+func TestSetupHSMClient(t *testing.T) {
+	loggerSetup()
+	savedCert, savedKey := hsmClientCertFile, hsmClientKeyFile
+	prev := setHSMRequester(nil)
+	defer func() {
+		hsmClientCertFile, hsmClientKeyFile = savedCert, savedKey
+		setHSMRequester(prev)
+	}()
+
+	_, certPEM, keyPEM := makeTestCert(t, "scsd", time.Hour)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, []byte(certPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte(keyPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cert    string
+		key     string
+		fail    bool
+		hasCert bool
+	}{
+		{"no client cert", "", "", false, false},
+		{"client cert", certFile, keyFile, false, true},
+		{"missing key", certFile, "", true, false},
+		{"bad key file", certFile, filepath.Join(dir, "nokey"), true, false},
+	}
+
+	for _, tt := range tests {
+		setHSMRequester(nil)
+		hsmClientCertFile, hsmClientKeyFile = tt.cert, tt.key
+		err := setupHSMClient()
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			if setHSMRequester(nil) != nil {
+				t.Errorf("%s: requester created despite the error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		c, ok := getHSMRequester().(*hsmHTTPClient)
+		if !ok || ((c.cert != nil) != tt.hasCert) {
+			t.Errorf("%s: expected client cert %t, got requester %+v",
+				tt.name, tt.hasCert, getHSMRequester())
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Secret redaction.  Trace logging dumps whole Redfish payloads and
+// structs, which carry BMC passwords, SSH keys and private keys.  Every
+// log line goes through redactFormatter, which masks the values of secret
+// fields in JSON and key:value text, PEM private keys, SSH key material,
+// auth headers, bearer tokens and URL passwords.  Struct dumps with %v
+// don't name fields, so types holding secrets also have a Format method
+// which masks them.
+
+const secretRedacted = "REDACTED"
+
+// Returns true if a field name is one whose value is a secret.
+
+func isSecretField(name string) bool {
+	lname := strings.ToLower(name)
+	for _, sfx := range []string{"name", "id", "type"} {
+		if strings.HasSuffix(lname, sfx) {
+			return false
+		}
+	}
+	for _, s := range []string{"password", "passwd", "secret", "token",
+		"credential", "authorization"} {
+		if strings.Contains(lname, s) {
+			return true
+		}
+	}
+	return strings.HasSuffix(lname, "key") || strings.HasSuffix(lname, "keys")
+}
+
+// Returns a copy of unmarshaled JSON data with the values of secret
+// fields replaced.
+
+func redactSecrets(val interface{}) interface{} {
+	switch tval := val.(type) {
+	case map[string]interface{}:
+		rval := make(map[string]interface{})
+		for k, v := range tval {
+			if isSecretField(k) && (v != nil) && (v != "") {
+				rval[k] = secretRedacted
+			} else {
+				rval[k] = redactSecrets(v)
+			}
+		}
+		return rval
+	case []interface{}:
+		rval := make([]interface{}, len(tval))
+		for ix, v := range tval {
+			rval[ix] = redactSecrets(v)
+		}
+		return rval
+	}
+	return val
+}
+
+func maskSecret(s string) string {
+	if s == "" {
+		return s
+	}
+	return secretRedacted
+}
+
+// Patterns whose value is replaced if their field name is a secret one.
+// Group 1 is the field name, group 2 the value.
+
+var secretFieldREs = []struct {
+	re   *regexp.Regexp
+	mask string
+}{
+	//JSON string: "Password":"xyz"
+	{regexp.MustCompile(`"([A-Za-z0-9_@.#\-]+)"\s*:\s*"((?:[^"\\]|\\.)*)"`), secretRedacted},
+	//Escaped JSON string: \"Password\":\"xyz\"
+	{regexp.MustCompile(`\\"([A-Za-z0-9_@.#\-]+)\\"\s*:\s*\\"((?:[^"\\]|\\[^"])*)\\"`), secretRedacted},
+	//JSON list: "AuthorizedKeys":["xyz"]
+	{regexp.MustCompile(`"([A-Za-z0-9_@.#\-]+)"\s*:\s*\[([^\]]*)\]`), `"` + secretRedacted + `"`},
+	//Go struct, map and header dumps, key=value: Password:xyz, password=xyz
+	{regexp.MustCompile(`\b([A-Za-z][A-Za-z0-9_\-]*)(?:=|:[ ]?)(\[[^\]]*\]|"(?:[^"\\]|\\.)*"|[^\s,;&}\])'"]+)`), secretRedacted},
+}
+
+// Patterns which are always replaced.
+
+var secretREs = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]*)PRIVATE KEY-----(?:[A-Za-z0-9+/=\s]|\\[nr])*(?:-----END [A-Z0-9 ]*PRIVATE KEY-----)?`),
+		"-----BEGIN ${1}PRIVATE KEY-----" + secretRedacted + "-----END ${1}PRIVATE KEY-----"},
+	{regexp.MustCompile(`\b(ssh-(?:rsa|dss|ed25519)|ecdsa-sha2-nistp[0-9]+|sk-[a-z0-9\-@.]+)\s+[A-Za-z0-9+/]{16,}={0,3}`),
+		"${1} " + secretRedacted},
+	{regexp.MustCompile(`(?i)\b(authorization\W{0,4})(basic|bearer|negotiate|digest)\s+[^\s"'\],]+`),
+		"${1}${2} " + secretRedacted},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`), secretRedacted},
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`), "${1}" + secretRedacted + "@"},
+}
+
+// Returns text with all secrets found in it masked.
+
+func redactText(text string) string {
+	for _, sre := range secretREs {
+		text = sre.re.ReplaceAllString(text, sre.repl)
+	}
+	for _, sre := range secretFieldREs {
+		//A value which isn't a secret is searched too, it can hold
+		//key:value pairs of its own.
+
+		var sb strings.Builder
+		pos := 0
+		for {
+			m := sre.re.FindStringSubmatchIndex(text[pos:])
+			if m == nil {
+				break
+			}
+			key := text[pos+m[2] : pos+m[3]]
+			vstart, vend := pos+m[4], pos+m[5]
+			val := text[vstart:vend]
+			if !isSecretField(key) || (val == "") || (val == `""`) ||
+				strings.HasPrefix(val, "-----") ||
+				strings.HasPrefix(strings.TrimLeft(text[vend:], " "), secretRedacted) ||
+				strings.Contains(val, secretRedacted) {
+				sb.WriteString(text[pos:vstart])
+				pos = vstart
+				continue
+			}
+			sb.WriteString(text[pos:vstart])
+			sb.WriteString(sre.mask)
+			pos = vend
+		}
+		sb.WriteString(text[pos:])
+		text = sb.String()
+	}
+	return text
+}
+
+// Log formatter which masks secrets in the message and fields of every
+// log entry before handing it to the real formatter.
+
+type redactFormatter struct {
+	logrus.Formatter
+}
+
+func (f *redactFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry.Message = redactText(entry.Message)
+	if len(entry.Data) > 0 {
+		data := make(logrus.Fields, len(entry.Data))
+		for k, v := range entry.Data {
+			switch tv := v.(type) {
+			case string:
+				if isSecretField(k) {
+					data[k] = maskSecret(tv)
+				} else {
+					data[k] = redactText(tv)
+				}
+			case error:
+				data[k] = redactText(tv.Error())
+			default:
+				if isSecretField(k) && (v != nil) {
+					data[k] = secretRedacted
+				} else {
+					data[k] = v
+				}
+			}
+		}
+		entry.Data = data
+	}
+	return f.Formatter.Format(entry)
+}
+
+// Returns the log formatter used by SCSD.
+
+func newLogFormatter() logrus.Formatter {
+	return &redactFormatter{&logrus.TextFormatter{FullTimestamp: true}}
+}
+
+// Formatters of types holding secrets.  Each masks the secrets in a copy,
+// and prints it as a type without a Format method.
+
+func (ti targInfo) Format(f fmt.State, verb rune) {
+	type plain targInfo
+	ti.password = maskSecret(ti.password)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(ti))
+}
+
+func (d credsData) Format(f fmt.State, verb rune) {
+	type plain credsData
+	d.Password = maskSecret(d.Password)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d globalCredsPost) Format(f fmt.State, verb rune) {
+	type plain globalCredsPost
+	d.Password = maskSecret(d.Password)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d bmcCredsData) Format(f fmt.State, verb rune) {
+	type plain bmcCredsData
+	d.Password = maskSecret(d.Password)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d rfAccountData) Format(f fmt.State, verb rune) {
+	type plain rfAccountData
+	d.Password = maskSecret(d.Password)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d cfgParams) Format(f fmt.State, verb rune) {
+	type plain cfgParams
+	d.SSHKey = maskSecret(d.SSHKey)
+	d.SSHConsoleKey = maskSecret(d.SSHConsoleKey)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d SSHAdminData) Format(f fmt.State, verb rune) {
+	type plain SSHAdminData
+	d.AuthorizedKeys = maskSecret(d.AuthorizedKeys)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d rfBiosChangePassword) Format(f fmt.State, verb rune) {
+	type plain rfBiosChangePassword
+	d.OldPassword = maskSecret(d.OldPassword)
+	d.NewPassword = maskSecret(d.NewPassword)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d BiosPasswordPost) Format(f fmt.State, verb rune) {
+	type plain BiosPasswordPost
+	d.OldPassword = maskSecret(d.OldPassword)
+	d.NewPassword = maskSecret(d.NewPassword)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d biosCreds) Format(f fmt.State, verb rune) {
+	type plain biosCreds
+	d.Password = maskSecret(d.Password)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d bmcCertData) Format(f fmt.State, verb rune) {
+	type plain bmcCertData
+	d.Key = maskSecret(d.Key)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
+
+func (d bmcUploadCert) Format(f fmt.State, verb rune) {
+	type plain bmcUploadCert
+	d.Key = maskSecret(d.Key)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain(d))
+}
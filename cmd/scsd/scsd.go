@@ -80,6 +80,7 @@ var tloc trsapi.TrsAPI
 var tlocLocal trsapi.TRSHTTPLocal
 var tlocRemote trsapi.TRSHTTPRemote
 var VaultKeypath string
+var VaultBiosKeypath = "secret/hms-bios-creds"
 var Running = true
 var dfltHTTP = false // for testing
 var caURI string
@@ -88,6 +89,8 @@ var serviceName = "scsd"
 var logger *logrus.Logger
 
 var compCredStore *compcreds.CompCredStore
+var biosCredStore sstorage.SecureStorage
+var secureStore sstorage.SecureStorage // SCSD's own data in Vault
 
 var rfClientLock sync.Mutex
 var caUpdateCount int
@@ -173,12 +176,39 @@ func parseEnvVars() {
 	__env_parse_string("SCSD_SMD_URL", &appParams.SmdURL)
 	__env_parse_bool("SCSD_DEFAULT_HTTP", &dfltHTTP)
 	__env_parse_string("SCSD_CA_URI", &caURI)
+	__env_parse_string("SCSD_BIOS_FEATURES_FILE", &biosFeaturesFile)
+	__env_parse_bool("SCSD_CERT_RENEW_ENABLE", &certRenewEnable)
+	__env_parse_int("SCSD_CERT_RENEW_DAYS", &certRenewDays)
+	__env_parse_int("SCSD_CERT_RENEW_INTERVAL", &certRenewInterval)
+	__env_parse_string("SCSD_CERT_RENEW_DOMAIN", &certRenewDomain)
+	__env_parse_bool("SCSD_STRICT_TLS", &strictTLS)
+	__env_parse_bool("SCSD_TLS_PIN_ENABLE", &tlsPinEnable)
+	__env_parse_bool("SCSD_BMC_IDENTITY_CHECK", &bmcIdentityCheck)
+	__env_parse_int("SCSD_HSM_TIMEOUT", &hsmTimeout)
+	__env_parse_int("SCSD_HSM_RETRIES", &hsmRetries)
+	__env_parse_int("SCSD_HSM_RETRY_BACKOFF", &hsmRetryBackoff)
+	__env_parse_string("SCSD_HSM_CLIENT_CERT", &hsmClientCertFile)
+	__env_parse_string("SCSD_HSM_CLIENT_KEY", &hsmClientKeyFile)
+	__env_parse_bool("SCSD_AUTH_ENABLE", &authEnable)
+	__env_parse_string("SCSD_AUTH_JWKS", &authJWKS)
+	__env_parse_string("SCSD_AUTH_ISSUER", &authIssuer)
+	__env_parse_string("SCSD_AUTH_AUDIENCE", &authAudience)
+	__env_parse_string("SCSD_AUTH_ROLES_CLAIMS", &authRolesClaims)
+	__env_parse_string("SCSD_AUTH_READER_ROLES", &authReaderRoles)
+	__env_parse_string("SCSD_AUTH_OPERATOR_ROLES", &authOperatorRoles)
+	__env_parse_string("SCSD_AUTH_ADMIN_ROLES", &authAdminRoles)
+	__env_parse_string("SCSD_AUDIT_FILE", &auditFile)
+	__env_parse_string("SCSD_AUDIT_TRUSTED_PROXIES", &auditTrustedProxies)
+	__env_parse_int("SCSD_BMC_DIAL_WORKERS", &bmcDialWorkers)
 
 	//These env vars are for vault and need to be named without SCSD_
 	//since libraries use them too.
 
 	var ve bool
 	__env_parse_string("VAULT_KEYPATH", &VaultKeypath)
+	__env_parse_string("VAULT_BIOS_KEYPATH", &VaultBiosKeypath)
+	__env_parse_string("VAULT_TLS_PIN_KEYPATH", &VaultTLSPinKeypath)
+	__env_parse_string("VAULT_CERT_META_KEYPATH", &VaultCertMetaKeypath)
 	veseen := __env_parse_bool("VAULT_ENABLE", &ve)
 	if veseen {
 		appParams.VaultEnable = &ve
@@ -202,6 +232,8 @@ func setupVault() {
 		} else {
 			logger.Infof("Connected to vault.")
 			compCredStore = compcreds.NewCompCredStore(VaultKeypath, ss)
+			biosCredStore = ss
+			secureStore = ss
 			break
 		}
 	}
@@ -216,8 +248,8 @@ func setupTRSCA() error {
 
 	caChain, err := hms_certs.FetchCAChain(caURI)
 	if err != nil {
-		return fmt.Errorf("ERROR fetching CA chain from '%s', falling back to unvalidated https for Redfish communications: %v",
-			caURI, err)
+		return fmt.Errorf("ERROR fetching CA chain from '%s', %s: %v",
+			caURI, rfTLSFallbackMsg(), err)
 	}
 
 	rfClientLock.Lock()
@@ -225,14 +257,23 @@ func setupTRSCA() error {
 	rawCert := hms_certs.TupleToNewline(caChain)
 	err = tloc.SetSecurity(trsapi.TRSHTTPLocalSecurity{CACertBundleData: rawCert})
 	if err != nil {
-		return fmt.Errorf("ERROR setting CA chain in HTTP interface, falling back to unvalidated https for Redfish communications.")
+		setRFTLSCA("")
+		setHSMCA(nil)
+		return fmt.Errorf("ERROR setting CA chain in HTTP interface, %s.",
+			rfTLSFallbackMsg())
 	}
+	err = setRFTLSCA(rawCert)
+	if err != nil {
+		logger.Errorf("setupTRSCA(): Can't use CA chain from '%s' for TLS validation, %s: %v",
+			caURI, rfTLSFallbackMsg(), err)
+	}
+	setHSMCA(getRFTLSPool())
 	caUpdateCount++
 	return nil
 }
 
 func caCB(cbData string) {
-	logger.Infof("Updating CA bundle for Redfish HTTP transports.")
+	logger.Infof("Updating CA bundle for Redfish and HSM HTTP transports.")
 	logger.Infof("All API threads paused.")
 	err := setupTRSCA()
 	if err != nil {
@@ -277,8 +318,8 @@ func main() {
 	var err error
 
 	logger = logrus.New()
-	logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
-	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	logrus.SetFormatter(newLogFormatter())
+	logger.SetFormatter(newLogFormatter())
 
 	venb := true
 	appParams.VaultEnable = &venb
@@ -286,6 +327,14 @@ func main() {
 
 	setLogLevel()
 
+	if biosFeaturesFile != "" {
+		err = loadBiosFeatures(biosFeaturesFile)
+		if err != nil {
+			logger.Errorf("Can't load BIOS features from '%s', using built-in features: %v",
+				biosFeaturesFile, err)
+		}
+	}
+
 	tloc = &tlocLocal
 	if !appParams.LocalMode {
 		tloc = &tlocRemote
@@ -317,6 +366,11 @@ func main() {
 
 	hms_certs.InitInstance(logger, serviceName)
 
+	err = setupHSMClient()
+	if err != nil {
+		logger.Fatalf("FATAL: HSM client setup failed: %v", err)
+	}
+
 	if appParams.LocalMode && (caURI != "") {
 
 		//Set up TRS cert security stuff and register CA chain update callback
@@ -384,11 +438,43 @@ func main() {
 	logger.Infof("TRS kafka URL:    '%s'", appParams.KafkaURL)
 	logger.Infof("Vault enabled:    %t", *appParams.VaultEnable)
 	logger.Infof("Vault keypath:    '%s'", VaultKeypath)
+	logger.Infof("Vault BIOS keypath: '%s'", VaultBiosKeypath)
+	logger.Infof("Vault TLS pin keypath: '%s'", VaultTLSPinKeypath)
+	logger.Infof("Vault cert data keypath: '%s'", VaultCertMetaKeypath)
+	logger.Infof("Cert renewal:     %t", certRenewEnable)
+	logger.Infof("Strict TLS:       %t", strictTLS)
+	logger.Infof("TLS pinning:      %t", tlsPinEnable)
+	logger.Infof("BMC identity check: %t", bmcIdentityCheck)
+	logger.Infof("HSM timeout:      %d", hsmTimeout)
+	logger.Infof("HSM retries:      %d", hsmRetries)
+	logger.Infof("HSM client cert:  '%s'", hsmClientCertFile)
+	logger.Infof("Audit log:        '%s'", auditFile)
+	logger.Infof("Audit proxies:    '%s'", auditTrustedProxies)
+	logger.Infof("API auth:         %t", authEnable)
+	if authEnable {
+		logger.Infof("API auth JWKS:    '%s'", authJWKS)
+		_, err = getJWKS(false)
+		if err != nil {
+			logger.Errorf("API auth JWKS not loaded, requests will be refused until it is.")
+		}
+	}
+	if strictTLS && (!appParams.LocalMode || (caURI == "")) {
+		logger.Errorf("Strict TLS mode needs local TRS mode and a CA bundle URI, all Redfish operations will be refused.")
+	}
 
 	if *appParams.VaultEnable {
 		setupVault()
 	}
 
+	if certRenewEnable {
+		if certRenewInterval <= 0 {
+			logger.Errorf("Invalid cert renewal interval %d, using 86400 seconds.",
+				certRenewInterval)
+			certRenewInterval = 86400
+		}
+		go certRenewTask()
+	}
+
 	logger.Infof("Starting up HTTP server.")
 	err = srv.ListenAndServe()
 	if err != http.ErrServerClosed {
@@ -39,8 +39,14 @@ func TestENV(t *testing.T) {
 	kurl := "localhost:9678"
 	surl := "http://a.b.c.d/hsm/v2"
 	vkey := "vault_keypath"
+	vbkey := "vault_bios_keypath"
 	venbl := "true"
 	dflth := "yes"
+	bfile := "/tmp/bios_features.json"
+	crenew := "true"
+	cdays := "45"
+	cint := "3600"
+	cdom := "chassis"
 	os.Setenv("SCSD_HTTP_LISTEN_PORT", hport)
 	os.Setenv("SCSD_HTTP_RETRIES", hret)
 	os.Setenv("SCSD_UUID", uuid)
@@ -49,8 +55,14 @@ func TestENV(t *testing.T) {
 	os.Setenv("SCSD_KAFKA_URL", kurl)
 	os.Setenv("SCSD_SMD_URL", surl)
 	os.Setenv("VAULT_KEYPATH", vkey)
+	os.Setenv("VAULT_BIOS_KEYPATH", vbkey)
 	os.Setenv("VAULT_ENABLE", venbl)
 	os.Setenv("SCSD_DEFAULT_HTTP", dflth)
+	os.Setenv("SCSD_BIOS_FEATURES_FILE", bfile)
+	os.Setenv("SCSD_CERT_RENEW_ENABLE", crenew)
+	os.Setenv("SCSD_CERT_RENEW_DAYS", cdays)
+	os.Setenv("SCSD_CERT_RENEW_INTERVAL", cint)
+	os.Setenv("SCSD_CERT_RENEW_DOMAIN", cdom)
 
 	parseEnvVars()
 
@@ -86,12 +98,37 @@ func TestENV(t *testing.T) {
 		t.Errorf("Mismatch of env Vault keypath, exp: %s, got: %s\n",
 			vkey, VaultKeypath)
 	}
+	if VaultBiosKeypath != vbkey {
+		t.Errorf("Mismatch of env Vault BIOS keypath, exp: %s, got: %s\n",
+			vbkey, VaultBiosKeypath)
+	}
 	if *appParams.VaultEnable == false {
 		t.Errorf("Mismatch of env VaultEnable, exp: true, got: false\n")
 	}
 	if dfltHTTP == false {
 		t.Errorf("Mismatch of env default http, exp: true, got: false\n")
 	}
+	if biosFeaturesFile != bfile {
+		t.Errorf("Mismatch of env BIOS features file, exp: %s, got: %s\n",
+			bfile, biosFeaturesFile)
+	}
+	if certRenewEnable == false {
+		t.Errorf("Mismatch of env cert renewal, exp: true, got: false\n")
+	}
+	ival, _ = strconv.Atoi(cdays)
+	if certRenewDays != ival {
+		t.Errorf("Mismatch of env cert renewal days, exp: %d, got: %d\n",
+			ival, certRenewDays)
+	}
+	ival, _ = strconv.Atoi(cint)
+	if certRenewInterval != ival {
+		t.Errorf("Mismatch of env cert renewal interval, exp: %d, got: %d\n",
+			ival, certRenewInterval)
+	}
+	if certRenewDomain != cdom {
+		t.Errorf("Mismatch of env cert renewal domain, exp: %s, got: %s\n",
+			cdom, certRenewDomain)
+	}
 }
 
 func printStuff() {
@@ -104,8 +141,8 @@ func printStuff() {
 
 func loggerSetup() {
 	logger = logrus.New()
-	logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
-	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	logrus.SetFormatter(newLogFormatter())
+	logger.SetFormatter(newLogFormatter())
 }
 
 func TestSetLogLevel(t *testing.T) {
@@ -45,12 +45,17 @@ import (
 
 type crayCertificateServiceActions struct {
 	ReplaceCert crayCertificateServiceActionsReplaceCert `json:"#CertificateService.ReplaceCertificate"`
+	GenerateCSR crayCertificateServiceActionsGenerateCSR `json:"#CertificateService.GenerateCSR"`
 }
 
 type crayCertificateServiceActionsReplaceCert struct {
 	Target string `json:"target"`
 }
 
+type crayCertificateServiceActionsGenerateCSR struct {
+	Target string `json:"target"`
+}
+
 // Cray Certificate Service
 
 type crayCertificateService struct {
@@ -137,20 +142,27 @@ type hpeSecurityServiceLinksHttpsCert struct {
 }
 
 type hpeSecurityServiceHttpsCert struct {
-	Actions hpeSecurityServiceHttpsCertActions `json:"Actions"`
+	Actions                   hpeSecurityServiceHttpsCertActions `json:"Actions"`
+	CertificateSigningRequest string                             `json:"CertificateSigningRequest,omitempty"`
 }
 
 type hpeSecurityServiceHttpsCertActions struct {
 	ImportCertificate hpeSecurityServiceHttpsCertActionsImport `json:"#HpeHttpsCert.ImportCertificate"`
+	GenerateCSR       hpeSecurityServiceHttpsCertActionsCSR    `json:"#HpeHttpsCert.GenerateCSR"`
 }
 
 type hpeSecurityServiceHttpsCertActionsImport struct {
 	Target string `json:"target"`
 }
 
+type hpeSecurityServiceHttpsCertActionsCSR struct {
+	Target string `json:"target"`
+}
+
 // Redfish Service root
 
 type rfServiceRoot struct {
+	UUID               string `json:"UUID"`
 	CertificateService crayRootCertService
 }
 
@@ -180,9 +192,14 @@ type bmcCertData struct {
 // Used for /bmc/managecerts POST and DELETE
 
 type bmcManageCertPost struct {
-	Domain    string   `json:"Domain"`
-	DomainIDs []string `json:"DomainIDs"`
-	FQDN      string   `json:"FQDN,omitempty"`
+	Domain       string   `json:"Domain"`
+	DomainIDs    []string `json:"DomainIDs"`
+	FQDN         string   `json:"FQDN,omitempty"`
+	AltNames     []string `json:"AltNames,omitempty"`     //createcerts only
+	IPAddresses  []string `json:"IPAddresses,omitempty"`  //createcerts only
+	KeyType      string   `json:"KeyType,omitempty"`      //createcerts only
+	KeyBits      int      `json:"KeyBits,omitempty"`      //createcerts only
+	ValidityDays int      `json:"ValidityDays,omitempty"` //createcerts only
 }
 
 type bmcManageCertPostRsp struct {
@@ -198,6 +215,7 @@ type certRsp struct {
 	StatusCode int       `json:"StatusCode"`
 	StatusMsg  string    `json:"StatusMsg"`
 	Cert       *certData `json:"Cert,omitempty"`
+	TLSVerify  string    `json:"TLSVerify,omitempty"`
 }
 
 type certData struct {
@@ -212,52 +230,52 @@ type rfCertPost struct {
 	Force      bool     `json:"Force"`
 	CertDomain string   `json:"CertDomain"` //"Cabinet", "Chassis", "BMC", etc.
 	Targets    []string `json:"Targets"`
+	Verify     bool     `json:"Verify,omitempty"`
+	VerifyWait int      `json:"VerifyWait,omitempty"` //seconds
 }
 
 type rfCertPostRsp struct {
 	Targets []certRsp `json:"Targets"`
 }
 
+// Outcome of a cert operation on one target, for failures which happen
+// outside of a task and for returned certs.
+
+type certOpResult struct {
+	statusCode int
+	statusMsg  string
+	cert       string
+}
+
 const (
 	VendorCray     = "Cray"
 	VendorHPE      = "HPE"
 	VendorIntel    = "Intel"
 	VendorGigabyte = "GB"
+	VendorUnknown  = "Unknown"
 )
 
-// Fetch the certificate URIs needed for cert mgmt, on each target controller
-// in a task list.
+// Determine the vendor of each target controller in a task list, for
+// cert mgmt.
 //
 // The algo is:
-//   o Verify target using the service root
-//   o Check for /redfish/v1/CertificateService.  If present, may be Intel or
-//     Cray; if not, it's HPE or GigaByte.
+//   o Get /redfish/v1/Chassis.
 //   o Unambiguate.  Cray:    /redfish/v1/Chassis/Enclosure
-//                   Intel:   /redfish/v1/Chassis/RackMount (NOT SUPPORTED)
+//                   Intel:   /redfish/v1/Chassis/RackMount
 //                   HPE:     /redfish/v1/Chassis/1
-//                   GB:      /redfish/v1/Chassis/Self (NOT SUPPORTED)
+//                   GB:      /redfish/v1/Chassis/Self
 //                   RTS/PDU: /redfish/v1/Chassis returns {}.  Also check the
 //                            URL, it will have -rts:port.  Same RF cert
 //                            schema as Cray mountain!
-//   o Call Cray or HPE cert func, and mark the rest as unsupported.
 //
-// taskList(inout): Task list to execute on which to perform cert replacement.
-// certs(in):       TLS cert/key data (leaf cert).
-// retData(out):    Returned data for REST return.
-// Return:          Error if a failure occurs, else nil.
+// funcName(in):    Name of the calling func, for logging.
+// taskList(inout): Task list of the targets.  Failed targets are ignored.
+// retData(out):    Return data, failed targets are added.
+// Return:          Vendor of each task, empty for ignored tasks;
+//                  error if a failure occurs, else nil.
 
-func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
-	retData *rfCertPostRsp) error {
+func getCertVendors(funcName string, taskList []trsapi.HttpTask, retData *rfCertPostRsp) ([]string, error) {
 	var err error
-	var sourceTL trsapi.HttpTask
-	var certsCray, certsHPE []bmcCertData
-
-	funcName := "setCerts()"
-
-	if len(taskList) != len(certs) {
-		return fmt.Errorf("%s: ERROR: Internal error, key array len != task array len.",
-			funcName)
-	}
 
 	//Set the URL to /redfish/v1/Chassis
 
@@ -272,7 +290,7 @@ func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
 	if err != nil {
 		logger.Errorf("%s: Problem executing chassis data fetch: %v",
 			funcName, err)
-		return err
+		return nil, err
 	}
 
 	//Set the return data for any chassis-get ops that failed, and also set
@@ -292,23 +310,22 @@ func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
 		}
 	}
 
-	//Get the results, unambiguate, mark unsupported ones, and call the func
-	//corresponding to the target vendor (Cray vs. HPE).
+	//Get the results and unambiguate.
 
-	var tlistCray, tlistHPE, tlistUns []string
-	var jdata rfChassis
+	vendors := make([]string, len(taskList))
 
 	for ii := 0; ii < len(taskList); ii++ {
 		if taskList[ii].Ignore {
 			continue
 		}
 
+		var jdata rfChassis
 		targ := targFromTask(&taskList[ii])
 		err = grabTaskRspData(funcName, &taskList[ii], &jdata)
 		if err != nil {
 			logger.Errorf("%s: Problem getting/parsing response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, err
 		}
 
 		//Check for RTS PDUs.  These have -rts:port in the hostname portion of
@@ -318,44 +335,97 @@ func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
 
 		if strings.Contains(taskList[ii].Request.Host, "-rts") ||
 			(len(jdata.Members) == 0) {
-			tlistCray = append(tlistCray, targ)
-			certsCray = append(certsCray, bmcCertData{Cert: certs[ii].Cert, Key: certs[ii].Key})
+			vendors[ii] = VendorCray
 			continue
 		}
 
-		found := false
+		vendors[ii] = VendorUnknown
 		for member := 0; member < len(jdata.Members); member++ {
 			logger.Tracef("Member[%d]: '%s", member, jdata.Members[member])
 			if strings.Contains(jdata.Members[member].ID, "Enclosure") {
-				logger.Tracef("Cray")
-				tlistCray = append(tlistCray, targ)
-				certsCray = append(certsCray, bmcCertData{Cert: certs[ii].Cert, Key: certs[ii].Key})
-				logger.Tracef("%s: Adding '%s' to Cray list", funcName, targ)
-				found = true
+				logger.Tracef("%s: '%s' is Cray", funcName, targ)
+				vendors[ii] = VendorCray
 				break
-			} else if strings.Contains(jdata.Members[member].ID, "RackMount") ||
-				strings.Contains(jdata.Members[member].ID, "Self") {
-				logger.Tracef("Unsupported: intel or GB")
-				tlistUns = append(tlistUns, targ)
-				logger.Tracef("%s: Adding '%s' to unsupported-vendor list",
-					funcName, targ)
-				found = true
+			} else if strings.Contains(jdata.Members[member].ID, "RackMount") {
+				logger.Tracef("%s: '%s' is Intel", funcName, targ)
+				vendors[ii] = VendorIntel
+				break
+			} else if strings.Contains(jdata.Members[member].ID, "Self") {
+				logger.Tracef("%s: '%s' is GB", funcName, targ)
+				vendors[ii] = VendorGigabyte
 				break
 			} else {
 				logger.Tracef("Might be HPE")
 				toks := strings.Split(strings.Trim(jdata.Members[member].ID, "/"), "/")
 				_, err := strconv.Atoi(toks[len(toks)-1])
 				if err == nil {
-					logger.Tracef("HPE")
-					tlistHPE = append(tlistHPE, targ)
-					certsHPE = append(certsHPE, bmcCertData{Cert: certs[ii].Cert, Key: certs[ii].Key})
-					logger.Tracef("%s: Adding '%s' to iLO list", funcName, targ)
-					found = true
+					logger.Tracef("%s: '%s' is HPE", funcName, targ)
+					vendors[ii] = VendorHPE
 					break
 				}
 			}
 		}
-		if !found {
+	}
+
+	return vendors, nil
+}
+
+// Fetch the certificate URIs needed for cert mgmt, on each target controller
+// in a task list.
+//
+// The algo is:
+//   o Get the vendor of each target from its Chassis data.
+//   o Call the Cray or HPE cert func, or the DMTF one for Intel and GB,
+//     and mark the rest as unsupported.
+//
+// taskList(inout): Task list to execute on which to perform cert replacement.
+// certs(in):       TLS cert/key data (leaf cert).
+// retData(out):    Returned data for REST return.
+// Return:          Error if a failure occurs, else nil.
+
+func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
+	retData *rfCertPostRsp) error {
+	var err error
+	var sourceTL trsapi.HttpTask
+	var certsCray, certsHPE, certsRF []bmcCertData
+
+	funcName := "setCerts()"
+
+	if len(taskList) != len(certs) {
+		return fmt.Errorf("%s: ERROR: Internal error, key array len != task array len.",
+			funcName)
+	}
+
+	vendors, err := getCertVendors(funcName, taskList, retData)
+	if err != nil {
+		return err
+	}
+
+	//Call the func corresponding to the target vendor (Cray, HPE, or DMTF
+	//standard for Intel and Gigabyte), and mark the rest as unsupported.
+
+	var tlistCray, tlistHPE, tlistRF, tlistUns []string
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+
+		targ := targFromTask(&taskList[ii])
+		switch vendors[ii] {
+		case VendorCray:
+			tlistCray = append(tlistCray, targ)
+			certsCray = append(certsCray, bmcCertData{Cert: certs[ii].Cert, Key: certs[ii].Key})
+			logger.Tracef("%s: Adding '%s' to Cray list", funcName, targ)
+		case VendorHPE:
+			tlistHPE = append(tlistHPE, targ)
+			certsHPE = append(certsHPE, bmcCertData{Cert: certs[ii].Cert, Key: certs[ii].Key})
+			logger.Tracef("%s: Adding '%s' to iLO list", funcName, targ)
+		case VendorIntel, VendorGigabyte:
+			tlistRF = append(tlistRF, targ)
+			certsRF = append(certsRF, bmcCertData{Cert: certs[ii].Cert, Key: certs[ii].Key})
+			logger.Tracef("%s: Adding '%s' to DMTF list", funcName, targ)
+		default:
 			tlistUns = append(tlistUns, targ)
 			logger.Tracef("%s: Adding '%s' to unsupported-vendor list",
 				funcName, targ)
@@ -391,6 +461,17 @@ func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
 		setRetData(taskListHPE, retData)
 	}
 
+	if len(tlistRF) > 0 {
+		logger.Tracef("%s: Setting Intel/Gigabyte certs.", funcName)
+		taskListRF := tloc.CreateTaskList(&sourceTL, len(tlistRF))
+		err = doDMTFCerts(taskListRF, tlistRF, certsRF, retData)
+		if err != nil {
+			logger.Errorf("%s: Problem setting TLS certs on Intel/Gigabyte target(s): %v",
+				funcName, err)
+			return err
+		}
+	}
+
 	//Populate unsupported ones
 
 	for ix := 0; ix < len(tlistUns); ix++ {
@@ -400,6 +481,16 @@ func setCerts(taskList []trsapi.HttpTask, certs []bmcCertData,
 		retData.Targets = append(retData.Targets, elm)
 	}
 
+	//Move TLS pins to the installed certs
+
+	pushed := make(map[string]string)
+	for ii := 0; ii < len(taskList); ii++ {
+		if !taskList[ii].Ignore {
+			pushed[targFromTask(&taskList[ii])] = certs[ii].Cert
+		}
+	}
+	pinPushedCerts(retData, pushed)
+
 	return nil
 }
 
@@ -417,6 +508,29 @@ func setRetData(taskList []trsapi.HttpTask, retData *rfCertPostRsp) {
 	}
 }
 
+// Make the response data for a task list, using the recorded results of
+// targets which failed outside of a task, and the task status for the rest.
+
+func setCertOpRetData(taskList []trsapi.HttpTask, results []certOpResult, retData *rfCertPostRsp) {
+	for ii := 0; ii < len(taskList); ii++ {
+		targ := targFromTask(&taskList[ii])
+		if results[ii].statusCode != 0 {
+			retData.Targets = append(retData.Targets, certRsp{ID: targ,
+				StatusCode: results[ii].statusCode,
+				StatusMsg:  results[ii].statusMsg})
+			continue
+		}
+		elm := certRsp{ID: targ,
+			StatusCode: getStatusCode(&taskList[ii]),
+			StatusMsg:  getStatusMsg(&taskList[ii])}
+		if statusCodeOK(elm.StatusCode) && (results[ii].cert != "") {
+			elm.Cert = &certData{CertType: "PEM",
+				CertData: hms_certs.NewlineToTuple(results[ii].cert)}
+		}
+		retData.Targets = append(retData.Targets, elm)
+	}
+}
+
 //Massage a cert and key into a usable JSON payload.
 
 func makeRFCertPayload(vendor string, cert bmcCertData, certURI string, certType string) []byte {
@@ -469,6 +583,117 @@ func doCrayCerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertD
 	var err error
 	funcName := "doCrayCerts()"
 
+	certSvcs, certURIs, err := getCrayCertLocations(funcName, taskList, targList)
+	if err != nil {
+		return err
+	}
+
+	//Create URL and payload for writing cert.
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+
+		targ := targFromTask(&taskList[ii])
+		url := dfltProtocol + "://" + targ + certSvcs[ii].Actions.ReplaceCert.Target
+		pld := makeRFCertPayload(VendorCray, certs[ii], certURIs[ii], "PEM")
+
+		//This is needed for testing with older mountain BMC FW which
+		//uses a different URL than the CertificateLocations says.
+		//
+		//aaa := strings.Replace(certURI,"/Certificates","",1)
+		//logger.Tracef("Cray Cert URI: '%s'",aaa)
+		//pld := makeRFCertPayload(VendorCray,certs[ii],aaa,"PEM")
+
+		taskList[ii].Request, _ = http.NewRequest("POST", url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+		/*etag := jdata.Etag	//might be empty, that's OK
+		if (etag != nil) {
+			taskList[ii].Request.Header.Add(ET_IFNONE,fixEtag(etag))
+		}*/
+		logger.Tracef("%s: url: '%s', '%s'",
+			funcName, url, taskList[ii].Request.URL.Path)
+	}
+
+	//Do the POST to write the new certs
+
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem setting certificate: %v", funcName, err)
+		return err
+	}
+	ignoreBadTasks(funcName, taskList)
+	logger.Tracef("%s: Finished cert replacement.", funcName)
+
+	return nil
+}
+
+// DMTF standard cert replacement, used for Intel and Gigabyte BMCs.  Same
+// algo as for Cray, but River BMCs vary in what they implement, so targets
+// lacking the ReplaceCertificate action or a cert location are reported as
+// unsupported.
+
+func doDMTFCerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertData, retData *rfCertPostRsp) error {
+	funcName := "doDMTFCerts()"
+	results := make([]certOpResult, len(taskList))
+
+	certSvcs, certURIs, err := getCrayCertLocations(funcName, taskList, targList)
+	if err != nil {
+		return err
+	}
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+		if certSvcs[ii].Actions.ReplaceCert.Target == "" {
+			taskList[ii].Ignore = true
+			results[ii] = certOpResult{statusCode: http.StatusNotImplemented,
+				statusMsg: "ReplaceCertificate not supported"}
+			continue
+		}
+		if certURIs[ii] == "" {
+			taskList[ii].Ignore = true
+			results[ii] = certOpResult{statusCode: http.StatusNotImplemented,
+				statusMsg: "No certificate location found"}
+			continue
+		}
+
+		//Vendor only matters for HPE, the rest take the standard payload.
+
+		targ := targFromTask(&taskList[ii])
+		url := dfltProtocol + "://" + targ + certSvcs[ii].Actions.ReplaceCert.Target
+		pld := makeRFCertPayload("", certs[ii], certURIs[ii], "PEM")
+		taskList[ii].Request, _ = http.NewRequest(http.MethodPost, url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+		logger.Tracef("%s: url: '%s', cert URI: '%s'", funcName, url, certURIs[ii])
+	}
+
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem setting certificate: %v", funcName, err)
+		return err
+	}
+	ignoreBadTasks(funcName, taskList)
+	logger.Tracef("%s: Finished cert replacement.", funcName)
+
+	setCertOpRetData(taskList, results, retData)
+	return nil
+}
+
+// Fetch the Cray CertificateService data and the URI of the current cert
+// of each target in a task list.  Targets which fail are ignored.
+//
+// funcName(in):    Name of the calling func, for logging.
+// taskList(inout): Task list of the targets.
+// targList(in):    Targets of the task list.
+// Return:          CertificateService data and cert URI of each task;
+//                  error if a failure occurs, else nil.
+
+func getCrayCertLocations(funcName string, taskList []trsapi.HttpTask, targList []string) ([]crayCertificateService, []string, error) {
+	var err error
+
 	//First check /redfish/v1/CertificateService to verify we're using PEM
 	//certs and that we have the cert replacement URI
 
@@ -478,16 +703,16 @@ func doCrayCerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertD
 	if err != nil {
 		logger.Errorf("%s: Problem executing cert set: %v",
 			funcName, err)
-		return err
+		return nil, nil, err
 	}
 	ignoreBadTasks(funcName, taskList)
 
 	//Parse the results, set up the next stage.
 
-	//etags := make([]string,len(taskList))
-	actionURIs := make([]string, len(taskList))
+	certSvcs := make([]crayCertificateService, len(taskList))
+	certURIs := make([]string, len(taskList))
 
-	logger.Tracef("%s: Parsing Chassis data, setting up for CertificateLocation data.",
+	logger.Tracef("%s: Parsing CertificateService data, setting up for CertificateLocation data.",
 		funcName)
 
 	for ii := 0; ii < len(taskList); ii++ {
@@ -495,19 +720,17 @@ func doCrayCerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertD
 			continue
 		}
 
-		var jdata crayCertificateService
 		targ := targFromTask(&taskList[ii])
-		err = grabTaskRspData(funcName, &taskList[ii], &jdata)
+		err = grabTaskRspData(funcName, &taskList[ii], &certSvcs[ii])
 		if err != nil {
 			logger.Errorf("%s: Problem getting response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, nil, err
 		}
 
-		//Get the action URI and the CertificateLocations URI
+		//Get the CertificateLocations URI
 
-		actionURIs[ii] = jdata.Actions.ReplaceCert.Target
-		url := dfltProtocol + "://" + targ + jdata.CertificateLocations.ID
+		url := dfltProtocol + "://" + targ + certSvcs[ii].CertificateLocations.ID
 		taskList[ii].Request, _ = http.NewRequest("GET", url, nil)
 	}
 
@@ -515,14 +738,13 @@ func doCrayCerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertD
 	if err != nil {
 		logger.Errorf("%s: Problem executing cert service data: %v",
 			funcName, err)
-		return err
+		return nil, nil, err
 	}
 	ignoreBadTasks(funcName, taskList)
 
-	//Parse the cert location URI, set stage for the cert write.
+	//Parse the cert location URI.
 
-	logger.Tracef("%s: Parsing cert location data, set up for cert replace.",
-		funcName)
+	logger.Tracef("%s: Parsing cert location data.", funcName)
 
 	for ii := 0; ii < len(taskList); ii++ {
 		if taskList[ii].Ignore {
@@ -530,50 +752,23 @@ func doCrayCerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertD
 		}
 
 		var jdata crayCertificateLocations
-		targ := targFromTask(&taskList[ii])
 		err = grabTaskRspData(funcName, &taskList[ii], &jdata)
 		if err != nil {
 			logger.Errorf("%s: Problem getting response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, nil, err
 		}
 
-		//Create URL and payload for writing cert.  Note that Cray only
-		//supports 1 cert URI even though it's represented as an array.
+		//Note that Cray only supports 1 cert URI even though it's
+		//represented as an array.
 		//TODO: this may change eventually.
 
-		certURI := jdata.Links.Certificates[0].ID
-		url := dfltProtocol + "://" + targ + actionURIs[ii]
-		pld := makeRFCertPayload(VendorCray, certs[ii], certURI, "PEM")
-
-		//This is needed for testing with older mountain BMC FW which
-		//uses a different URL than the CertificateLocations says.
-		//
-		//aaa := strings.Replace(certURI,"/Certificates","",1)
-		//logger.Tracef("Cray Cert URI: '%s'",aaa)
-		//pld := makeRFCertPayload(VendorCray,certs[ii],aaa,"PEM")
-
-		taskList[ii].Request, _ = http.NewRequest("POST", url, bytes.NewBuffer(pld))
-		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
-		/*etag := jdata.Etag	//might be empty, that's OK
-		if (etag != nil) {
-			taskList[ii].Request.Header.Add(ET_IFNONE,fixEtag(etag))
-		}*/
-		logger.Tracef("%s: url: '%s', '%s'",
-			funcName, url, taskList[ii].Request.URL.Path)
-	}
-
-	//Do the POST to write the new certs
-
-	err = doOp(taskList)
-	if err != nil {
-		logger.Errorf("%s: Problem setting certificate: %v", funcName, err)
-		return err
+		if len(jdata.Links.Certificates) > 0 {
+			certURIs[ii] = jdata.Links.Certificates[0].ID
+		}
 	}
-	ignoreBadTasks(funcName, taskList)
-	logger.Tracef("%s: Finished cert replacement.", funcName)
 
-	return nil
+	return certSvcs, certURIs, nil
 }
 
 // HPE blade cert replacement.
@@ -605,6 +800,52 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 	var err error
 	funcName := "doHPECerts()"
 
+	httpsCerts, _, err := getHPEHttpsCerts(funcName, taskList, targList)
+	if err != nil {
+		return err
+	}
+
+	// Set the stage for the cert POST
+
+	logger.Tracef("%s: Setup for cert replacement.", funcName)
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+
+		targ := targFromTask(&taskList[ii])
+		pld := makeRFCertPayload(VendorHPE, certs[ii], "", "")
+		url := dfltProtocol + "://" + targ + httpsCerts[ii].Actions.ImportCertificate.Target
+		taskList[ii].Request, _ = http.NewRequest("POST", url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem executing cert set: %v",
+			funcName, err)
+		return err
+	}
+
+	ignoreBadTasks(funcName, taskList)
+	logger.Tracef("%s: Finished cert replacement.", funcName)
+
+	return nil
+}
+
+// Fetch the HPE HttpsCert data of each target in a task list, by way of
+// the Managers and the Oem SecurityService.  Targets which fail are ignored.
+//
+// funcName(in):    Name of the calling func, for logging.
+// taskList(inout): Task list of the targets.
+// targList(in):    Targets of the task list.
+// Return:          HttpsCert data and HttpsCert URI of each task;
+//                  error if a failure occurs, else nil.
+
+func getHPEHttpsCerts(funcName string, taskList []trsapi.HttpTask, targList []string) ([]hpeSecurityServiceHttpsCert, []string, error) {
+	var err error
+
 	// GET /redfish/v1/Managers  to get the manager ID (should be only
 	// one entry)
 
@@ -614,7 +855,7 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 	if err != nil {
 		logger.Errorf("%s: Problem executing cert set: %v",
 			funcName, err)
-		return err
+		return nil, nil, err
 	}
 	ignoreBadTasks(funcName, taskList)
 
@@ -634,7 +875,7 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 		if err != nil {
 			logger.Errorf("%s: Problem getting response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, nil, err
 		}
 
 		logger.Tracef("%s, '%s': Managers: '%v'",
@@ -649,7 +890,7 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 	if err != nil {
 		logger.Errorf("%s: Problem executing cert set: %v",
 			funcName, err)
-		return err
+		return nil, nil, err
 	}
 	ignoreBadTasks(funcName, taskList)
 
@@ -668,7 +909,7 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 		if err != nil {
 			logger.Errorf("%s: Problem getting response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, nil, err
 		}
 
 		url := dfltProtocol + "://" + targ + jdata.Oem.HPE.Links.SecurityService.ID
@@ -679,7 +920,7 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 	if err != nil {
 		logger.Errorf("%s: Problem executing cert set: %v",
 			funcName, err)
-		return err
+		return nil, nil, err
 	}
 	ignoreBadTasks(funcName, taskList)
 
@@ -698,7 +939,7 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 		if err != nil {
 			logger.Errorf("%s: Problem getting response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, nil, err
 		}
 
 		url := dfltProtocol + "://" + targ + jdata.Links.HttpsCert.ID
@@ -709,48 +950,32 @@ func doHPECerts(taskList []trsapi.HttpTask, targList []string, certs []bmcCertDa
 	if err != nil {
 		logger.Errorf("%s: Problem executing cert set: %v",
 			funcName, err)
-		return err
+		return nil, nil, err
 	}
 	ignoreBadTasks(funcName, taskList)
 
-	// Get the action info, set the stage for the cert POST
+	// Get the action info
 
-	logger.Tracef("%s: Parsing cert info, setup for cert replacement.",
-		funcName)
+	logger.Tracef("%s: Parsing cert info.", funcName)
+
+	httpsCerts := make([]hpeSecurityServiceHttpsCert, len(taskList))
+	certURIs := make([]string, len(taskList))
 
 	for ii := 0; ii < len(taskList); ii++ {
 		if taskList[ii].Ignore {
 			continue
 		}
 
-		var jdata hpeSecurityServiceHttpsCert
-		targ := targFromTask(&taskList[ii])
-		err = grabTaskRspData(funcName, &taskList[ii], &jdata)
+		err = grabTaskRspData(funcName, &taskList[ii], &httpsCerts[ii])
 		if err != nil {
 			logger.Errorf("%s: Problem getting response from '%s': %v",
 				funcName, taskList[ii].Request.URL.Path, err)
-			return err
+			return nil, nil, err
 		}
-
-		//Set up for the cert post
-
-		pld := makeRFCertPayload(VendorHPE, certs[ii], "", "")
-		url := dfltProtocol + "://" + targ + jdata.Actions.ImportCertificate.Target
-		taskList[ii].Request, _ = http.NewRequest("POST", url, bytes.NewBuffer(pld))
-		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
-	}
-
-	err = doOp(taskList)
-	if err != nil {
-		logger.Errorf("%s: Problem executing cert set: %v",
-			funcName, err)
-		return err
+		certURIs[ii] = taskList[ii].Request.URL.Path
 	}
 
-	ignoreBadTasks(funcName, taskList)
-	logger.Tracef("%s: Finished cert replacement.", funcName)
-
-	return nil
+	return httpsCerts, certURIs, nil
 }
 
 // Convert a user-supplied domain name to one hms_certs can understand.
@@ -797,6 +1022,15 @@ func doBMCCreateCertsPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = validateCreateCertOpts(&jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Invalid cert options: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		logger.Errorf("%s: %s", funcName, emsg)
+		return
+	}
+
 	retData.DomainIDs = make([]certRsp, len(jdata.DomainIDs))
 	domMap := make(map[string][]int)
 	certMap := make(map[string]*hms_certs.VaultCertData)
@@ -827,7 +1061,11 @@ func doBMCCreateCertsPost(w http.ResponseWriter, r *http.Request) {
 		logger.Tracef("%s: Creating cert for cert domain '%s'", funcName, k)
 
 		vcert := new(hms_certs.VaultCertData)
-		err = hms_certs.CreateCert([]string{k}, domainName, jdata.FQDN, vcert)
+		if hasCreateCertOpts(&jdata) {
+			err = createCustomCert(k, domainName, &jdata, vcert)
+		} else {
+			err = hms_certs.CreateCert([]string{k}, domainName, jdata.FQDN, vcert)
+		}
 		if err != nil {
 			logger.Tracef("%s: ERROR creating cert for '%s': %v",
 				funcName, k, err)
@@ -849,6 +1087,10 @@ func doBMCCreateCertsPost(w http.ResponseWriter, r *http.Request) {
 	for k, _ := range domMap {
 		logger.Tracef("%s: Storing cert data for '%s'.", funcName, k)
 		err := hms_certs.StoreCertData(k, *certMap[k])
+		if err == nil {
+			err = storeCertMeta(k, certMeta{Source: CertSourceCreated,
+				Options: getCreateCertOpts(&jdata)})
+		}
 		if err != nil {
 			logger.Tracef("%s: Cert store for '%s' failed: %v",
 				funcName, k, err)
@@ -931,6 +1173,9 @@ func doBMCDeleteCertsPost(w http.ResponseWriter, r *http.Request) {
 		logger.Tracef("%s: Deleting cert for cert domain '%s'", funcName, k)
 
 		err = hms_certs.DeleteCertData(k, false)
+		if err == nil {
+			err = deleteCertMeta(k)
+		}
 		if err != nil {
 			logger.Tracef("%s: ERROR deleting cert for '%s': %v",
 				funcName, k, err)
@@ -1068,6 +1313,14 @@ func doBMCSetCertsPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	verifyWait, verr := getCertVerifyWait(jdata.VerifyWait)
+	if verr != nil {
+		emsg := fmt.Sprintf("ERROR: %v", verr)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
 	//Verify targets
 
 	td := makeTargData(jdata.Targets)
@@ -1169,6 +1422,11 @@ func doBMCSetCertsPost(w http.ResponseWriter, r *http.Request) {
 		certs[ii].Key = certMap[targ].Data.PrivateKey
 	}
 
+	var servedFPs map[string]string
+	if jdata.Verify {
+		servedFPs = getServedCertFingerprints(tlist)
+	}
+
 	certErr := setCerts(taskList, certs, &retData)
 
 	if certErr != nil {
@@ -1179,6 +1437,14 @@ func doBMCSetCertsPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if jdata.Verify {
+		pushed := make(map[string]string)
+		for ii := 0; ii < len(taskList); ii++ {
+			pushed[targFromTask(&taskList[ii])] = certs[ii].Cert
+		}
+		verifyPushedCerts(&retData, pushed, servedFPs, verifyWait)
+	}
+
 	ba, berr := json.Marshal(&retData)
 	if berr != nil {
 		emsg := fmt.Sprintf("ERROR: Problem marshaling return data.")
@@ -1192,12 +1458,13 @@ func doBMCSetCertsPost(w http.ResponseWriter, r *http.Request) {
 	w.Write(ba)
 }
 
-func getRFPostParams(r *http.Request) (bool, string) {
+func getRFPostParams(r *http.Request) (bool, string, bool) {
 	var qvals []string
 	var ok bool
 
 	force := false
 	cdom := "cabinet"
+	verify := false
 
 	queryValues := r.URL.Query()
 
@@ -1217,7 +1484,15 @@ func getRFPostParams(r *http.Request) (bool, string) {
 		cdom = qvals[0]
 	}
 
-	return force, cdom
+	_, ok = queryValues["Verify"]
+	if !ok {
+		_, ok = queryValues["verify"]
+	}
+	if ok {
+		verify = true
+	}
+
+	return force, cdom, verify
 }
 
 // Fetch leaf cert from Vault and apply it to a single RF target.
@@ -1232,7 +1507,7 @@ func doBMCSetCertsPostSingle(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	targ := xnametypes.NormalizeHMSCompID(vars["xname"])
 
-	force, cdom := getRFPostParams(r)
+	force, cdom, verify := getRFPostParams(r)
 
 	//Verify the target
 
@@ -1307,6 +1582,11 @@ func doBMCSetCertsPostSingle(w http.ResponseWriter, r *http.Request) {
 	certs[0].Cert = vcert.Data.Certificate
 	certs[0].Key = vcert.Data.PrivateKey
 
+	var servedFPs map[string]string
+	if verify {
+		servedFPs = getServedCertFingerprints(tlist)
+	}
+
 	certErr := setCerts(taskList, certs, &retData)
 
 	if certErr != nil {
@@ -1318,6 +1598,12 @@ func doBMCSetCertsPostSingle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if verify {
+		pushed := map[string]string{targFromTask(&taskList[0]): certs[0].Cert}
+		verifyPushedCerts(&retData, pushed, servedFPs,
+			certVerifyWaitDflt*time.Second)
+	}
+
 	ba, berr := json.Marshal(&retData.Targets[0])
 	if berr != nil {
 		emsg := fmt.Sprintf("ERROR: Problem marshaling return data.")
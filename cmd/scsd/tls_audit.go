@@ -0,0 +1,303 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+// Cert consistency audit between Vault and BMCs.  Every BMC known to HSM
+// is mapped to its cert domain ID, and the cert it serves is compared with
+// the cert Vault holds for that domain.
+
+// Used for /v1/bmc/certaudit POST.  If DomainIDs is empty, all BMCs in HSM
+// are audited.  If Repush is set, the Vault certs are applied again to the
+// BMCs which serve some other cert.
+
+type certAuditPost struct {
+	CertDomain string   `json:"CertDomain"`
+	DomainIDs  []string `json:"DomainIDs,omitempty"`
+	Repush     bool     `json:"Repush,omitempty"`
+}
+
+type certAuditRspElem struct {
+	ID               string   `json:"ID"`
+	DomainID         string   `json:"DomainID"`
+	VaultCert        bool     `json:"VaultCert"`
+	Status           string   `json:"Status"`
+	Flagged          bool     `json:"Flagged"`
+	StatusMsg        string   `json:"StatusMsg"`
+	BMCFingerprint   string   `json:"BMCFingerprint,omitempty"`
+	VaultFingerprint string   `json:"VaultFingerprint,omitempty"`
+	Repush           *certRsp `json:"Repush,omitempty"`
+}
+
+type certAuditRsp struct {
+	CertDomain string             `json:"CertDomain"`
+	Flagged    int                `json:"Flagged"`
+	Targets    []certAuditRspElem `json:"Targets"`
+}
+
+// Audit results.  Drifted and missing targets are flagged.
+
+const (
+	CertAuditMatch       = "match"
+	CertAuditDrifted     = "drifted"
+	CertAuditMissing     = "missing"
+	CertAuditUnreachable = "unreachable"
+	CertAuditSkipped     = "skipped"
+)
+
+// Get served cert fingerprints and apply certs; replaceable for testing.
+var auditServedCerts = getServedCertFingerprints
+var repushDomainCert = applyDomainCert
+
+// Vault cert of a cert domain, as far as the audit is concerned.
+
+type auditVaultCert struct {
+	vcert       *hms_certs.VaultCertData
+	fingerprint string
+	err         error
+}
+
+// Fetch the Vault cert of a cert domain.
+//
+// domID(in):      Domain ID, e.g. x1000.
+// certDomain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// Return:         Vault cert info; err is set if there is no usable cert.
+
+func getAuditVaultCert(domID string, certDomain string) auditVaultCert {
+	vcert, err := hms_certs.FetchCertData(domID, certDomain)
+	if err != nil {
+		return auditVaultCert{err: err}
+	}
+	cert, err := parseLeafCert(vcert.Data.Certificate)
+	if err != nil {
+		return auditVaultCert{err: fmt.Errorf("ERROR parsing Vault cert: %v", err)}
+	}
+	return auditVaultCert{vcert: &vcert, fingerprint: certFingerprint(cert)}
+}
+
+// Audit the cert of one BMC.
+//
+// bmc(in):      BMC component from HSM.
+// domID(in):    Cert domain ID of the BMC.
+// vc(in):       Vault cert of the domain.
+// servedFP(in): Fingerprint of the cert served by the BMC, "" if unknown.
+// Return:       Audit result of the BMC.
+
+func auditBMCCert(bmc hsmComponent, domID string, vc auditVaultCert, servedFP string) certAuditRspElem {
+	elm := certAuditRspElem{ID: bmc.ID,
+		DomainID:         domID,
+		VaultCert:        vc.err == nil,
+		BMCFingerprint:   servedFP,
+		VaultFingerprint: vc.fingerprint}
+
+	switch {
+	case vc.err != nil:
+		elm.Status = CertAuditMissing
+		elm.StatusMsg = fmt.Sprintf("No usable Vault cert for domain '%s': %v",
+			domID, vc.err)
+	case !goodHSMState(bmc.State):
+		elm.Status = CertAuditSkipped
+		elm.StatusMsg = fmt.Sprintf("Target '%s' in bad HSM state: %s",
+			bmc.ID, bmc.State)
+	case servedFP == "":
+		elm.Status = CertAuditUnreachable
+		elm.StatusMsg = fmt.Sprintf("Can't get the cert served by '%s'", bmc.ID)
+	case servedFP != vc.fingerprint:
+		elm.Status = CertAuditDrifted
+		elm.StatusMsg = fmt.Sprintf("'%s' does not serve the Vault cert of domain '%s'",
+			bmc.ID, domID)
+	default:
+		elm.Status = CertAuditMatch
+		elm.StatusMsg = "OK"
+	}
+	elm.Flagged = (elm.Status == CertAuditDrifted) || (elm.Status == CertAuditMissing)
+	return elm
+}
+
+// Audit the certs of BMCs grouped by cert domain.
+//
+// domMap(in):     Map of domain ID to the BMCs in the domain.
+// certDomain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// Return:         Audit results, sorted by BMC; Vault certs by domain ID.
+
+func auditCerts(domMap map[string][]hsmComponent, certDomain string) ([]certAuditRspElem, map[string]auditVaultCert) {
+	vcerts := make(map[string]auditVaultCert)
+	var targs []string
+
+	for domID, bmcs := range domMap {
+		vcerts[domID] = getAuditVaultCert(domID, certDomain)
+		if vcerts[domID].err != nil {
+			continue
+		}
+		for _, bmc := range bmcs {
+			if goodHSMState(bmc.State) {
+				targs = append(targs, bmc.ID)
+			}
+		}
+	}
+
+	served := auditServedCerts(targs)
+
+	elms := []certAuditRspElem{}
+	for domID, bmcs := range domMap {
+		for _, bmc := range bmcs {
+			elms = append(elms, auditBMCCert(bmc, domID, vcerts[domID], served[bmc.ID]))
+		}
+	}
+	sort.Slice(elms, func(i, j int) bool { return elms[i].ID < elms[j].ID })
+	return elms, vcerts
+}
+
+// Apply the Vault certs again to the BMCs which serve some other cert.
+//
+// elms(inout): Audit results; drifted targets get the outcome of the push.
+// vcerts(in):  Vault certs by domain ID.
+
+func repushDriftedCerts(elms []certAuditRspElem, vcerts map[string]auditVaultCert) {
+	domTargs := make(map[string][]string)
+	elmMap := make(map[string]*certAuditRspElem)
+	for ix := range elms {
+		if elms[ix].Status == CertAuditDrifted {
+			domTargs[elms[ix].DomainID] = append(domTargs[elms[ix].DomainID], elms[ix].ID)
+			elmMap[elms[ix].ID] = &elms[ix]
+		}
+	}
+
+	for domID, targs := range domTargs {
+		logger.Infof("Cert audit: re-pushing the cert of domain '%s' to %s",
+			domID, strings.Join(targs, ","))
+		rsp, err := repushDomainCert(targs, vcerts[domID].vcert)
+		for ix := range rsp.Targets {
+			if elm, ok := elmMap[rsp.Targets[ix].ID]; ok {
+				elm.Repush = &rsp.Targets[ix]
+			}
+		}
+		for _, targ := range targs {
+			if elmMap[targ].Repush == nil {
+				emsg := "No result from cert push"
+				if err != nil {
+					emsg = fmt.Sprintf("Cert push failed: %v", err)
+				}
+				elmMap[targ].Repush = &certRsp{ID: targ,
+					StatusCode: http.StatusInternalServerError,
+					StatusMsg:  emsg}
+			}
+		}
+	}
+}
+
+// Report, for every BMC of a cert domain, which cert domain ID it maps to,
+// whether Vault has a cert for that domain and whether the BMC serves it.
+// Optionally re-push the Vault certs to the drifted BMCs.
+
+func doBMCCertAuditPost(w http.ResponseWriter, r *http.Request) {
+	var jdata certAuditPost
+	var retData certAuditRsp
+	funcName := "doBMCCertAuditPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	if jdata.CertDomain == "" {
+		jdata.CertDomain = "cabinet"
+	}
+	certDomain, derr := userDomainToCertDomain(jdata.CertDomain)
+	if derr != nil {
+		emsg := fmt.Sprintf("ERROR: %v", derr)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	wantDoms := make(map[string]bool)
+	for _, id := range jdata.DomainIDs {
+		domID, err := hms_certs.CheckDomain([]string{id}, certDomain)
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: ID '%s' not in domain %s: %v",
+				id, jdata.CertDomain, err)
+			sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+				http.StatusBadRequest)
+			return
+		}
+		wantDoms[domID] = true
+	}
+
+	bmcs, err := getHSMBMCs()
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "HSM query failed", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		logger.Errorf("%s: %s", funcName, emsg)
+		return
+	}
+	domMap := groupBMCsByCertDomain(bmcs, certDomain)
+	if len(wantDoms) > 0 {
+		for domID := range domMap {
+			if !wantDoms[domID] {
+				delete(domMap, domID)
+			}
+		}
+	}
+
+	elms, vcerts := auditCerts(domMap, certDomain)
+	if jdata.Repush {
+		repushDriftedCerts(elms, vcerts)
+	}
+
+	retData.CertDomain = jdata.CertDomain
+	retData.Targets = elms
+	for _, elm := range elms {
+		if elm.Flagged {
+			retData.Flagged++
+		}
+	}
+
+	ba, baerr := json.Marshal(&retData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR marshalling response data: %v", baerr)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
@@ -0,0 +1,167 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+func TestAuditBMCCert(t *testing.T) {
+	good := auditVaultCert{fingerprint: "aaaa"}
+	none := auditVaultCert{err: fmt.Errorf("Key does not exist")}
+	tests := []struct {
+		state    string
+		vc       auditVaultCert
+		servedFP string
+		status   string
+		flagged  bool
+	}{
+		{"Ready", good, "aaaa", CertAuditMatch, false},
+		{"On", good, "bbbb", CertAuditDrifted, true},
+		{"Ready", good, "", CertAuditUnreachable, false},
+		{"Off", good, "", CertAuditSkipped, false},
+		{"Ready", none, "bbbb", CertAuditMissing, true},
+		{"Off", none, "", CertAuditMissing, true},
+	}
+
+	for ix, tt := range tests {
+		elm := auditBMCCert(hsmComponent{ID: "x1000c0s0b0", State: tt.state},
+			"x1000", tt.vc, tt.servedFP)
+		if (elm.Status != tt.status) || (elm.Flagged != tt.flagged) {
+			t.Errorf("Test %d: expected %s/%t, got %s/%t (%s)", ix,
+				tt.status, tt.flagged, elm.Status, elm.Flagged, elm.StatusMsg)
+		}
+		if (elm.DomainID != "x1000") || (elm.VaultCert != (tt.vc.err == nil)) {
+			t.Errorf("Test %d: bad domain info: %+v", ix, elm)
+		}
+	}
+}
+
+func TestDoBMCCertAuditPost(t *testing.T) {
+	loggerSetup()
+	savedURL := appParams.SmdURL
+	savedServed, savedRepush := auditServedCerts, repushDomainCert
+	defer func() {
+		appParams.SmdURL = savedURL
+		auditServedCerts, repushDomainCert = savedServed, savedRepush
+	}()
+
+	os.Setenv("VAULT_ENABLE", "0")
+	defer os.Unsetenv("VAULT_ENABLE")
+	hms_certs.Init(nil)
+
+	smServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Components":[{"ID":"x5c0s1b0","State":"Ready"},{"ID":"x5c0s2b0","State":"Ready"},{"ID":"x5c0s3b0","State":"Off"},{"ID":"x6c0s1b0","State":"Ready"},{"ID":"x7c0s1b0","State":"Ready"},{"ID":"x8c0s1b0","State":"Ready"}]}`))
+	}))
+	defer smServer.Close()
+	appParams.SmdURL = smServer.URL
+
+	//x5 and x6 have Vault certs, x7 has none.  x5c0s1b0 serves the x5
+	//cert, x5c0s2b0 some other cert and x6c0s1b0 can't be reached.
+
+	x5cert, x5PEM, _ := makeTestCert(t, "x5", 365*24*time.Hour)
+	_, x6PEM, _ := makeTestCert(t, "x6", 365*24*time.Hour)
+	other, _, _ := makeTestCert(t, "x5", 365*24*time.Hour)
+	hms_certs.StoreCertData("x5", hms_certs.VaultCertData{Data: hms_certs.CertInfo{Certificate: x5PEM}})
+	defer hms_certs.DeleteCertData("x5", true)
+	hms_certs.StoreCertData("x6", hms_certs.VaultCertData{Data: hms_certs.CertInfo{Certificate: x6PEM}})
+	defer hms_certs.DeleteCertData("x6", true)
+
+	var handshakes []string
+	auditServedCerts = func(targets []string) map[string]string {
+		handshakes = targets
+		return map[string]string{"x5c0s1b0": certFingerprint(x5cert),
+			"x5c0s2b0": certFingerprint(other),
+			"x7c0s1b0": certFingerprint(other)}
+	}
+	var pushed []string
+	repushDomainCert = func(targs []string, vcert *hms_certs.VaultCertData) (rfCertPostRsp, error) {
+		pushed = append(pushed, targs...)
+		if vcert.Data.Certificate != x5PEM {
+			t.Errorf("Wrong cert pushed to %v", targs)
+		}
+		rsp := rfCertPostRsp{}
+		for _, targ := range targs {
+			rsp.Targets = append(rsp.Targets, certRsp{ID: targ,
+				StatusCode: http.StatusOK, StatusMsg: "OK"})
+		}
+		return rsp, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, API_CERT_AUDIT,
+		strings.NewReader(`{"CertDomain":"Cabinet","DomainIDs":["x5","x6c0s1b0","x7"],"Repush":true}`))
+	rr := httptest.NewRecorder()
+	doBMCCertAuditPost(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Bad status code: %d, %s", rr.Code, rr.Body.String())
+	}
+
+	var rsp certAuditRsp
+	json.Unmarshal(rr.Body.Bytes(), &rsp)
+	exp := []struct {
+		id     string
+		status string
+		repush bool
+	}{
+		{"x5c0s1b0", CertAuditMatch, false},
+		{"x5c0s2b0", CertAuditDrifted, true},
+		{"x5c0s3b0", CertAuditSkipped, false},
+		{"x6c0s1b0", CertAuditUnreachable, false},
+		{"x7c0s1b0", CertAuditMissing, false},
+	}
+	if len(rsp.Targets) != len(exp) {
+		t.Fatalf("Expected %d targets, got %v", len(exp), rsp.Targets)
+	}
+	for ix, ee := range exp {
+		elm := rsp.Targets[ix]
+		if (elm.ID != ee.id) || (elm.Status != ee.status) || ((elm.Repush != nil) != ee.repush) {
+			t.Errorf("Expected %s/%s/%t, got %+v", ee.id, ee.status, ee.repush, elm)
+		}
+	}
+	if rsp.Flagged != 2 {
+		t.Errorf("Expected 2 flagged targets, got %d", rsp.Flagged)
+	}
+	if (len(pushed) != 1) || (pushed[0] != "x5c0s2b0") {
+		t.Errorf("Expected a re-push to x5c0s2b0 only, got %v", pushed)
+	}
+	if len(handshakes) != 3 {
+		t.Errorf("Expected handshakes with 3 BMCs, got %v", handshakes)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, API_CERT_AUDIT,
+		strings.NewReader(`{"CertDomain":"Rack"}`))
+	rr = httptest.NewRecorder()
+	doBMCCertAuditPost(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad cert domain, got %d", rr.Code)
+	}
+}
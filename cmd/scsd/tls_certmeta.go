@@ -0,0 +1,124 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SCSD's own data about the BMC certs in secure storage, kept apart from
+// the cert data, which belongs to hms_certs.  It is keyed by cert domain ID,
+// and kept in Vault under VaultCertMetaKeypath when Vault is enabled, in
+// memory otherwise, the same as the certs.
+
+var VaultCertMetaKeypath = "secret/hms-cert-meta"
+
+// Cert sources
+
+const (
+	CertSourceCreated  = "created"  // created by SCSD, renewable
+	CertSourceUploaded = "uploaded" // uploaded with /bmc/uploadcerts
+)
+
+type certMeta struct {
+	Source  string          `json:"Source"`
+	Options *certCreateOpts `json:"Options,omitempty"` // custom createcerts options
+}
+
+var certMetaLock sync.Mutex
+var certMetas = make(map[string]certMeta)
+
+func certMetaInVault() bool {
+	return (appParams.VaultEnable != nil) && *appParams.VaultEnable
+}
+
+// Store the data of the cert of a domain.
+//
+// domID(in): Cert domain ID, e.g. x1000.
+// meta(in):  Cert data to store.
+// Return:    nil on success, else error.
+
+func storeCertMeta(domID string, meta certMeta) error {
+	if !certMetaInVault() {
+		certMetaLock.Lock()
+		certMetas[domID] = meta
+		certMetaLock.Unlock()
+		return nil
+	}
+	if secureStore == nil {
+		return fmt.Errorf("Vault not connected, can't store cert data of '%s'", domID)
+	}
+	err := secureStore.Store(VaultCertMetaKeypath+"/"+domID, meta)
+	if err != nil {
+		return fmt.Errorf("Can't store cert data of '%s' in Vault: %v", domID, err)
+	}
+	return nil
+}
+
+// Fetch the data of the cert of a domain.  Certs stored without any, such
+// as those created before it was kept, get an empty Source.
+//
+// domID(in): Cert domain ID, e.g. x1000.
+// Return:    Cert data; error if it could not be read.
+
+func fetchCertMeta(domID string) (certMeta, error) {
+	var meta certMeta
+
+	if !certMetaInVault() {
+		certMetaLock.Lock()
+		meta = certMetas[domID]
+		certMetaLock.Unlock()
+		return meta, nil
+	}
+	if secureStore == nil {
+		return meta, fmt.Errorf("Vault not connected, can't fetch cert data of '%s'", domID)
+	}
+	err := secureStore.Lookup(VaultCertMetaKeypath+"/"+domID, &meta)
+	if err != nil {
+		return meta, fmt.Errorf("Can't fetch cert data of '%s' from Vault: %v", domID, err)
+	}
+	return meta, nil
+}
+
+// Delete the data of the cert of a domain.  No data is not an error.
+//
+// domID(in): Cert domain ID, e.g. x1000.
+// Return:    nil on success, else error.
+
+func deleteCertMeta(domID string) error {
+	if !certMetaInVault() {
+		certMetaLock.Lock()
+		delete(certMetas, domID)
+		certMetaLock.Unlock()
+		return nil
+	}
+	if secureStore == nil {
+		return fmt.Errorf("Vault not connected, can't delete cert data of '%s'", domID)
+	}
+	err := secureStore.Delete(VaultCertMetaKeypath + "/" + domID)
+	if err != nil {
+		return fmt.Errorf("Can't delete cert data of '%s' from Vault: %v", domID, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCertMeta(t *testing.T) {
+	savedVault := appParams.VaultEnable
+	defer func() { appParams.VaultEnable = savedVault }()
+	appParams.VaultEnable = nil
+
+	meta, err := fetchCertMeta("x9")
+	if (err != nil) || (meta.Source != "") {
+		t.Errorf("Expected no cert data, got %v %v", meta, err)
+	}
+	storeCertMeta("x9", certMeta{Source: CertSourceUploaded})
+	meta, err = fetchCertMeta("x9")
+	if (err != nil) || (meta.Source != CertSourceUploaded) {
+		t.Errorf("Expected the stored cert data, got %v %v", meta, err)
+	}
+	deleteCertMeta("x9")
+	if meta, _ = fetchCertMeta("x9"); meta.Source != "" {
+		t.Errorf("Expected the cert data to be deleted, got %v", meta)
+	}
+
+	//Vault enabled but not connected
+
+	savedStore := secureStore
+	defer func() { secureStore = savedStore }()
+	secureStore = nil
+	vaultOn := true
+	appParams.VaultEnable = &vaultOn
+	if _, err = fetchCertMeta("x9"); err == nil {
+		t.Errorf("Expected an error without a Vault connection")
+	}
+	if err = storeCertMeta("x9", certMeta{Source: CertSourceCreated}); err == nil {
+		t.Errorf("Expected an error without a Vault connection")
+	}
+}
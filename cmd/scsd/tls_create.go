@@ -0,0 +1,444 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+// Custom cert options for /v1/bmc/createcerts.  hms_certs.CreateCert() only
+// knows about the domain SANs, an FQDN and a fixed TTL, and lets Vault
+// generate the key with the PKI role's defaults.  If any of the extra
+// SAN, key or validity options are given, SCSD generates the key pair
+// itself, puts all of the SANs into a CSR and has the Vault PKI sign it.
+
+const (
+	certKeyTypeRSA      = "RSA"
+	certKeyTypeECDSA    = "ECDSA"
+	certValidityDflt    = 365 //days, same as hms_certs.CreateCert()
+	certValiditySlop    = time.Hour
+	certMaxCabChassis   = 8 //These match hms_certs
+	certMaxChassisSlot  = 8
+	certMaxRVChassisSlt = 64
+	certMaxSlotBMC      = 8
+)
+
+// Allowed key sizes, the first one is the default.
+
+var certKeyBits = map[string][]int{certKeyTypeRSA: {2048, 3072, 4096},
+	certKeyTypeECDSA: {256, 384, 521}}
+
+var certDNSNameRE = regexp.MustCompile(`^(\*\.)?[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+
+// Signs createcerts CSRs, replaceable for testing.
+var signCreateCSR = vaultSignCSR
+
+// Custom cert options a cert was created with, stored with the cert so
+// that renewal creates the same kind of cert.  The FQDN is kept in the
+// cert data.
+
+type certCreateOpts struct {
+	AltNames     []string `json:"AltNames,omitempty"`
+	IPAddresses  []string `json:"IPAddresses,omitempty"`
+	KeyType      string   `json:"KeyType,omitempty"`
+	KeyBits      int      `json:"KeyBits,omitempty"`
+	ValidityDays int      `json:"ValidityDays,omitempty"`
+}
+
+// Returns true if any custom cert options are present in a createcerts
+// request.
+
+func hasCreateCertOpts(jdata *bmcManageCertPost) bool {
+	return (len(jdata.AltNames) > 0) || (len(jdata.IPAddresses) > 0) ||
+		(jdata.KeyType != "") || (jdata.KeyBits != 0) ||
+		(jdata.ValidityDays != 0)
+}
+
+// Returns the custom cert options of a createcerts request, nil if there
+// are none.
+
+func getCreateCertOpts(jdata *bmcManageCertPost) *certCreateOpts {
+	if !hasCreateCertOpts(jdata) {
+		return nil
+	}
+	return &certCreateOpts{AltNames: jdata.AltNames,
+		IPAddresses:  jdata.IPAddresses,
+		KeyType:      jdata.KeyType,
+		KeyBits:      jdata.KeyBits,
+		ValidityDays: jdata.ValidityDays,
+	}
+}
+
+// Returns a createcerts request with stored custom cert options.
+//
+// opts(in): Custom cert options.
+// fqdn(in): FQDN from the cert data, may be empty.
+// Return:   createcerts request to pass to createCustomCert().
+
+func (opts *certCreateOpts) toCreatePost(fqdn string) *bmcManageCertPost {
+	return &bmcManageCertPost{FQDN: fqdn,
+		AltNames:     opts.AltNames,
+		IPAddresses:  opts.IPAddresses,
+		KeyType:      opts.KeyType,
+		KeyBits:      opts.KeyBits,
+		ValidityDays: opts.ValidityDays,
+	}
+}
+
+// Returns the normalized key type and key size to use for a createcerts
+// request, filling in defaults.
+
+func createCertKeyParams(jdata *bmcManageCertPost) (string, int, error) {
+	keyType := strings.ToUpper(jdata.KeyType)
+	if keyType == "" {
+		keyType = certKeyTypeRSA
+	}
+	sizes, ok := certKeyBits[keyType]
+	if !ok {
+		return "", 0, fmt.Errorf("Invalid KeyType '%s', must be %s or %s",
+			jdata.KeyType, certKeyTypeRSA, certKeyTypeECDSA)
+	}
+	if jdata.KeyBits == 0 {
+		return keyType, sizes[0], nil
+	}
+	for _, sz := range sizes {
+		if jdata.KeyBits == sz {
+			return keyType, sz, nil
+		}
+	}
+	return "", 0, fmt.Errorf("Invalid KeyBits %d for %s keys, must be one of %v",
+		jdata.KeyBits, keyType, sizes)
+}
+
+// Check the custom cert options of a createcerts request.
+
+func validateCreateCertOpts(jdata *bmcManageCertPost) error {
+	for _, name := range jdata.AltNames {
+		if !certDNSNameRE.MatchString(name) {
+			return fmt.Errorf("Invalid DNS name in AltNames: '%s'", name)
+		}
+	}
+	for _, ip := range jdata.IPAddresses {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("Invalid IP address in IPAddresses: '%s'", ip)
+		}
+	}
+	_, _, err := createCertKeyParams(jdata)
+	if err != nil {
+		return err
+	}
+	if jdata.ValidityDays < 0 {
+		return fmt.Errorf("Invalid ValidityDays %d, must be > 0",
+			jdata.ValidityDays)
+	}
+	return nil
+}
+
+// Generate all possible BMC names in a cert domain.  This is the same
+// list hms_certs.CreateCert() puts into its certs.
+//
+// domID(in):  Domain XName, e.g. "x1000" for a cabinet domain.
+// domain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// Return:     Names; error if the domain is not valid.
+
+func domainAltNames(domID string, domain string) ([]string, error) {
+	var eps []string
+
+	switch domain {
+	case hms_certs.CertDomainCabinet:
+		eps = append(eps, domID+"c0")
+		for slot := 0; slot < 4; slot++ {
+			eps = append(eps, fmt.Sprintf("%sm%d", domID, slot))
+			eps = append(eps, fmt.Sprintf("%sm%d-rts", domID, slot))
+		}
+		for slot := 0; slot < certMaxRVChassisSlt; slot++ {
+			for bmc := 0; bmc < certMaxSlotBMC; bmc++ {
+				eps = append(eps, fmt.Sprintf("%sc0s%db%d", domID, slot, bmc))
+				eps = append(eps, fmt.Sprintf("%sc0r%db%d", domID, slot, bmc))
+			}
+		}
+		for chassis := 1; chassis < certMaxCabChassis; chassis++ {
+			eps = append(eps, fmt.Sprintf("%sc%d", domID, chassis))
+			for slot := 0; slot < certMaxChassisSlot; slot++ {
+				for bmc := 0; bmc < certMaxSlotBMC; bmc++ {
+					eps = append(eps, fmt.Sprintf("%sc%ds%db%d",
+						domID, chassis, slot, bmc))
+					eps = append(eps, fmt.Sprintf("%sc%dr%db%d",
+						domID, chassis, slot, bmc))
+				}
+			}
+		}
+
+	case hms_certs.CertDomainChassis:
+		toks := strings.Split(domID, "c")
+		if len(toks) < 2 {
+			return nil, fmt.Errorf("Invalid chassis name: '%s' (missing 'c')",
+				domID)
+		}
+		maxSlot := certMaxChassisSlot
+		if cid, _ := strconv.Atoi(toks[1]); cid == 0 {
+			maxSlot = certMaxRVChassisSlt
+		}
+		for slot := 0; slot < maxSlot; slot++ {
+			for bmc := 0; bmc < certMaxSlotBMC; bmc++ {
+				eps = append(eps, fmt.Sprintf("%ss%db%d", domID, slot, bmc))
+				eps = append(eps, fmt.Sprintf("%sr%db%d", domID, slot, bmc))
+			}
+		}
+
+	case hms_certs.CertDomainBlade:
+		for bmc := 0; bmc < certMaxSlotBMC; bmc++ {
+			eps = append(eps, fmt.Sprintf("%sb%d", domID, bmc))
+		}
+
+	case hms_certs.CertDomainBMC:
+		eps = append(eps, strings.Split(domID, "n")[0])
+
+	default:
+		return nil, fmt.Errorf("Invalid cert domain: %s", domain)
+	}
+
+	return eps, nil
+}
+
+// Returns the DNS SANs and IP SANs of a custom createcerts cert.  The
+// domain names get the FQDN appended the same way hms_certs.CreateCert()
+// does it; extra AltNames are used as-is.
+
+func createCertSANs(domID string, domain string, jdata *bmcManageCertPost) ([]string, []net.IP, error) {
+	names, err := domainAltNames(domID, domain)
+	if err != nil {
+		return nil, nil, err
+	}
+	if jdata.FQDN != "" {
+		fqdn := "." + strings.TrimLeft(jdata.FQDN, ".")
+		for ix := range names {
+			names[ix] += fqdn
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[strings.ToLower(name)] = true
+	}
+	for _, name := range jdata.AltNames {
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+
+	var ips []net.IP
+	for _, ipstr := range jdata.IPAddresses {
+		ip := net.ParseIP(ipstr)
+		dup := false
+		for _, have := range ips {
+			if have.Equal(ip) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			ips = append(ips, ip)
+		}
+	}
+	return names, ips, nil
+}
+
+// Generate a private key.
+//
+// keyType(in): certKeyTypeRSA or certKeyTypeECDSA.
+// keyBits(in): Key size.
+// Return:      Private key; PEM encoded key in the same format Vault uses;
+//              error on failure.
+
+func genCertKey(keyType string, keyBits int) (crypto.Signer, string, error) {
+	if keyType == certKeyTypeRSA {
+		key, err := rsa.GenerateKey(rand.Reader, keyBits)
+		if err != nil {
+			return nil, "", err
+		}
+		kpem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		return key, string(kpem), nil
+	}
+
+	var curve elliptic.Curve
+	switch keyBits {
+	case 256:
+		curve = elliptic.P256()
+	case 384:
+		curve = elliptic.P384()
+	case 521:
+		curve = elliptic.P521()
+	default:
+		return nil, "", fmt.Errorf("Unsupported ECDSA key size %d", keyBits)
+	}
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	kpem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return key, string(kpem), nil
+}
+
+// Check that a signed cert honors the requested options.  The Vault PKI
+// role can drop SANs it doesn't allow or cap the TTL, in which case the
+// cert is not what was asked for.
+//
+// certPEM(in):  Signed cert.
+// key(in):      Private key the cert must match.
+// names(in):    DNS SANs the cert must have.
+// ips(in):      IP SANs the cert must have.
+// notAfter(in): Requested expiration time.
+// Return:       nil if the cert is OK; error if not.
+
+func checkCreatedCert(certPEM string, key crypto.Signer, names []string, ips []net.IP, notAfter time.Time) error {
+	certs, _, err := parsePEMCerts(certPEM)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return fmt.Errorf("No cert found in the signed data")
+	}
+	cert := certs[0]
+
+	pub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(key.Public()) {
+		return fmt.Errorf("Signed cert does not match the generated key")
+	}
+
+	have := make(map[string]bool)
+	for _, name := range cert.DNSNames {
+		have[strings.ToLower(name)] = true
+	}
+	for _, name := range names {
+		if !have[strings.ToLower(name)] {
+			return fmt.Errorf("Signed cert is missing SAN '%s'", name)
+		}
+	}
+	for _, ip := range ips {
+		found := false
+		for _, cip := range cert.IPAddresses {
+			if cip.Equal(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Signed cert is missing IP SAN '%s'", ip)
+		}
+	}
+	if cert.NotAfter.Before(notAfter.Add(-certValiditySlop)) {
+		return fmt.Errorf("Signed cert expires %s, before the requested %s",
+			cert.NotAfter.UTC().Format(time.RFC3339),
+			notAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Create a cert with custom SANs, key parameters and validity period for a
+// cert domain.  The key is generated locally and the CSR is signed by the
+// Vault PKI.
+//
+// domID(in):    Domain XName, e.g. "x1000" for a cabinet domain.
+// domain(in):   Cert domain, e.g. hms_certs.CertDomainCabinet.
+// jdata(in):    createcerts request.
+// retData(out): Cert data, ready to be stored.
+// Return:       nil on success; error on failure.
+
+func createCustomCert(domID string, domain string, jdata *bmcManageCertPost, retData *hms_certs.VaultCertData) error {
+	keyType, keyBits, err := createCertKeyParams(jdata)
+	if err != nil {
+		return err
+	}
+	days := jdata.ValidityDays
+	if days == 0 {
+		days = certValidityDflt
+	}
+	names, ips, err := createCertSANs(domID, domain, jdata)
+	if err != nil {
+		return err
+	}
+
+	key, keyPEM, err := genCertKey(keyType, keyBits)
+	if err != nil {
+		return fmt.Errorf("ERROR generating %s-%d key: %v", keyType, keyBits, err)
+	}
+	tmpl := x509.CertificateRequest{Subject: pkix.Name{CommonName: domID},
+		DNSNames:    names,
+		IPAddresses: ips}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &tmpl, key)
+	if err != nil {
+		return fmt.Errorf("ERROR creating CSR: %v", err)
+	}
+	csr := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+
+	var ipStrs []string
+	for _, ip := range ips {
+		ipStrs = append(ipStrs, ip.String())
+	}
+	ttl := time.Duration(days) * 24 * time.Hour
+	notAfter := time.Now().Add(ttl)
+	req := vaultSignReq{CSR: csr,
+		CommonName: domID,
+		AltNames:   strings.Join(names, ","),
+		IPSANs:     strings.Join(ipStrs, ","),
+		TTL:        fmt.Sprintf("%dh", days*24)}
+	rsp, err := signCreateCSR(req)
+	if err != nil {
+		return err
+	}
+
+	err = checkCreatedCert(rsp.Data.Certificate, key, names, ips, notAfter)
+	if err != nil {
+		return err
+	}
+
+	*retData = *rsp
+	retData.Data.PrivateKey = keyPEM
+	retData.Data.PrivateKeyType = privateKeyType(key)
+	if jdata.FQDN != "" {
+		retData.Data.FQDN = "." + strings.TrimLeft(jdata.FQDN, ".")
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+// Fake Vault PKI signer.  Honors the CSR SANs and TTL unless told to drop
+// the IP SANs or cap the TTL, like a restrictive PKI role would.
+
+type fakeCreateSigner struct {
+	ca      *testCA
+	dropIPs bool
+	maxTTL  time.Duration
+	req     vaultSignReq
+}
+
+func (f *fakeCreateSigner) sign(req vaultSignReq) (*hms_certs.VaultCertData, error) {
+	f.req = req
+	block, _ := pem.Decode([]byte(req.CSR))
+	if block == nil {
+		return nil, fmt.Errorf("no CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := time.ParseDuration(req.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if (f.maxTTL != 0) && (ttl > f.maxTTL) {
+		ttl = f.maxTTL
+	}
+	tmpl := x509.Certificate{SerialNumber: big.NewInt(1234),
+		Subject:   csr.Subject,
+		NotBefore: time.Now().Add(-time.Minute),
+		NotAfter:  time.Now().Add(ttl),
+		DNSNames:  csr.DNSNames,
+	}
+	if !f.dropIPs {
+		tmpl.IPAddresses = csr.IPAddresses
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, f.ca.cert, csr.PublicKey, f.ca.key)
+	if err != nil {
+		return nil, err
+	}
+	var rsp hms_certs.VaultCertData
+	rsp.Data.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	rsp.Data.IssuingCA = f.ca.pem
+	rsp.Data.CAChain = []string{f.ca.pem}
+	rsp.Data.SerialNumber = "04:d2"
+	return &rsp, nil
+}
+
+func TestValidateCreateCertOpts(t *testing.T) {
+	tests := []struct {
+		jdata bmcManageCertPost
+		ok    bool
+		opts  bool
+	}{
+		{bmcManageCertPost{Domain: "BMC"}, true, false},
+		{bmcManageCertPost{FQDN: "hmn"}, true, false},
+		{bmcManageCertPost{AltNames: []string{"bmc1", "bmc1.site.com", "*.site.com"}}, true, true},
+		{bmcManageCertPost{AltNames: []string{"bmc1,bmc2"}}, false, true},
+		{bmcManageCertPost{AltNames: []string{""}}, false, true},
+		{bmcManageCertPost{IPAddresses: []string{"10.1.2.3", "fd00::1"}}, true, true},
+		{bmcManageCertPost{IPAddresses: []string{"10.1.2"}}, false, true},
+		{bmcManageCertPost{KeyType: "ecdsa"}, true, true},
+		{bmcManageCertPost{KeyType: "ECDSA", KeyBits: 384}, true, true},
+		{bmcManageCertPost{KeyType: "ECDSA", KeyBits: 2048}, false, true},
+		{bmcManageCertPost{KeyBits: 4096}, true, true},
+		{bmcManageCertPost{KeyBits: 1024}, false, true},
+		{bmcManageCertPost{KeyType: "DSA"}, false, true},
+		{bmcManageCertPost{ValidityDays: 90}, true, true},
+		{bmcManageCertPost{ValidityDays: -1}, false, true},
+	}
+
+	for ix, tt := range tests {
+		err := validateCreateCertOpts(&tt.jdata)
+		if (err == nil) != tt.ok {
+			t.Errorf("Test %d: expected ok=%t, got error %v", ix, tt.ok, err)
+		}
+		if hasCreateCertOpts(&tt.jdata) != tt.opts {
+			t.Errorf("Test %d: expected custom options %t", ix, tt.opts)
+		}
+	}
+}
+
+func TestDomainAltNames(t *testing.T) {
+	tests := []struct {
+		domID  string
+		domain string
+		count  int
+		has    string
+	}{
+		{"x1000c0s0b0", hms_certs.CertDomainBMC, 1, "x1000c0s0b0"},
+		{"x1000c1s3", hms_certs.CertDomainBlade, 8, "x1000c1s3b7"},
+		{"x1000c0", hms_certs.CertDomainChassis, 1024, "x1000c0r63b7"},
+		{"x1000c3", hms_certs.CertDomainChassis, 128, "x1000c3s7b0"},
+		{"x1000", hms_certs.CertDomainCabinet, 9 + 1024 + 7*129, "x1000m3-rts"},
+	}
+
+	for _, tt := range tests {
+		names, err := domainAltNames(tt.domID, tt.domain)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.domID, err)
+			continue
+		}
+		if len(names) != tt.count {
+			t.Errorf("%s: expected %d names, got %d", tt.domID, tt.count, len(names))
+		}
+		found := false
+		for _, name := range names {
+			if name == tt.has {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: missing name '%s'", tt.domID, tt.has)
+		}
+	}
+
+	_, err := domainAltNames("x1000", "bogus")
+	if err == nil {
+		t.Errorf("Expected an error for a bad domain")
+	}
+}
+
+func TestCreateCustomCert(t *testing.T) {
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Test PKI CA", nil, true, year, nil)
+	fs := &fakeCreateSigner{ca: root}
+	saveSigner := signCreateCSR
+	signCreateCSR = fs.sign
+	defer func() { signCreateCSR = saveSigner }()
+
+	jdata := bmcManageCertPost{FQDN: "hmn",
+		AltNames:     []string{"bmc-s0", "x1000c0s0b0.hmn"},
+		IPAddresses:  []string{"10.254.1.5", "10.254.1.5"},
+		KeyType:      "ecdsa",
+		KeyBits:      384,
+		ValidityDays: 30}
+	var vcert hms_certs.VaultCertData
+	err := createCustomCert("x1000c0s0b0", hms_certs.CertDomainBMC, &jdata, &vcert)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fs.req.TTL != "720h" {
+		t.Errorf("Expected TTL 720h, got '%s'", fs.req.TTL)
+	}
+	if fs.req.AltNames != "x1000c0s0b0.hmn,bmc-s0" {
+		t.Errorf("Bad alt names: '%s'", fs.req.AltNames)
+	}
+	if fs.req.IPSANs != "10.254.1.5" {
+		t.Errorf("Bad IP SANs: '%s'", fs.req.IPSANs)
+	}
+	cert, err := parseLeafCert(vcert.Data.Certificate)
+	if err != nil {
+		t.Fatalf("Can't parse cert: %v", err)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || (pub.Curve.Params().BitSize != 384) {
+		t.Errorf("Expected an ECDSA P-384 key, got %T", cert.PublicKey)
+	}
+	if (len(cert.IPAddresses) != 1) || !cert.IPAddresses[0].Equal(net.ParseIP("10.254.1.5")) {
+		t.Errorf("Bad cert IP SANs: %v", cert.IPAddresses)
+	}
+	if (vcert.Data.PrivateKeyType != "ec") || (vcert.Data.FQDN != ".hmn") ||
+		(vcert.Data.IssuingCA != root.pem) {
+		t.Errorf("Bad cert data: %+v", vcert.Data)
+	}
+	_, err = tls.X509KeyPair([]byte(vcert.Data.Certificate), []byte(vcert.Data.PrivateKey))
+	if err != nil {
+		t.Errorf("Cert and key don't match: %v", err)
+	}
+
+	//Defaults: RSA 2048, 1 year
+
+	jdata = bmcManageCertPost{AltNames: []string{"bmc-s0"}}
+	err = createCustomCert("x1000c0s0b0", hms_certs.CertDomainBMC, &jdata, &vcert)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cert, _ = parseLeafCert(vcert.Data.Certificate)
+	rpub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok || (rpub.N.BitLen() != 2048) || (fs.req.TTL != "8760h") {
+		t.Errorf("Expected an RSA-2048 key and 8760h TTL, got %T/%s",
+			cert.PublicKey, fs.req.TTL)
+	}
+
+	//PKI role that drops IP SANs or caps the TTL
+
+	fs.dropIPs = true
+	jdata = bmcManageCertPost{IPAddresses: []string{"10.254.1.5"}, KeyType: "ECDSA"}
+	err = createCustomCert("x1000c0s0b0", hms_certs.CertDomainBMC, &jdata, &vcert)
+	if (err == nil) || !strings.Contains(err.Error(), "IP SAN") {
+		t.Errorf("Expected a missing IP SAN error, got %v", err)
+	}
+	fs.dropIPs = false
+	fs.maxTTL = 24 * time.Hour
+	jdata = bmcManageCertPost{ValidityDays: 10, KeyType: "ECDSA"}
+	err = createCustomCert("x1000c0s0b0", hms_certs.CertDomainBMC, &jdata, &vcert)
+	if (err == nil) || !strings.Contains(err.Error(), "expires") {
+		t.Errorf("Expected a capped TTL error, got %v", err)
+	}
+}
+
+func TestDoBMCCreateCertsPostOpts(t *testing.T) {
+	loggerSetup()
+	os.Setenv("VAULT_ENABLE", "0")
+	defer os.Unsetenv("VAULT_ENABLE")
+	hms_certs.Init(nil)
+	savedVault := appParams.VaultEnable
+	defer func() { appParams.VaultEnable = savedVault }()
+	appParams.VaultEnable = nil
+
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Test PKI CA", nil, true, year, nil)
+	fs := &fakeCreateSigner{ca: root}
+	saveSigner := signCreateCSR
+	signCreateCSR = fs.sign
+	defer func() { signCreateCSR = saveSigner }()
+	defer hms_certs.DeleteCertData("x1000c0s0", true)
+	defer deleteCertMeta("x1000c0s0")
+
+	req := httptest.NewRequest(http.MethodPost, API_CRT_CERTS,
+		strings.NewReader(`{"Domain":"Blade","DomainIDs":["x1000c0s0b0","x1000c0s0b1"],"IPAddresses":["10.254.1.5"],"KeyType":"ECDSA"}`))
+	rr := httptest.NewRecorder()
+	doBMCCreateCertsPost(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Bad status code: %d, %s", rr.Code, rr.Body.String())
+	}
+	var rsp bmcManageCertPostRsp
+	json.Unmarshal(rr.Body.Bytes(), &rsp)
+	for _, dr := range rsp.DomainIDs {
+		if dr.StatusCode != http.StatusOK {
+			t.Errorf("%s: bad status %d/%s", dr.ID, dr.StatusCode, dr.StatusMsg)
+		}
+	}
+
+	vcert, err := hms_certs.FetchCertData("x1000c0s0", hms_certs.CertDomainBlade)
+	if err != nil {
+		t.Fatalf("Cert not stored: %v", err)
+	}
+	cert, err := parseLeafCert(vcert.Data.Certificate)
+	if (err != nil) || (len(cert.IPAddresses) != 1) || (len(cert.DNSNames) != 8) {
+		t.Errorf("Stored cert doesn't have the requested SANs: %v", err)
+	}
+	meta, err := fetchCertMeta("x1000c0s0")
+	if (err != nil) || (meta.Source != CertSourceCreated) || (meta.Options == nil) ||
+		(meta.Options.KeyType != "ECDSA") || (len(meta.Options.IPAddresses) != 1) {
+		t.Errorf("Expected the cert options to be stored with the cert, got %+v %v", meta, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, API_CRT_CERTS,
+		strings.NewReader(`{"Domain":"Blade","DomainIDs":["x1000c0s0b0"],"KeyType":"DSA"}`))
+	rr = httptest.NewRecorder()
+	doBMCCreateCertsPost(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad key type, got %d", rr.Code)
+	}
+}
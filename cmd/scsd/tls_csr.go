@@ -0,0 +1,635 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+// CSR based BMC certs.  The BMC generates the key pair and a CSR, the CSR
+// is signed by the Vault PKI used by hms_certs, and the signed cert is
+// installed on the BMC.  The private key never leaves the BMC and nothing
+// is stored in Vault.
+
+// Used for /v1/bmc/csrcerts POST.  The subject fields are required by the
+// Redfish and HPE GenerateCSR actions.
+
+type bmcCSRCertPost struct {
+	Force              bool     `json:"Force"`
+	Targets            []string `json:"Targets"`
+	FQDN               string   `json:"FQDN,omitempty"`
+	Country            string   `json:"Country"`
+	State              string   `json:"State"`
+	City               string   `json:"City"`
+	Organization       string   `json:"Organization"`
+	OrganizationalUnit string   `json:"OrganizationalUnit"`
+	KeyPairAlgorithm   string   `json:"KeyPairAlgorithm,omitempty"`
+	KeyBitLength       int      `json:"KeyBitLength,omitempty"`
+	Verify             bool     `json:"Verify,omitempty"`
+	VerifyWait         int      `json:"VerifyWait,omitempty"` //seconds
+}
+
+// Redfish CertificateService.GenerateCSR payload and response
+
+type rfGenerateCSR struct {
+	CertificateCollection CertificateURI `json:"CertificateCollection"`
+	CommonName            string         `json:"CommonName"`
+	AlternativeNames      []string       `json:"AlternativeNames,omitempty"`
+	Country               string         `json:"Country"`
+	State                 string         `json:"State"`
+	City                  string         `json:"City"`
+	Organization          string         `json:"Organization"`
+	OrganizationalUnit    string         `json:"OrganizationalUnit"`
+	KeyPairAlgorithm      string         `json:"KeyPairAlgorithm,omitempty"`
+	KeyBitLength          int            `json:"KeyBitLength,omitempty"`
+}
+
+type rfGenerateCSRRsp struct {
+	CSRString string `json:"CSRString"`
+}
+
+// HPE HpeHttpsCert.GenerateCSR payload.  The CSR shows up in the
+// CertificateSigningRequest field of HttpsCert once it has been generated.
+
+type hpeGenerateCSR struct {
+	City       string `json:"City"`
+	CommonName string `json:"CommonName"`
+	Country    string `json:"Country"`
+	OrgName    string `json:"OrgName"`
+	OrgUnit    string `json:"OrgUnit"`
+	State      string `json:"State"`
+}
+
+// Vault PKI sign request.  hms-securestorage uses mapstructure to turn this
+// into the Vault API data.
+
+type vaultSignReq struct {
+	CSR        string `json:"csr" mapstructure:"csr"`
+	CommonName string `json:"common_name" mapstructure:"common_name"`
+	AltNames   string `json:"alt_names" mapstructure:"alt_names"`
+	IPSANs     string `json:"ip_sans" mapstructure:"ip_sans"`
+	TTL        string `json:"ttl" mapstructure:"ttl"`
+}
+
+const (
+	csrCertTTL         = "8760h" //same as hms_certs.CreateCert()
+	hpeCSRPollTries    = 24
+	hpeCSRPollInterval = 5 * time.Second
+)
+
+// Signs CSRs, replaceable for testing.
+var signBMCCSR = signCSRWithVault
+
+// Sign a CSR with the Vault PKI used by hms_certs.  Uses the sign
+// endpoint of the role hms_certs issues certs with.
+//
+// csr(in):        PEM encoded CSR.
+// commonName(in): Common name of the cert.
+// altNames(in):   Subject alternative names of the cert.
+// Return:         PEM encoded cert; error if signing failed.
+
+func signCSRWithVault(csr string, commonName string, altNames []string) (string, error) {
+	req := vaultSignReq{CSR: csr,
+		CommonName: commonName,
+		AltNames:   strings.Join(altNames, ","),
+		TTL:        csrCertTTL}
+	rsp, err := vaultSignCSR(req)
+	if err != nil {
+		return "", err
+	}
+	return rsp.Data.Certificate, nil
+}
+
+// Make a Vault PKI sign request.
+//
+// req(in): Sign request.
+// Return:  Vault response, which has the cert, issuing CA and CA chain
+//          but no private key; error if signing failed.
+
+func vaultSignCSR(req vaultSignReq) (*hms_certs.VaultCertData, error) {
+	if !vaultEnabled() {
+		return nil, fmt.Errorf("Vault is disabled, can't sign CSRs")
+	}
+	ss, err := sstorage.NewVaultAdapterAs(hms_certs.ConfigParams.VaultPKIBase, "pki-common-direct")
+	if err != nil {
+		return nil, fmt.Errorf("ERROR creating secure storage adapter: %v", err)
+	}
+
+	signPath := strings.Replace(hms_certs.ConfigParams.PKIPath, "issue/", "sign/", 1)
+	var rsp hms_certs.VaultCertData
+	err = ss.StoreWithData(signPath, req, &rsp)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR signing CSR for '%s': %v", req.CommonName, err)
+	}
+	if rsp.Data.Certificate == "" {
+		return nil, fmt.Errorf("ERROR signing CSR for '%s': no cert returned", req.CommonName)
+	}
+	return &rsp, nil
+}
+
+// Check that a CSR from a BMC is a valid, self-consistent PKCS#10 request.
+//
+// csr(in): PEM encoded CSR, with newlines or \n tuples.
+// Return:  PEM encoded CSR with newlines; error if the CSR is not valid.
+
+func checkCSR(csr string) (string, error) {
+	pemCSR := hms_certs.TupleToNewline(csr)
+	block, _ := pem.Decode([]byte(pemCSR))
+	if (block == nil) || !strings.Contains(block.Type, "CERTIFICATE REQUEST") {
+		return "", fmt.Errorf("No CSR found in the BMC response")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("Can't parse the CSR: %v", err)
+	}
+	err = req.CheckSignature()
+	if err != nil {
+		return "", fmt.Errorf("Bad CSR signature: %v", err)
+	}
+	return pemCSR, nil
+}
+
+// Returns the cert common name and alternative names of a target.
+
+func csrCertNames(target string, fqdn string) (string, []string) {
+	xname := stripPort(target)
+	names := []string{xname}
+	if fqdn != "" {
+		names = append(names, xname+"."+strings.TrimLeft(fqdn, "."))
+	}
+	return xname, names
+}
+
+// Check the CSR of a target and sign it.  On failure, the target's task is
+// ignored from then on.
+
+func signTaskCSR(task *trsapi.HttpTask, csr string, fqdn string, result *certOpResult) {
+	targ := targFromTask(task)
+	pemCSR, err := checkCSR(csr)
+	if err != nil {
+		task.Ignore = true
+		*result = certOpResult{statusCode: http.StatusBadGateway,
+			statusMsg: fmt.Sprintf("Bad CSR from '%s': %v", targ, err)}
+		return
+	}
+	cn, altNames := csrCertNames(targ, fqdn)
+	cert, err := signBMCCSR(pemCSR, cn, altNames)
+	if err != nil {
+		task.Ignore = true
+		*result = certOpResult{statusCode: http.StatusInternalServerError,
+			statusMsg: fmt.Sprintf("Can't sign CSR from '%s': %v", targ, err)}
+		return
+	}
+	result.cert = cert
+}
+
+// Cray (and standard Redfish) CSR cert replacement.
+//
+// Algo:
+//
+// o Get the CertificateService and CertificateLocations data, as for
+//   doCrayCerts().
+// o POST CertificateService.GenerateCSR for the collection of the current
+//   cert.  The response holds the CSR.
+// o Sign the CSR.
+// o POST the signed cert, without a key, to
+//   CertificateService.ReplaceCertificate.
+
+func doCrayCSRCerts(taskList []trsapi.HttpTask, targList []string, jdata *bmcCSRCertPost, retData *rfCertPostRsp) error {
+	funcName := "doCrayCSRCerts()"
+	results := make([]certOpResult, len(taskList))
+
+	certSvcs, certURIs, err := getCrayCertLocations(funcName, taskList, targList)
+	if err != nil {
+		return err
+	}
+
+	//Generate the CSRs
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+		targ := targFromTask(&taskList[ii])
+		if certSvcs[ii].Actions.GenerateCSR.Target == "" {
+			taskList[ii].Ignore = true
+			results[ii] = certOpResult{statusCode: http.StatusNotImplemented,
+				statusMsg: "GenerateCSR not supported"}
+			continue
+		}
+
+		cn, altNames := csrCertNames(targ, jdata.FQDN)
+		pld, _ := json.Marshal(&rfGenerateCSR{
+			CertificateCollection: CertificateURI{Uri: path.Dir(certURIs[ii])},
+			CommonName:            cn,
+			AlternativeNames:      altNames,
+			Country:               jdata.Country,
+			State:                 jdata.State,
+			City:                  jdata.City,
+			Organization:          jdata.Organization,
+			OrganizationalUnit:    jdata.OrganizationalUnit,
+			KeyPairAlgorithm:      jdata.KeyPairAlgorithm,
+			KeyBitLength:          jdata.KeyBitLength,
+		})
+		url := dfltProtocol + "://" + targ + certSvcs[ii].Actions.GenerateCSR.Target
+		taskList[ii].Request, _ = http.NewRequest(http.MethodPost, url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	logger.Tracef("%s: Generating CSRs.", funcName)
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem generating CSRs: %v", funcName, err)
+		return err
+	}
+	ignoreBadTasks(funcName, taskList)
+
+	//Sign the CSRs and set up the cert replacement
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+		var csrRsp rfGenerateCSRRsp
+		err = grabTaskRspData(funcName, &taskList[ii], &csrRsp)
+		if err != nil {
+			logger.Errorf("%s: Problem getting response from '%s': %v",
+				funcName, taskList[ii].Request.URL.Path, err)
+			return err
+		}
+
+		signTaskCSR(&taskList[ii], csrRsp.CSRString, jdata.FQDN, &results[ii])
+		if taskList[ii].Ignore {
+			continue
+		}
+
+		targ := targFromTask(&taskList[ii])
+		pld := makeRFCertPayload(VendorCray, bmcCertData{Cert: results[ii].cert},
+			certURIs[ii], "PEM")
+		url := dfltProtocol + "://" + targ + certSvcs[ii].Actions.ReplaceCert.Target
+		taskList[ii].Request, _ = http.NewRequest(http.MethodPost, url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	logger.Tracef("%s: Replacing certs.", funcName)
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem setting certificate: %v", funcName, err)
+		return err
+	}
+	ignoreBadTasks(funcName, taskList)
+
+	setCertOpRetData(taskList, results, retData)
+	return nil
+}
+
+// HPE CSR cert replacement.
+//
+// Algo:
+//
+// o Get the HttpsCert data, as for doHPECerts().
+// o POST HpeHttpsCert.GenerateCSR.
+// o GET HttpsCert until CertificateSigningRequest is populated, which can
+//   take a while.
+// o Sign the CSR.
+// o POST the signed cert to HpeHttpsCert.ImportCertificate.
+
+func doHPECSRCerts(taskList []trsapi.HttpTask, targList []string, jdata *bmcCSRCertPost, retData *rfCertPostRsp) error {
+	funcName := "doHPECSRCerts()"
+	results := make([]certOpResult, len(taskList))
+
+	httpsCerts, certURIs, err := getHPEHttpsCerts(funcName, taskList, targList)
+	if err != nil {
+		return err
+	}
+
+	//Generate the CSRs
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+		targ := targFromTask(&taskList[ii])
+		if httpsCerts[ii].Actions.GenerateCSR.Target == "" {
+			taskList[ii].Ignore = true
+			results[ii] = certOpResult{statusCode: http.StatusNotImplemented,
+				statusMsg: "GenerateCSR not supported"}
+			continue
+		}
+
+		cn, _ := csrCertNames(targ, jdata.FQDN)
+		pld, _ := json.Marshal(&hpeGenerateCSR{City: jdata.City,
+			CommonName: cn,
+			Country:    jdata.Country,
+			OrgName:    jdata.Organization,
+			OrgUnit:    jdata.OrganizationalUnit,
+			State:      jdata.State})
+		url := dfltProtocol + "://" + targ + httpsCerts[ii].Actions.GenerateCSR.Target
+		taskList[ii].Request, _ = http.NewRequest(http.MethodPost, url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	logger.Tracef("%s: Generating CSRs.", funcName)
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem generating CSRs: %v", funcName, err)
+		return err
+	}
+	ignoreBadTasks(funcName, taskList)
+
+	//Poll HttpsCert for the CSRs.  Targets with a CSR are ignored while
+	//the rest are polled.
+
+	csrs := make([]string, len(taskList))
+	for try := 0; try < hpeCSRPollTries; try++ {
+		for ii := 0; ii < len(taskList); ii++ {
+			if taskList[ii].Ignore {
+				continue
+			}
+			url := dfltProtocol + "://" + targFromTask(&taskList[ii]) + certURIs[ii]
+			taskList[ii].Request, _ = http.NewRequest(http.MethodGet, url, nil)
+		}
+		err = doOp(taskList)
+		if err != nil {
+			logger.Errorf("%s: Problem fetching CSRs: %v", funcName, err)
+			return err
+		}
+		ignoreBadTasks(funcName, taskList)
+
+		pending := 0
+		for ii := 0; ii < len(taskList); ii++ {
+			if taskList[ii].Ignore {
+				continue
+			}
+			var httpsCert hpeSecurityServiceHttpsCert
+			err = grabTaskRspData(funcName, &taskList[ii], &httpsCert)
+			if err != nil {
+				logger.Errorf("%s: Problem getting response from '%s': %v",
+					funcName, taskList[ii].Request.URL.Path, err)
+				return err
+			}
+			if httpsCert.CertificateSigningRequest == "" {
+				pending++
+				continue
+			}
+			csrs[ii] = httpsCert.CertificateSigningRequest
+			taskList[ii].Ignore = true
+		}
+		if (pending == 0) || (try == hpeCSRPollTries-1) {
+			break
+		}
+		logger.Tracef("%s: Waiting for %d CSRs.", funcName, pending)
+		time.Sleep(hpeCSRPollInterval)
+	}
+
+	//Sign the CSRs and set up the cert import
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if results[ii].statusCode != 0 {
+			continue
+		}
+		if csrs[ii] == "" {
+			if !taskList[ii].Ignore {
+				taskList[ii].Ignore = true
+				results[ii] = certOpResult{statusCode: http.StatusGatewayTimeout,
+					statusMsg: "Timed out waiting for the CSR"}
+			}
+			continue
+		}
+
+		taskList[ii].Ignore = false
+		signTaskCSR(&taskList[ii], csrs[ii], jdata.FQDN, &results[ii])
+		if taskList[ii].Ignore {
+			continue
+		}
+
+		targ := targFromTask(&taskList[ii])
+		pld := makeRFCertPayload(VendorHPE, bmcCertData{Cert: results[ii].cert}, "", "")
+		url := dfltProtocol + "://" + targ + httpsCerts[ii].Actions.ImportCertificate.Target
+		taskList[ii].Request, _ = http.NewRequest(http.MethodPost, url, bytes.NewBuffer(pld))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	logger.Tracef("%s: Importing certs.", funcName)
+	err = doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem importing certs: %v", funcName, err)
+		return err
+	}
+	ignoreBadTasks(funcName, taskList)
+
+	setCertOpRetData(taskList, results, retData)
+	return nil
+}
+
+// Have target BMCs generate CSRs, sign them and install the signed certs.
+//
+// taskList(inout): Task list of the targets.
+// jdata(in):       Request data.
+// retData(out):    Returned data for REST return.
+// Return:          Error if a failure occurs, else nil.
+
+func setCSRCerts(taskList []trsapi.HttpTask, jdata *bmcCSRCertPost, retData *rfCertPostRsp) error {
+	var sourceTL trsapi.HttpTask
+	funcName := "setCSRCerts()"
+
+	vendors, err := getCertVendors(funcName, taskList, retData)
+	if err != nil {
+		return err
+	}
+
+	var tlistCray, tlistHPE []string
+
+	for ii := 0; ii < len(taskList); ii++ {
+		if taskList[ii].Ignore {
+			continue
+		}
+		targ := targFromTask(&taskList[ii])
+		switch vendors[ii] {
+		case VendorCray:
+			tlistCray = append(tlistCray, targ)
+		case VendorHPE:
+			tlistHPE = append(tlistHPE, targ)
+		default:
+			retData.Targets = append(retData.Targets, certRsp{ID: targ,
+				StatusCode: http.StatusNotImplemented,
+				StatusMsg:  "Unsupported vendor"})
+		}
+	}
+
+	sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+
+	if len(tlistCray) > 0 {
+		taskListCray := tloc.CreateTaskList(&sourceTL, len(tlistCray))
+		err = doCrayCSRCerts(taskListCray, tlistCray, jdata, retData)
+		if err != nil {
+			logger.Errorf("%s: Problem setting CSR certs on Cray target(s): %v",
+				funcName, err)
+			return err
+		}
+	}
+
+	if len(tlistHPE) > 0 {
+		taskListHPE := tloc.CreateTaskList(&sourceTL, len(tlistHPE))
+		err = doHPECSRCerts(taskListHPE, tlistHPE, jdata, retData)
+		if err != nil {
+			logger.Errorf("%s: Problem setting CSR certs on HPE target(s): %v",
+				funcName, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check the request data of /v1/bmc/csrcerts POST.
+
+func validateCSRCertPost(jdata *bmcCSRCertPost) error {
+	if len(jdata.Targets) == 0 {
+		return fmt.Errorf("No targets specified")
+	}
+	required := map[string]string{"Country": jdata.Country,
+		"State":              jdata.State,
+		"City":               jdata.City,
+		"Organization":       jdata.Organization,
+		"OrganizationalUnit": jdata.OrganizationalUnit}
+	for _, name := range []string{"Country", "State", "City", "Organization", "OrganizationalUnit"} {
+		if required[name] == "" {
+			return fmt.Errorf("Missing %s", name)
+		}
+	}
+	if jdata.KeyBitLength < 0 {
+		return fmt.Errorf("Invalid KeyBitLength: %d", jdata.KeyBitLength)
+	}
+	_, err := getCertVerifyWait(jdata.VerifyWait)
+	return err
+}
+
+// Have BMCs generate CSRs, sign them with the Vault PKI and install the
+// signed certs on the BMCs.
+
+func doBMCCSRCertsPost(w http.ResponseWriter, r *http.Request) {
+	var jdata bmcCSRCertPost
+	var retData rfCertPostRsp
+	var sourceTL trsapi.HttpTask
+	funcName := "doBMCCSRCertsPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+	err = validateCSRCertPost(&jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	expTargData, terr := hsmVerify(makeTargData(jdata.Targets), jdata.Force, true)
+	if terr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem verifying target states: %v.", terr)
+		sendErrorRsp(w, "Indeterminate target state", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	var tlist []string
+	for ii := 0; ii < len(expTargData); ii++ {
+		if expTargData[ii].groupMatched {
+			continue
+		}
+		if !goodHSMState(expTargData[ii].state.String()) {
+			retData.Targets = append(retData.Targets, certRsp{ID: expTargData[ii].target,
+				StatusCode: http.StatusUnprocessableEntity,
+				StatusMsg: fmt.Sprintf("Target '%s' in bad HSM state: %s",
+					expTargData[ii].target, string(expTargData[ii].state))})
+			continue
+		}
+		tlist = append(tlist, expTargData[ii].target)
+	}
+
+	if len(tlist) > 0 {
+		sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+		sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+		taskList := tloc.CreateTaskList(&sourceTL, len(tlist))
+		populateTaskList(taskList, tlist, RFROOT_API, http.MethodGet, nil)
+
+		var servedFPs map[string]string
+		if jdata.Verify {
+			servedFPs = getServedCertFingerprints(tlist)
+		}
+
+		err = setCSRCerts(taskList, &jdata, &retData)
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: CSR certificate operation failed: %v", err)
+			sendErrorRsp(w, "CSR certificate operation error", emsg, r.URL.Path,
+				http.StatusInternalServerError)
+			return
+		}
+
+		pushed := make(map[string]string)
+		for _, elm := range retData.Targets {
+			if elm.Cert != nil {
+				pushed[elm.ID] = elm.Cert.CertData
+			}
+		}
+		pinPushedCerts(&retData, pushed)
+
+		if jdata.Verify {
+			verifyWait, _ := getCertVerifyWait(jdata.VerifyWait)
+			verifyPushedCerts(&retData, pushed, servedFPs, verifyWait)
+		}
+	}
+
+	ba, berr := json.Marshal(&retData)
+	if berr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling return data: %v", berr)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
@@ -0,0 +1,248 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+func makeTestCSR(t *testing.T, cn string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Can't generate key: %v", err)
+	}
+	tmpl := x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &tmpl, key)
+	if err != nil {
+		t.Fatalf("Can't create CSR: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+func TestCheckCSR(t *testing.T) {
+	csr := makeTestCSR(t, "x0c0s0b0")
+
+	pemCSR, err := checkCSR(hms_certs.NewlineToTuple(csr))
+	if err != nil {
+		t.Errorf("Unexpected error checking CSR: %v", err)
+	}
+	if strings.TrimSpace(pemCSR) != strings.TrimSpace(csr) {
+		t.Errorf("CSR mismatch, exp:\n%s\ngot:\n%s", csr, pemCSR)
+	}
+
+	_, err = checkCSR("")
+	if err == nil {
+		t.Errorf("Expected error with empty CSR")
+	}
+
+	_, certPEM, _ := makeTestCert(t, "x0c0s0b0", time.Hour)
+	_, err = checkCSR(certPEM)
+	if err == nil {
+		t.Errorf("Expected error with a cert instead of a CSR")
+	}
+
+	//Flip a bit in the signature
+
+	block, _ := pem.Decode([]byte(csr))
+	block.Bytes[len(block.Bytes)-1] ^= 0x01
+	_, err = checkCSR(string(pem.EncodeToMemory(block)))
+	if err == nil {
+		t.Errorf("Expected error with a corrupt CSR")
+	}
+}
+
+func TestValidateCSRCertPost(t *testing.T) {
+	good := bmcCSRCertPost{Targets: []string{"x0c0s0b0"},
+		Country: "US", State: "MN", City: "Bloomington",
+		Organization: "HPE", OrganizationalUnit: "HPC"}
+
+	if err := validateCSRCertPost(&good); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	bad := good
+	bad.Targets = nil
+	if err := validateCSRCertPost(&bad); err == nil {
+		t.Errorf("Expected error with no targets")
+	}
+	bad = good
+	bad.City = ""
+	if err := validateCSRCertPost(&bad); err == nil {
+		t.Errorf("Expected error with no City")
+	}
+	bad = good
+	bad.KeyBitLength = -1
+	if err := validateCSRCertPost(&bad); err == nil {
+		t.Errorf("Expected error with negative KeyBitLength")
+	}
+}
+
+// Fake BMC supporting GenerateCSR and ReplaceCertificate.  The chassis
+// member determines the vendor.  If noActions is set the CertificateService
+// has no actions.
+
+type fakeCSRBMC struct {
+	sync.Mutex
+	t          *testing.T
+	chassis    string
+	noActions  bool
+	csrReq     rfGenerateCSR
+	replacePld CertificatePayload
+}
+
+func (f *fakeCSRBMC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	switch r.URL.Path {
+	case RFCHASSIS_API:
+		w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Chassis/` + f.chassis + `"}]}`))
+	case CRAY_CERTSVC_API:
+		if f.noActions {
+			w.Write([]byte(`{"CertificateLocations":{"@odata.id":"/redfish/v1/CertificateService/CertificateLocations"}}`))
+			return
+		}
+		w.Write([]byte(`{"Actions":{"#CertificateService.ReplaceCertificate":{"target":"/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate"},"#CertificateService.GenerateCSR":{"target":"/redfish/v1/CertificateService/Actions/CertificateService.GenerateCSR"}},"CertificateLocations":{"@odata.id":"/redfish/v1/CertificateService/CertificateLocations"}}`))
+	case "/redfish/v1/CertificateService/CertificateLocations":
+		w.Write([]byte(`{"Links":{"Certificates":[{"@odata.id":"/redfish/v1/Managers/BMC/NetworkProtocol/HTTPS/Certificates/1"}]}}`))
+	case "/redfish/v1/CertificateService/Actions/CertificateService.GenerateCSR":
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &f.csrReq)
+		rsp := rfGenerateCSRRsp{CSRString: hms_certs.NewlineToTuple(makeTestCSR(f.t, f.csrReq.CommonName))}
+		ba, _ := json.Marshal(&rsp)
+		w.Write([]byte(strings.Replace(string(ba), "\\\\", "\\", -1)))
+	case "/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate":
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &f.replacePld)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSetCSRCerts(t *testing.T) {
+	loggerSetup()
+	savedProto, savedSigner, savedVault := dfltProtocol, signBMCCSR, appParams.VaultEnable
+	defer func() {
+		dfltProtocol, signBMCCSR, appParams.VaultEnable = savedProto, savedSigner, savedVault
+	}()
+	dfltProtocol = "http"
+	appParams.VaultEnable = nil //no RF creds needed
+
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("Error initializing TRS API: %v", err)
+	}
+
+	var signedCN string
+	var signedAltNames []string
+	signBMCCSR = func(csr string, cn string, altNames []string) (string, error) {
+		signedCN, signedAltNames = cn, altNames
+		return "-----BEGIN CERTIFICATE-----\nsigned\n-----END CERTIFICATE-----", nil
+	}
+
+	crayBMC := &fakeCSRBMC{t: t, chassis: "Enclosure"}
+	craySrv := httptest.NewServer(crayBMC)
+	defer craySrv.Close()
+	intelBMC := &fakeCSRBMC{t: t, chassis: "RackMount"}
+	intelSrv := httptest.NewServer(intelBMC)
+	defer intelSrv.Close()
+
+	crayURL, _ := url.Parse(craySrv.URL)
+	intelURL, _ := url.Parse(intelSrv.URL)
+	targs := []string{crayURL.Host, intelURL.Host}
+
+	jdata := bmcCSRCertPost{Targets: targs, FQDN: ".local",
+		Country: "US", State: "MN", City: "Bloomington",
+		Organization: "HPE", OrganizationalUnit: "HPC"}
+
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = 10 * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, len(targs))
+	populateTaskList(taskList, targs, RFROOT_API, http.MethodGet, nil)
+
+	var retData rfCertPostRsp
+	err = setCSRCerts(taskList, &jdata, &retData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(retData.Targets) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %v", len(retData.Targets), retData.Targets)
+	}
+
+	for _, elm := range retData.Targets {
+		switch elm.ID {
+		case crayURL.Host:
+			if elm.StatusCode != http.StatusOK {
+				t.Errorf("Cray target failed: %d/%s", elm.StatusCode, elm.StatusMsg)
+			}
+			if (elm.Cert == nil) || !strings.Contains(elm.Cert.CertData, "signed") {
+				t.Errorf("Cray target missing signed cert: %v", elm.Cert)
+			}
+		case intelURL.Host:
+			if elm.StatusCode != http.StatusNotImplemented {
+				t.Errorf("Expected 501 for Intel target, got %d", elm.StatusCode)
+			}
+		default:
+			t.Errorf("Unexpected target in results: '%s'", elm.ID)
+		}
+	}
+
+	if crayBMC.csrReq.CertificateCollection.Uri != "/redfish/v1/Managers/BMC/NetworkProtocol/HTTPS/Certificates" {
+		t.Errorf("Wrong CSR cert collection: '%s'", crayBMC.csrReq.CertificateCollection.Uri)
+	}
+	if (crayBMC.csrReq.Country != "US") || (crayBMC.csrReq.OrganizationalUnit != "HPC") {
+		t.Errorf("Wrong CSR subject: %v", crayBMC.csrReq)
+	}
+	expNames := []string{"127.0.0.1", "127.0.0.1.local"}
+	if (signedCN != "127.0.0.1") || (strings.Join(signedAltNames, ",") != strings.Join(expNames, ",")) {
+		t.Errorf("Wrong signed names, exp: %s %v, got: %s %v", "127.0.0.1", expNames,
+			signedCN, signedAltNames)
+	}
+	if (crayBMC.replacePld.CertificateString == nil) ||
+		strings.Contains(*crayBMC.replacePld.CertificateString, "PRIVATE KEY") {
+		t.Errorf("Bad cert replacement payload: %v", crayBMC.replacePld)
+	}
+	if (crayBMC.replacePld.CertificateUri == nil) ||
+		(crayBMC.replacePld.CertificateUri.Uri != "/redfish/v1/Managers/BMC/NetworkProtocol/HTTPS/Certificates/1") {
+		t.Errorf("Bad cert replacement URI: %v", crayBMC.replacePld.CertificateUri)
+	}
+}
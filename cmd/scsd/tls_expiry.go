@@ -0,0 +1,375 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+// Used for /v1/bmc/certexpiry POST.  If ExpiresWithinDays is set, only
+// targets whose BMC or Vault cert expires within that many days are
+// returned, along with any targets whose certs could not be read.
+
+type certExpiryPost struct {
+	Force             bool     `json:"Force"`
+	CertDomain        string   `json:"CertDomain"`
+	Targets           []string `json:"Targets"`
+	ExpiresWithinDays *int     `json:"ExpiresWithinDays,omitempty"`
+}
+
+// Details of a leaf cert.  Fingerprint is the SHA-256 of the DER encoding.
+
+type certDetail struct {
+	Subject       string    `json:"Subject"`
+	SANs          []string  `json:"SANs"`
+	Issuer        string    `json:"Issuer"`
+	NotBefore     time.Time `json:"NotBefore"`
+	NotAfter      time.Time `json:"NotAfter"`
+	DaysRemaining int       `json:"DaysRemaining"`
+	Fingerprint   string    `json:"Fingerprint"`
+}
+
+type certExpiryRspElem struct {
+	ID         string      `json:"ID"`
+	StatusCode int         `json:"StatusCode"`
+	StatusMsg  string      `json:"StatusMsg"`
+	BMCCert    *certDetail `json:"BMCCert,omitempty"`
+	VaultCert  *certDetail `json:"VaultCert,omitempty"`
+	Match      bool        `json:"Match"`
+}
+
+type certExpiryRsp struct {
+	Targets []certExpiryRspElem `json:"Targets"`
+}
+
+// Port used for the TLS handshake with a BMC when the target has none.
+const bmcTLSPort = "443"
+
+// Maximum number of BMCs contacted at once by the cert helpers, across all
+// requests.  Set by SCSD_BMC_DIAL_WORKERS.
+var bmcDialWorkers = 32
+
+var bmcDialSlots chan struct{}
+var bmcDialSlotsOnce sync.Once
+
+// Create the cert details from a parsed cert.
+//
+// cert(in): Parsed x509 cert.
+// now(in):  Time from which to count the days remaining.
+// Return:   Cert details.
+
+func makeCertDetail(cert *x509.Certificate, now time.Time) *certDetail {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return &certDetail{Subject: cert.Subject.String(),
+		SANs:          sans,
+		Issuer:        cert.Issuer.String(),
+		NotBefore:     cert.NotBefore.UTC(),
+		NotAfter:      cert.NotAfter.UTC(),
+		DaysRemaining: int(cert.NotAfter.Sub(now).Hours() / 24),
+		Fingerprint:   certFingerprint(cert),
+	}
+}
+
+// Returns the SHA-256 fingerprint of a cert's DER encoding, in hex.
+
+func certFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse the leaf cert out of PEM data.  The leaf is the first cert; any
+// following certs are its chain.
+//
+// pemData(in): PEM cert data, with newlines or \n tuples.
+// Return:      Parsed leaf cert; error if none was found.
+
+func parseLeafCert(pemData string) (*x509.Certificate, error) {
+	rest := []byte(hms_certs.TupleToNewline(pemData))
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("No certificate found in PEM data")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// Run a function for each of a number of targets on a bounded pool of
+// workers.  At most bmcDialWorkers of the functions run at once, counting
+// those of concurrent calls.
+//
+// count(in): Number of targets.
+// dial(in):  Function contacting the target at the given index.
+
+func runBMCDials(count int, dial func(ii int)) {
+	bmcDialSlotsOnce.Do(func() {
+		bmcDialSlots = make(chan struct{}, max(bmcDialWorkers, 1))
+	})
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for ww := 0; ww < min(count, cap(bmcDialSlots)); ww++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ii := range indexes {
+				bmcDialSlots <- struct{}{}
+				dial(ii)
+				<-bmcDialSlots
+			}
+		}()
+	}
+	for ii := 0; ii < count; ii++ {
+		indexes <- ii
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// Fetch the leaf cert served by a BMC with a TLS handshake.  The cert is
+// not validated, since the point is to look at it whatever it is.
+//
+// target(in): BMC XName, with an optional :port.
+// Return:     Served leaf cert; error if the handshake failed.
+
+func getBMCCert(target string) (*x509.Certificate, error) {
+	addr := target
+	if !strings.Contains(addr, ":") {
+		addr = net.JoinHostPort(addr, bmcTLSPort)
+	}
+	dialer := &net.Dialer{Timeout: time.Duration(appParams.HTTPTimeout) * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr,
+		&tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return nil, fmt.Errorf("TLS handshake with '%s' failed: %v", addr, err)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("'%s' served no certificate", addr)
+	}
+	return certs[0], nil
+}
+
+// Fetch the leaf cert of a target from Vault.
+//
+// target(in):     BMC XName, with an optional :port.
+// certDomain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// Return:         Leaf cert from Vault; error and HTTP status code on failure.
+
+func getVaultCert(target string, certDomain string) (*x509.Certificate, error, int) {
+	xname := stripPort(target)
+	_, err := hms_certs.CheckDomain([]string{xname}, certDomain)
+	if err != nil {
+		return nil, fmt.Errorf("Cert target %s not found in domain %s: %v",
+			xname, certDomain, err), http.StatusBadRequest
+	}
+	vcert, err := hms_certs.FetchCertData(xname, certDomain)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR fetching cert for '%s': %v", xname, err),
+			http.StatusNotFound
+	}
+	cert, err := parseLeafCert(vcert.Data.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR parsing Vault cert for '%s': %v", xname, err),
+			http.StatusInternalServerError
+	}
+	return cert, nil, http.StatusOK
+}
+
+// Get the BMC and Vault certs of one target and compare them.
+//
+// target(in):     BMC XName, with an optional :port.
+// certDomain(in): Cert domain of the Vault cert.
+// now(in):        Time from which to count the days remaining.
+// Return:         Response element for the target.
+
+func getCertExpiry(target string, certDomain string, now time.Time) certExpiryRspElem {
+	elm := certExpiryRspElem{ID: target, StatusCode: http.StatusOK}
+	var emsgs []string
+
+	bmcCert, err := getBMCCert(target)
+	if err != nil {
+		elm.StatusCode = http.StatusInternalServerError
+		emsgs = append(emsgs, err.Error())
+	} else {
+		elm.BMCCert = makeCertDetail(bmcCert, now)
+	}
+
+	vaultCert, err, code := getVaultCert(target, certDomain)
+	if err != nil {
+		if elm.StatusCode == http.StatusOK {
+			elm.StatusCode = code
+		}
+		emsgs = append(emsgs, err.Error())
+	} else {
+		elm.VaultCert = makeCertDetail(vaultCert, now)
+	}
+
+	if (elm.BMCCert != nil) && (elm.VaultCert != nil) {
+		elm.Match = elm.BMCCert.Fingerprint == elm.VaultCert.Fingerprint
+	}
+	if len(emsgs) > 0 {
+		elm.StatusMsg = strings.Join(emsgs, "; ")
+	} else {
+		elm.StatusMsg = "OK"
+	}
+	return elm
+}
+
+// Check if a response element passes the "expires within N days" filter.
+// Elements with errors always pass, since their expiry is unknown.
+//
+// elm(in):    Response element.
+// days(in):   Number of days.
+// now(in):    Time from which to count the days.
+// Return:     true if the element should be reported.
+
+func certExpiresWithin(elm certExpiryRspElem, days int, now time.Time) bool {
+	if !statusCodeOK(elm.StatusCode) {
+		return true
+	}
+	limit := now.Add(time.Duration(days) * 24 * time.Hour)
+	for _, detail := range []*certDetail{elm.BMCCert, elm.VaultCert} {
+		if (detail != nil) && detail.NotAfter.Before(limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// Report the leaf certs of BMCs, both as served by the BMC and as stored
+// in Vault, with their expiration dates and whether they match.
+
+func doBMCCertExpiryPost(w http.ResponseWriter, r *http.Request) {
+	var jdata certExpiryPost
+	var retData certExpiryRsp
+	funcName := "doBMCCertExpiryPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request data", "ERROR: No targets specified.",
+			r.URL.Path, http.StatusBadRequest)
+		return
+	}
+	if (jdata.ExpiresWithinDays != nil) && (*jdata.ExpiresWithinDays < 0) {
+		emsg := fmt.Sprintf("ERROR: Invalid ExpiresWithinDays: %d",
+			*jdata.ExpiresWithinDays)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+	if jdata.CertDomain == "" {
+		jdata.CertDomain = "cabinet"
+	}
+	certDomain, derr := userDomainToCertDomain(jdata.CertDomain)
+	if derr != nil {
+		emsg := fmt.Sprintf("ERROR: %v", derr)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	expTargData, terr := hsmVerify(makeTargData(jdata.Targets), jdata.Force, true)
+	if terr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem verifying target states: %v.", terr)
+		sendErrorRsp(w, "Indeterminate target state", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	//Handshake with the targets in good HSM states in parallel; the others
+	//are reported as such.
+
+	now := time.Now()
+	var targs []targInfo
+	for ii := 0; ii < len(expTargData); ii++ {
+		if !expTargData[ii].groupMatched {
+			targs = append(targs, expTargData[ii])
+		}
+	}
+
+	elms := make([]certExpiryRspElem, len(targs))
+	runBMCDials(len(targs), func(ii int) {
+		if !goodHSMState(targs[ii].state.String()) {
+			elms[ii] = certExpiryRspElem{ID: targs[ii].target,
+				StatusCode: http.StatusUnprocessableEntity,
+				StatusMsg: fmt.Sprintf("Target '%s' in bad HSM state: %s",
+					targs[ii].target, string(targs[ii].state))}
+			return
+		}
+		elms[ii] = getCertExpiry(targs[ii].target, certDomain, now)
+	})
+
+	retData.Targets = []certExpiryRspElem{}
+	for _, elm := range elms {
+		if (jdata.ExpiresWithinDays == nil) ||
+			certExpiresWithin(elm, *jdata.ExpiresWithinDays, now) {
+			retData.Targets = append(retData.Targets, elm)
+		}
+	}
+
+	ba, baerr := json.Marshal(&retData)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR marshalling response data: %v", baerr)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
@@ -0,0 +1,500 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/gorilla/mux"
+)
+
+// Trust-on-first-use (TOFU) pinning of BMC TLS certs, for BMCs with
+// self-signed certs which can't be validated with a CA bundle.  When
+// enabled, the fingerprint of the cert a BMC serves is pinned the first
+// time SCSD sends it a Redfish request.  Later requests are refused if the
+// BMC serves another cert, until an admin acknowledges the new one.  The
+// pin is checked in the TLS handshake of the connection carrying the
+// request, see launchRFTasks().  When
+// SCSD installs a cert on a BMC, the pin is moved to it, and the old cert
+// stays accepted until the BMC serves the new one.
+//
+// Pins are kept in Vault when it is enabled, in memory otherwise.
+
+var tlsPinEnable = false
+var VaultTLSPinKeypath = "secret/hms-tls-pins"
+
+// Pin sources
+
+const (
+	TLSPinFirstUse     = "first-use"
+	TLSPinInstalled    = "installed"
+	TLSPinAcknowledged = "acknowledged"
+)
+
+// A pinned BMC cert.  Times are RFC3339 strings so that pins can be
+// stored in Vault as is.
+
+type tlsPin struct {
+	ID                  string `json:"ID"`
+	Fingerprint         string `json:"Fingerprint"`
+	Subject             string `json:"Subject,omitempty"`
+	Source              string `json:"Source"`
+	PinnedAt            string `json:"PinnedAt"`
+	PrevFingerprint     string `json:"PrevFingerprint,omitempty"`
+	MismatchFingerprint string `json:"MismatchFingerprint,omitempty"`
+	MismatchSubject     string `json:"MismatchSubject,omitempty"`
+	MismatchSeenAt      string `json:"MismatchSeenAt,omitempty"`
+}
+
+// All pins, as stored in Vault.
+
+type tlsPinStore struct {
+	Pins map[string]tlsPin `json:"Pins"`
+}
+
+// Return of /v1/bmc/tlspins GET.
+
+type tlsPinsRsp struct {
+	Enabled bool     `json:"Enabled"`
+	Pins    []tlsPin `json:"Pins"`
+}
+
+// Payload of /v1/bmc/tlspins/{xname}/ack POST.  If Fingerprint is given it
+// must be the one of the mismatched cert.
+
+type tlsPinAckPost struct {
+	Fingerprint string `json:"Fingerprint"`
+}
+
+var errTLSPinMismatch = errors.New("TLS cert changed")
+
+var tlsPinLock sync.Mutex
+var tlsPins = make(map[string]tlsPin)
+var tlsPinsLoaded = false
+
+// Load the pins from Vault, once.  Fails if Vault is enabled but not
+// connected, since pinning again from scratch would defeat the pins.
+// Must be called with tlsPinLock held.
+
+func loadTLSPins() error {
+	if tlsPinsLoaded {
+		return nil
+	}
+	if (appParams.VaultEnable != nil) && *appParams.VaultEnable {
+		if secureStore == nil {
+			return fmt.Errorf("Vault not connected, can't load TLS pins")
+		}
+		var store tlsPinStore
+		err := secureStore.Lookup(VaultTLSPinKeypath, &store)
+		if err != nil {
+			return fmt.Errorf("Can't load TLS pins from Vault: %v", err)
+		}
+		for id, pin := range store.Pins {
+			tlsPins[id] = pin
+		}
+	}
+	tlsPinsLoaded = true
+	return nil
+}
+
+// Store the pins in Vault, if it is enabled.  Must be called with
+// tlsPinLock held.
+
+func storeTLSPins() error {
+	if (appParams.VaultEnable == nil) || !*appParams.VaultEnable {
+		return nil
+	}
+	if secureStore == nil {
+		return fmt.Errorf("Vault not connected, can't store TLS pins")
+	}
+	err := secureStore.Store(VaultTLSPinKeypath, tlsPinStore{Pins: tlsPins})
+	if err != nil {
+		return fmt.Errorf("Can't store TLS pins in Vault: %v", err)
+	}
+	return nil
+}
+
+func tlsPinID(targ string) string {
+	return strings.ToLower(targ)
+}
+
+// Check the cert served by a target against its pin, pinning it if the
+// target has none yet and pinNew is set.
+//
+// targ(in):   Target, with an optional :port.
+// cert(in):   Leaf cert served by the target.
+// pinNew(in): Pin the cert if the target has no pin.
+// Return:     true if the pins changed; error if the cert doesn't match.
+
+func checkTLSPin(targ string, cert *x509.Certificate, pinNew bool) (bool, error) {
+	id := tlsPinID(targ)
+	fp := certFingerprint(cert)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	pin, ok := tlsPins[id]
+	if !ok {
+		if !pinNew {
+			return false, nil
+		}
+		logger.Infof("Pinning TLS cert of '%s' on first use: %s", targ, fp)
+		tlsPins[id] = tlsPin{ID: id, Fingerprint: fp,
+			Subject: cert.Subject.String(), Source: TLSPinFirstUse,
+			PinnedAt: now}
+		return true, nil
+	}
+
+	switch fp {
+	case pin.Fingerprint:
+		if (pin.PrevFingerprint == "") && (pin.MismatchFingerprint == "") {
+			return false, nil
+		}
+		pin.PrevFingerprint = ""
+		pin.MismatchFingerprint = ""
+		pin.MismatchSubject = ""
+		pin.MismatchSeenAt = ""
+		tlsPins[id] = pin
+		return true, nil
+	case pin.PrevFingerprint:
+		//Installed cert not served yet
+		return false, nil
+	}
+
+	changed := pin.MismatchFingerprint != fp
+	if changed {
+		pin.MismatchFingerprint = fp
+		pin.MismatchSubject = cert.Subject.String()
+		pin.MismatchSeenAt = now
+		tlsPins[id] = pin
+	}
+	return changed, fmt.Errorf("%w: '%s' serves %s, pinned %s; %w until the new cert is acknowledged",
+		errTLSPinMismatch, targ, fp, pin.Fingerprint, errRFTLSRefused)
+}
+
+// Check the cert served by a target against its pin, pinning it if the
+// target has none yet and pinNew is set, and store the pins if they changed.
+//
+// targ(in):   Target, with an optional :port.
+// cert(in):   Leaf cert served by the target.
+// pinNew(in): Pin the cert if the target has no pin.
+// Return:     Error if the cert doesn't match or can't be checked.
+
+func verifyTLSPin(targ string, cert *x509.Certificate, pinNew bool) error {
+	tlsPinLock.Lock()
+	defer tlsPinLock.Unlock()
+
+	err := loadTLSPins()
+	if err != nil {
+		return fmt.Errorf("Can't check the pinned TLS cert, %w: %v",
+			errRFTLSRefused, err)
+	}
+	changed, perr := checkTLSPin(targ, cert, pinNew)
+	if changed {
+		err = storeTLSPins()
+		if err != nil {
+			logger.Errorf("%v", err)
+		}
+	}
+	return perr
+}
+
+type tlsPinTargKey struct{}
+
+// Returns a request context carrying the target whose pin the connection
+// sending the request is checked against.
+
+func withTLSPinTarg(ctx context.Context, targ string) context.Context {
+	return context.WithValue(ctx, tlsPinTargKey{}, targ)
+}
+
+// Returns a TLS dial func for a Redfish transport, which checks the cert
+// a target serves against its pin during the handshake.  A request thus
+// only goes out over a connection to the pinned cert; on a mismatch the
+// handshake fails and nothing is sent.
+//
+// cfg(in):    TLS config of the transport.
+// pinNew(in): Pin the cert of targets which have no pin yet.  Unset for
+//             connections made before the target's identity is known.
+// Return:     Dial func for the transport's DialTLSContext.
+
+func tlsPinDialer(cfg *tls.Config, pinNew bool) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		targ, _ := ctx.Value(tlsPinTargKey{}).(string)
+		if targ == "" {
+			return nil, fmt.Errorf("No target to check the pinned TLS cert of '%s' against, %w",
+				addr, errRFTLSRefused)
+		}
+		tcfg := cfg.Clone()
+		if tcfg.ServerName == "" {
+			tcfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tcfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("'%s' served no TLS cert, %w", targ, errRFTLSRefused)
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return fmt.Errorf("Can't parse the TLS cert of '%s', %w: %v",
+					targ, errRFTLSRefused, err)
+			}
+			return verifyTLSPin(targ, cert, pinNew)
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tconn := tls.Client(conn, tcfg)
+		err = tconn.HandshakeContext(ctx)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tconn, nil
+	}
+}
+
+// Move the pins of targets to the certs SCSD installed on them.
+//
+// retData(in): Results of the cert install.
+// pushed(in):  PEM cert installed on each target.
+
+func pinPushedCerts(retData *rfCertPostRsp, pushed map[string]string) {
+	if !tlsPinEnable {
+		return
+	}
+
+	tlsPinLock.Lock()
+	defer tlsPinLock.Unlock()
+	err := loadTLSPins()
+	if err != nil {
+		logger.Errorf("Can't move TLS pins to installed certs: %v", err)
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	changed := false
+	for _, elm := range retData.Targets {
+		pem, ok := pushed[elm.ID]
+		if !ok || !statusCodeOK(elm.StatusCode) {
+			continue
+		}
+		cert, err := parseLeafCert(pem)
+		if err != nil {
+			logger.Errorf("Can't parse the cert installed on '%s', TLS pin not moved: %v",
+				elm.ID, err)
+			continue
+		}
+		id := tlsPinID(elm.ID)
+		old := tlsPins[id]
+		fp := certFingerprint(cert)
+		if old.Fingerprint == fp {
+			continue
+		}
+		logger.Infof("Moving TLS pin of '%s' to installed cert %s", elm.ID, fp)
+		tlsPins[id] = tlsPin{ID: id, Fingerprint: fp,
+			Subject: cert.Subject.String(), Source: TLSPinInstalled,
+			PinnedAt: now, PrevFingerprint: old.Fingerprint}
+		changed = true
+	}
+
+	if changed {
+		err = storeTLSPins()
+		if err != nil {
+			logger.Errorf("%v", err)
+		}
+	}
+}
+
+// Get the pin ID from the request URL and lock the pins.  On error, an
+// error response is sent and the pins are not locked.
+
+func lockTLSPinReq(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := tlsPinID(mux.Vars(r)["xname"])
+	tlsPinLock.Lock()
+	err := loadTLSPins()
+	if err != nil {
+		tlsPinLock.Unlock()
+		sendErrorRsp(w, "TLS pin load error", fmt.Sprintf("ERROR: %v", err),
+			r.URL.Path, http.StatusServiceUnavailable)
+		return id, false
+	}
+	return id, true
+}
+
+func sendTLSPinRsp(w http.ResponseWriter, r *http.Request, data interface{}) {
+	ba, err := json.Marshal(data)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling TLS pin data: %v", err)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// /v1/bmc/tlspins GET
+
+func doBMCTLSPinsGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	tlsPinLock.Lock()
+	err := loadTLSPins()
+	if err != nil {
+		tlsPinLock.Unlock()
+		sendErrorRsp(w, "TLS pin load error", fmt.Sprintf("ERROR: %v", err),
+			r.URL.Path, http.StatusServiceUnavailable)
+		return
+	}
+	rsp := tlsPinsRsp{Enabled: tlsPinEnable, Pins: []tlsPin{}}
+	for _, pin := range tlsPins {
+		rsp.Pins = append(rsp.Pins, pin)
+	}
+	tlsPinLock.Unlock()
+
+	sort.Slice(rsp.Pins, func(i, j int) bool {
+		return rsp.Pins[i].ID < rsp.Pins[j].ID
+	})
+	sendTLSPinRsp(w, r, &rsp)
+}
+
+// /v1/bmc/tlspins/{xname} GET
+
+func doBMCTLSPinGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	id, ok := lockTLSPinReq(w, r)
+	if !ok {
+		return
+	}
+	pin, ok := tlsPins[id]
+	tlsPinLock.Unlock()
+
+	if !ok {
+		sendErrorRsp(w, "No TLS pin", fmt.Sprintf("ERROR: No TLS pin for '%s'.", id),
+			r.URL.Path, http.StatusNotFound)
+		return
+	}
+	sendTLSPinRsp(w, r, &pin)
+}
+
+// /v1/bmc/tlspins/{xname} DELETE.  The target's cert is pinned again on
+// the next contact.
+
+func doBMCTLSPinDelete(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	id, ok := lockTLSPinReq(w, r)
+	if !ok {
+		return
+	}
+	defer tlsPinLock.Unlock()
+
+	if _, ok := tlsPins[id]; !ok {
+		sendErrorRsp(w, "No TLS pin", fmt.Sprintf("ERROR: No TLS pin for '%s'.", id),
+			r.URL.Path, http.StatusNotFound)
+		return
+	}
+	delete(tlsPins, id)
+	err := storeTLSPins()
+	if err != nil {
+		sendErrorRsp(w, "TLS pin store error", fmt.Sprintf("ERROR: %v", err),
+			r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+	logger.Infof("TLS pin of '%s' reset.", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// /v1/bmc/tlspins/{xname}/ack POST.  Pins the mismatched cert last served
+// by the target.
+
+func doBMCTLSPinAckPost(w http.ResponseWriter, r *http.Request) {
+	var jdata tlsPinAckPost
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	if r.ContentLength != 0 {
+		err := getReqData("doBMCTLSPinAckPost", r, &jdata)
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: %v", err)
+			sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	id, ok := lockTLSPinReq(w, r)
+	if !ok {
+		return
+	}
+	defer tlsPinLock.Unlock()
+
+	pin, ok := tlsPins[id]
+	if !ok {
+		sendErrorRsp(w, "No TLS pin", fmt.Sprintf("ERROR: No TLS pin for '%s'.", id),
+			r.URL.Path, http.StatusNotFound)
+		return
+	}
+	if pin.MismatchFingerprint == "" {
+		sendErrorRsp(w, "No TLS cert mismatch",
+			fmt.Sprintf("ERROR: '%s' has served no cert other than the pinned one.", id),
+			r.URL.Path, http.StatusConflict)
+		return
+	}
+	fp := strings.ToLower(strings.ReplaceAll(jdata.Fingerprint, ":", ""))
+	if (fp != "") && (fp != pin.MismatchFingerprint) {
+		sendErrorRsp(w, "TLS cert mismatch",
+			fmt.Sprintf("ERROR: '%s' last served %s, not %s.", id,
+				pin.MismatchFingerprint, fp),
+			r.URL.Path, http.StatusConflict)
+		return
+	}
+
+	pin = tlsPin{ID: id, Fingerprint: pin.MismatchFingerprint,
+		Subject: pin.MismatchSubject, Source: TLSPinAcknowledged,
+		PinnedAt: time.Now().UTC().Format(time.RFC3339)}
+	tlsPins[id] = pin
+	err := storeTLSPins()
+	if err != nil {
+		sendErrorRsp(w, "TLS pin store error", fmt.Sprintf("ERROR: %v", err),
+			r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+	logger.Infof("TLS cert %s of '%s' acknowledged and pinned.", pin.Fingerprint, id)
+	sendTLSPinRsp(w, r, &pin)
+}
@@ -0,0 +1,326 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+	"github.com/gorilla/mux"
+)
+
+// Save and restore the TLS pinning globals around a test, starting with
+// pinning enabled and no pins.
+
+func tlsPinSetup(t *testing.T) func() {
+	loggerSetup()
+	savedEnable, savedPins, savedLoaded := tlsPinEnable, tlsPins, tlsPinsLoaded
+	savedProto, savedVault := dfltProtocol, appParams.VaultEnable
+	tlsPinEnable = true
+	tlsPins = make(map[string]tlsPin)
+	tlsPinsLoaded = false
+	appParams.VaultEnable = nil
+	return func() {
+		tlsPinEnable, tlsPins, tlsPinsLoaded = savedEnable, savedPins, savedLoaded
+		dfltProtocol, appParams.VaultEnable = savedProto, savedVault
+	}
+}
+
+func TestCheckTLSPin(t *testing.T) {
+	defer tlsPinSetup(t)()
+	certA, pemA, _ := makeTestCert(t, "x0c0s0b0", time.Hour)
+	certB, pemB, _ := makeTestCert(t, "x0c0s0b0", time.Hour)
+	fpA, fpB := certFingerprint(certA), certFingerprint(certB)
+	targ := "x0c0s0b0"
+
+	changed, err := checkTLSPin(targ, certA, true)
+	if !changed || (err != nil) {
+		t.Fatalf("First use: expected new pin, got changed %t, err %v", changed, err)
+	}
+	if pin := tlsPins[targ]; (pin.Fingerprint != fpA) || (pin.Source != TLSPinFirstUse) {
+		t.Errorf("First use: unexpected pin %+v", pin)
+	}
+	changed, err = checkTLSPin(targ, certA, true)
+	if changed || (err != nil) {
+		t.Errorf("Same cert: expected no change, got changed %t, err %v", changed, err)
+	}
+
+	changed, err = checkTLSPin(targ, certB, true)
+	if !errors.Is(err, errTLSPinMismatch) || !errors.Is(err, errRFTLSRefused) {
+		t.Errorf("Changed cert: expected mismatch refusal, got %v", err)
+	}
+	if !changed || (tlsPins[targ].MismatchFingerprint != fpB) {
+		t.Errorf("Changed cert: mismatch not recorded: %+v", tlsPins[targ])
+	}
+	changed, _ = checkTLSPin(targ, certB, true)
+	if changed {
+		t.Errorf("Changed cert again: expected no change")
+	}
+	changed, err = checkTLSPin(targ, certA, true)
+	if !changed || (err != nil) || (tlsPins[targ].MismatchFingerprint != "") {
+		t.Errorf("Pinned cert back: expected mismatch cleared, got %+v, err %v",
+			tlsPins[targ], err)
+	}
+
+	//Installing a cert moves the pin, keeping the old cert accepted until
+	//the new one is served.
+
+	retData := rfCertPostRsp{Targets: []certRsp{
+		{ID: targ, StatusCode: http.StatusOK},
+		{ID: "x0c0s1b0", StatusCode: http.StatusInternalServerError},
+	}}
+	pinPushedCerts(&retData, map[string]string{targ: pemB, "x0c0s1b0": pemA})
+	pin := tlsPins[targ]
+	if (pin.Fingerprint != fpB) || (pin.PrevFingerprint != fpA) || (pin.Source != TLSPinInstalled) {
+		t.Errorf("Installed cert: unexpected pin %+v", pin)
+	}
+	if _, ok := tlsPins["x0c0s1b0"]; ok {
+		t.Errorf("Failed install: pin created")
+	}
+	if _, err = checkTLSPin(targ, certA, true); err != nil {
+		t.Errorf("Installed cert not served yet: unexpected error %v", err)
+	}
+	if _, err = checkTLSPin(targ, certB, true); err != nil {
+		t.Errorf("Installed cert served: unexpected error %v", err)
+	}
+	if tlsPins[targ].PrevFingerprint != "" {
+		t.Errorf("Installed cert served: previous cert still accepted")
+	}
+	if _, err = checkTLSPin(targ, certA, true); err == nil {
+		t.Errorf("Old cert after install: expected refusal")
+	}
+}
+
+// Send a GET to a target through doOp(), returning the task and whether
+// the target got the request.
+
+func tlsPinDoOp(t *testing.T, targ string, nReqs *int32) (*trsapi.HttpTask, bool) {
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = 5 * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, 1)
+	populateTaskList(taskList, []string{targ}, "/redfish/v1/", http.MethodGet, nil)
+
+	before := atomic.LoadInt32(nReqs)
+	err := doOp(taskList)
+	if err != nil {
+		t.Fatalf("doOp() failed: %v", err)
+	}
+	return &taskList[0], atomic.LoadInt32(nReqs) != before
+}
+
+func TestTLSPinDoOp(t *testing.T) {
+	defer tlsPinSetup(t)()
+	var nReqs int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nReqs, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	targ := strings.TrimPrefix(srv.URL, "https://")
+	srvFP := certFingerprint(srv.Certificate())
+
+	dfltProtocol = "https"
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("TRS init failed: %v", err)
+	}
+
+	task, sent := tlsPinDoOp(t, targ, &nReqs)
+	if !sent || (getStatusCode(task) != http.StatusOK) {
+		t.Fatalf("First use: request not sent, status %d", getStatusCode(task))
+	}
+	if tlsPins[targ].Fingerprint != srvFP {
+		t.Fatalf("First use: expected pin %s, got %+v", srvFP, tlsPins[targ])
+	}
+
+	pin := tlsPins[targ]
+	pin.Fingerprint = "0123"
+	tlsPins[targ] = pin
+	task, sent = tlsPinDoOp(t, targ, &nReqs)
+	if sent || (getStatusCode(task) != http.StatusServiceUnavailable) {
+		t.Errorf("Changed cert: expected refusal, sent %t, status %d",
+			sent, getStatusCode(task))
+	}
+	if msg := getStatusMsg(task); !strings.Contains(msg, "acknowledged") {
+		t.Errorf("Changed cert: unexpected status message '%s'", msg)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost"+API_TLS_PINS+"/"+targ+"/ack", nil)
+	req = mux.SetURLVars(req, map[string]string{"xname": targ})
+	w := httptest.NewRecorder()
+	doBMCTLSPinAckPost(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	task, sent = tlsPinDoOp(t, targ, &nReqs)
+	if !sent || (getStatusCode(task) != http.StatusOK) {
+		t.Errorf("Acknowledged cert: request not sent, status %d", getStatusCode(task))
+	}
+
+	//Plain http is not pinned
+
+	tlsPins = make(map[string]tlsPin)
+	dfltProtocol = "http"
+	httpSrv := httptest.NewServer(srv.Config.Handler)
+	defer httpSrv.Close()
+	httpTarg := strings.TrimPrefix(httpSrv.URL, "http://")
+	if _, sent = tlsPinDoOp(t, httpTarg, &nReqs); !sent || (len(tlsPins) != 0) {
+		t.Errorf("Plain http: expected request sent and no pin, sent %t, %d pins",
+			sent, len(tlsPins))
+	}
+}
+
+// Pins are checked in the handshake of the connection sending a task,
+// with no pre-flight check, and need SCSD's secure store when Vault is
+// enabled.
+
+func TestTLSPinLaunch(t *testing.T) {
+	defer tlsPinSetup(t)()
+	var nReqs int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nReqs, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	targ := strings.TrimPrefix(srv.URL, "https://")
+	srvFP := certFingerprint(srv.Certificate())
+	savedStore := secureStore
+	defer func() { secureStore = savedStore }()
+
+	dfltProtocol = "https"
+	tloc = &tlocLocal
+	launch := func() (*trsapi.HttpTask, bool) {
+		var sourceTL trsapi.HttpTask
+		sourceTL.Timeout = 5 * time.Second
+		sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+		taskList := tloc.CreateTaskList(&sourceTL, 1)
+		populateTaskList(taskList, []string{targ}, "/redfish/v1/", http.MethodGet, nil)
+		before := atomic.LoadInt32(&nReqs)
+		rchan, err := launchRFTasks(&taskList)
+		if err != nil {
+			t.Fatalf("launchRFTasks() failed: %v", err)
+		}
+		task := <-rchan
+		if task.Request.Response != nil {
+			task.Request.Response.Body.Close()
+		}
+		return task, atomic.LoadInt32(&nReqs) != before
+	}
+
+	tlsPins[targ] = tlsPin{ID: targ, Fingerprint: "0123", Source: TLSPinFirstUse}
+	tlsPinsLoaded = true
+	task, sent := launch()
+	if sent || !taskRFTLSRefused(task) || !errors.Is(*task.Err, errTLSPinMismatch) {
+		t.Errorf("Changed cert: expected refusal, sent %t, err %v", sent, *task.Err)
+	}
+	if tlsPins[targ].MismatchFingerprint != srvFP {
+		t.Errorf("Changed cert: expected mismatch %s recorded, got %+v", srvFP, tlsPins[targ])
+	}
+
+	vaultOn := true
+	appParams.VaultEnable = &vaultOn
+	secureStore = nil
+	tlsPins = make(map[string]tlsPin)
+	tlsPinsLoaded = false
+	task, sent = launch()
+	if sent || !taskRFTLSRefused(task) ||
+		!strings.Contains(getStatusMsg(task), "Vault not connected") {
+		t.Errorf("No secure store: expected refusal, sent %t, message '%s'",
+			sent, getStatusMsg(task))
+	}
+}
+
+func TestTLSPinAPI(t *testing.T) {
+	defer tlsPinSetup(t)()
+	tlsPins["x0c0s1b0"] = tlsPin{ID: "x0c0s1b0", Fingerprint: "aa", Source: TLSPinFirstUse}
+	tlsPins["x0c0s0b0"] = tlsPin{ID: "x0c0s0b0", Fingerprint: "bb", Source: TLSPinFirstUse}
+
+	doReq := func(method string, xname string, suffix string, body string,
+		handler func(http.ResponseWriter, *http.Request)) *httptest.ResponseRecorder {
+		url := "http://localhost" + API_TLS_PINS
+		if xname != "" {
+			url += "/" + xname + suffix
+		}
+		req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+		if xname != "" {
+			req = mux.SetURLVars(req, map[string]string{"xname": xname})
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := doReq(http.MethodGet, "", "", "", doBMCTLSPinsGet)
+	var rsp tlsPinsRsp
+	if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+		t.Fatalf("Error unmarshalling pins: %v", err)
+	}
+	if !rsp.Enabled || (len(rsp.Pins) != 2) || (rsp.Pins[0].ID != "x0c0s0b0") {
+		t.Errorf("Unexpected pin list: %+v", rsp)
+	}
+
+	if w = doReq(http.MethodGet, "X0C0S1B0", "", "", doBMCTLSPinGet); w.Code != http.StatusOK {
+		t.Errorf("Get pin: expected 200, got %d", w.Code)
+	}
+	if w = doReq(http.MethodGet, "x0c0s9b0", "", "", doBMCTLSPinGet); w.Code != http.StatusNotFound {
+		t.Errorf("Get missing pin: expected 404, got %d", w.Code)
+	}
+
+	if w = doReq(http.MethodPost, "x0c0s0b0", "/ack", "", doBMCTLSPinAckPost); w.Code != http.StatusConflict {
+		t.Errorf("Ack without mismatch: expected 409, got %d", w.Code)
+	}
+	pin := tlsPins["x0c0s0b0"]
+	pin.MismatchFingerprint = "ccdd"
+	tlsPins["x0c0s0b0"] = pin
+	if w = doReq(http.MethodPost, "x0c0s0b0", "/ack", `{"Fingerprint":"ee"}`, doBMCTLSPinAckPost); w.Code != http.StatusConflict {
+		t.Errorf("Ack of other cert: expected 409, got %d", w.Code)
+	}
+	w = doReq(http.MethodPost, "x0c0s0b0", "/ack", `{"Fingerprint":"CC:DD"}`, doBMCTLSPinAckPost)
+	if w.Code != http.StatusOK {
+		t.Errorf("Ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if pin = tlsPins["x0c0s0b0"]; (pin.Fingerprint != "ccdd") || (pin.Source != TLSPinAcknowledged) ||
+		(pin.MismatchFingerprint != "") {
+		t.Errorf("Ack: unexpected pin %+v", pin)
+	}
+
+	if w = doReq(http.MethodDelete, "x0c0s0b0", "", "", doBMCTLSPinDelete); w.Code != http.StatusNoContent {
+		t.Errorf("Reset: expected 204, got %d", w.Code)
+	}
+	if _, ok := tlsPins["x0c0s0b0"]; ok {
+		t.Errorf("Reset: pin not removed")
+	}
+	if w = doReq(http.MethodDelete, "x0c0s0b0", "", "", doBMCTLSPinDelete); w.Code != http.StatusNotFound {
+		t.Errorf("Reset of missing pin: expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,410 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+// Automatic BMC cert renewal.  When enabled, a background task periodically
+// looks at the Vault certs of the cert domains of all BMCs known to HSM.
+// Certs expiring within the renewal window are re-created, stored and
+// applied to the BMCs of their domain, and the outcome is recorded for
+// the /v1/bmc/certrenew GET status endpoint.
+
+var certRenewEnable = false
+var certRenewDays = 30
+var certRenewInterval = 86400 //seconds
+var certRenewDomain = "cabinet"
+
+// Number of renewals kept for the status endpoint.
+const certRenewHistoryMax = 100
+
+// Outcome of one cert renewal.  Targets holds the outcome of applying the
+// new cert to each BMC of the domain.
+
+type certRenewal struct {
+	DomainID        string     `json:"DomainID"`
+	Time            time.Time  `json:"Time"`
+	OldNotAfter     time.Time  `json:"OldNotAfter"`
+	NewNotAfter     *time.Time `json:"NewNotAfter,omitempty"`
+	StatusCode      int        `json:"StatusCode"`
+	StatusMsg       string     `json:"StatusMsg"`
+	ExternalRenewal bool       `json:"ExternalRenewal,omitempty"` // uploaded cert, not renewed
+	Targets         []certRsp  `json:"Targets,omitempty"`
+}
+
+// Return of /v1/bmc/certrenew GET.  Renewals are listed most recent first.
+
+type certRenewStatus struct {
+	Enabled       bool          `json:"Enabled"`
+	CertDomain    string        `json:"CertDomain"`
+	WindowDays    int           `json:"WindowDays"`
+	Interval      int           `json:"Interval"`
+	LastRun       *time.Time    `json:"LastRun,omitempty"`
+	LastRunStatus string        `json:"LastRunStatus,omitempty"`
+	Renewals      []certRenewal `json:"Renewals"`
+}
+
+var certRenewLock sync.Mutex
+var certRenewHistory []certRenewal
+var certRenewLastRun *time.Time
+var certRenewLastRunStatus string
+
+// Record the outcome of a renewal, dropping the oldest ones past
+// certRenewHistoryMax.  An uploaded cert needing external renewal is only
+// recorded again once its expiration date changes.
+
+func recordCertRenewal(renewal certRenewal) {
+	certRenewLock.Lock()
+	defer certRenewLock.Unlock()
+	if renewal.ExternalRenewal {
+		for _, prev := range certRenewHistory {
+			if prev.DomainID == renewal.DomainID {
+				if prev.ExternalRenewal && prev.OldNotAfter.Equal(renewal.OldNotAfter) {
+					return
+				}
+				break
+			}
+		}
+	}
+	certRenewHistory = append([]certRenewal{renewal}, certRenewHistory...)
+	if len(certRenewHistory) > certRenewHistoryMax {
+		certRenewHistory = certRenewHistory[:certRenewHistoryMax]
+	}
+}
+
+// Record the outcome of a renewal run.
+
+func recordCertRenewRun(runTime time.Time, err error) {
+	certRenewLock.Lock()
+	defer certRenewLock.Unlock()
+	certRenewLastRun = &runTime
+	if err != nil {
+		certRenewLastRunStatus = err.Error()
+	} else {
+		certRenewLastRunStatus = "OK"
+	}
+}
+
+// Returns the current renewal settings and history.
+
+func getCertRenewStatus() certRenewStatus {
+	certRenewLock.Lock()
+	defer certRenewLock.Unlock()
+	status := certRenewStatus{Enabled: certRenewEnable,
+		CertDomain:    certRenewDomain,
+		WindowDays:    certRenewDays,
+		Interval:      certRenewInterval,
+		LastRun:       certRenewLastRun,
+		LastRunStatus: certRenewLastRunStatus,
+		Renewals:      make([]certRenewal, len(certRenewHistory)),
+	}
+	copy(status.Renewals, certRenewHistory)
+	return status
+}
+
+// Get all BMCs known to HSM.
+//
+// Return: List of BMC components; error if HSM could not be queried.
+
+func getHSMBMCs() ([]hsmComponent, error) {
+	rsp, err := doHSMGet(appParams.SmdURL + "/State/Components?type=NodeBMC&type=ChassisBMC&type=RouterBMC&type=CabinetBMC&stateonly=true")
+	if err != nil {
+		return nil, fmt.Errorf("Problem getting BMCs from HSM: %v", err)
+	}
+	var compData hsmComponentList
+	err = json.Unmarshal(rsp, &compData)
+	if err != nil {
+		return nil, fmt.Errorf("Problem unmarshalling HSM data: %v", err)
+	}
+	return compData.Components, nil
+}
+
+// Group BMCs by cert domain ID.  BMCs which are not in any domain, such as
+// non-XNames, are skipped.
+//
+// bmcs(in):       BMC components.
+// certDomain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// Return:         Map of domain ID to the BMCs in the domain.
+
+func groupBMCsByCertDomain(bmcs []hsmComponent, certDomain string) map[string][]hsmComponent {
+	domMap := make(map[string][]hsmComponent)
+	for _, bmc := range bmcs {
+		domID, err := hms_certs.CheckDomain([]string{bmc.ID}, certDomain)
+		if err != nil {
+			logger.Tracef("Cert renewal: skipping '%s': %v", bmc.ID, err)
+			continue
+		}
+		domMap[domID] = append(domMap[domID], bmc)
+	}
+	return domMap
+}
+
+// Check if a Vault cert expires within the renewal window.
+//
+// vcert(in): Cert data from Vault.
+// days(in):  Renewal window in days.
+// now(in):   Time from which to count the days.
+// Return:    Expiration date of the cert; true if the cert needs renewal;
+//            error if the cert could not be parsed.
+
+func certNeedsRenewal(vcert *hms_certs.VaultCertData, days int, now time.Time) (time.Time, bool, error) {
+	cert, err := parseLeafCert(vcert.Data.Certificate)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	limit := now.Add(time.Duration(days) * 24 * time.Hour)
+	return cert.NotAfter.UTC(), cert.NotAfter.Before(limit), nil
+}
+
+// Apply a cert/key pair to a list of BMCs.
+//
+// targs(in): BMC XNames.
+// vcert(in): Cert data from Vault.
+// Return:    Outcome for each BMC; error if the operation failed as a whole.
+
+func applyDomainCert(targs []string, vcert *hms_certs.VaultCertData) (rfCertPostRsp, error) {
+	var retData rfCertPostRsp
+	var sourceTL trsapi.HttpTask
+
+	sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, len(targs))
+	populateTaskList(taskList, targs, RFROOT_API, http.MethodGet, nil)
+
+	certs := make([]bmcCertData, len(taskList))
+	for ii := 0; ii < len(taskList); ii++ {
+		certs[ii].Cert = vcert.Data.Certificate
+		certs[ii].Key = vcert.Data.PrivateKey
+	}
+
+	err := setCerts(taskList, certs, &retData)
+	return retData, err
+}
+
+// Renew the cert of one domain: create a new cert, store it in Vault and
+// apply it to the BMCs of the domain which are in a good HSM state.  The
+// new cert is created with the custom options the current one was created
+// with, if any.
+//
+// domID(in):       Domain ID, e.g. x1000.
+// certDomain(in):  Cert domain, e.g. hms_certs.CertDomainCabinet.
+// bmcs(in):        BMCs in the domain.
+// vcert(in):       Current cert data from Vault.
+// meta(in):        SCSD data of the current cert.
+// oldNotAfter(in): Expiration date of the current cert.
+// Return:          Outcome of the renewal.
+
+func renewDomainCert(domID string, certDomain string, bmcs []hsmComponent,
+	vcert *hms_certs.VaultCertData, meta certMeta, oldNotAfter time.Time) certRenewal {
+	renewal := certRenewal{DomainID: domID, Time: time.Now().UTC(),
+		OldNotAfter: oldNotAfter,
+		StatusCode:  http.StatusOK,
+		StatusMsg:   "OK"}
+
+	var err error
+	newCert := new(hms_certs.VaultCertData)
+	if meta.Options != nil {
+		err = createCustomCert(domID, certDomain, meta.Options.toCreatePost(vcert.Data.FQDN), newCert)
+	} else {
+		err = hms_certs.CreateCert([]string{domID}, certDomain, vcert.Data.FQDN, newCert)
+	}
+	if err != nil {
+		renewal.StatusCode = http.StatusInternalServerError
+		renewal.StatusMsg = fmt.Sprintf("Error creating cert for domain '%s': %v",
+			domID, err)
+		return renewal
+	}
+	cert, err := parseLeafCert(newCert.Data.Certificate)
+	if err == nil {
+		notAfter := cert.NotAfter.UTC()
+		renewal.NewNotAfter = &notAfter
+	}
+
+	err = hms_certs.StoreCertData(domID, *newCert)
+	if err != nil {
+		renewal.StatusCode = http.StatusInternalServerError
+		renewal.StatusMsg = fmt.Sprintf("ERROR storing cert for domain '%s': %v",
+			domID, err)
+		return renewal
+	}
+
+	var targs []string
+	for _, bmc := range bmcs {
+		if goodHSMState(bmc.State) {
+			targs = append(targs, bmc.ID)
+			continue
+		}
+		renewal.Targets = append(renewal.Targets, certRsp{ID: bmc.ID,
+			StatusCode: http.StatusUnprocessableEntity,
+			StatusMsg: fmt.Sprintf("Target '%s' in bad HSM state: %s",
+				bmc.ID, bmc.State)})
+	}
+	if len(targs) > 0 {
+		rsp, err := applyDomainCert(targs, newCert)
+		renewal.Targets = append(renewal.Targets, rsp.Targets...)
+		if err != nil {
+			renewal.StatusCode = http.StatusInternalServerError
+			renewal.StatusMsg = fmt.Sprintf("Cert for domain '%s' renewed, but not applied to BMCs: %v",
+				domID, err)
+			return renewal
+		}
+	}
+
+	var bads []string
+	for _, trsp := range renewal.Targets {
+		if !statusCodeOK(trsp.StatusCode) {
+			bads = append(bads, trsp.ID)
+		}
+	}
+	if len(bads) > 0 {
+		renewal.StatusCode = http.StatusInternalServerError
+		renewal.StatusMsg = fmt.Sprintf("Cert for domain '%s' renewed, but not applied to: %s",
+			domID, strings.Join(bads, ","))
+	}
+	return renewal
+}
+
+// Look for Vault certs of all BMC cert domains expiring within the renewal
+// window, and renew them.
+//
+// now(in): Time from which to count the renewal window.
+// Return:  Error if the run could not be done at all.
+
+func renewExpiringCerts(now time.Time) error {
+	certDomain, err := userDomainToCertDomain(certRenewDomain)
+	if err != nil {
+		return err
+	}
+	bmcs, err := getHSMBMCs()
+	if err != nil {
+		return err
+	}
+
+	domMap := groupBMCsByCertDomain(bmcs, certDomain)
+	domIDs := make([]string, 0, len(domMap))
+	for domID := range domMap {
+		domIDs = append(domIDs, domID)
+	}
+	sort.Strings(domIDs)
+
+	for _, domID := range domIDs {
+		vcert, err := hms_certs.FetchCertData(domMap[domID][0].ID, certDomain)
+		if err != nil {
+			//No cert for this domain, nothing to renew.
+			logger.Tracef("Cert renewal: no cert for domain '%s': %v", domID, err)
+			continue
+		}
+		notAfter, renew, err := certNeedsRenewal(&vcert, certRenewDays, now)
+		if err != nil {
+			recordCertRenewal(certRenewal{DomainID: domID, Time: time.Now().UTC(),
+				StatusCode: http.StatusInternalServerError,
+				StatusMsg: fmt.Sprintf("ERROR parsing cert for domain '%s': %v",
+					domID, err)})
+			continue
+		}
+		if !renew {
+			continue
+		}
+
+		//Uploaded certs were issued elsewhere, replacing them with one of
+		//our own would drop the site's CA.  Only report them.
+
+		meta, err := fetchCertMeta(domID)
+		if err != nil {
+			recordCertRenewal(certRenewal{DomainID: domID, Time: time.Now().UTC(),
+				OldNotAfter: notAfter,
+				StatusCode:  http.StatusInternalServerError,
+				StatusMsg:   fmt.Sprintf("ERROR: not renewing cert for domain '%s': %v", domID, err)})
+			continue
+		}
+		if meta.Source == CertSourceUploaded {
+			logger.Warnf("Uploaded cert for domain '%s' expires %s, it must be renewed externally.",
+				domID, notAfter.Format(time.RFC3339))
+			recordCertRenewal(certRenewal{DomainID: domID, Time: time.Now().UTC(),
+				OldNotAfter:     notAfter,
+				StatusCode:      http.StatusConflict,
+				StatusMsg:       fmt.Sprintf("Cert for domain '%s' was uploaded, it must be renewed externally and uploaded again", domID),
+				ExternalRenewal: true})
+			continue
+		}
+
+		logger.Infof("Cert for domain '%s' expires %s, renewing.",
+			domID, notAfter.Format(time.RFC3339))
+		renewal := renewDomainCert(domID, certDomain, domMap[domID], &vcert, meta, notAfter)
+		if !statusCodeOK(renewal.StatusCode) {
+			logger.Errorf("Cert renewal: %s", renewal.StatusMsg)
+		}
+		recordCertRenewal(renewal)
+	}
+
+	return nil
+}
+
+// Background cert renewal task.  Runs until the service shuts down.
+
+func certRenewTask() {
+	logger.Infof("Cert renewal task started, domain: %s, window: %d days, interval: %d seconds.",
+		certRenewDomain, certRenewDays, certRenewInterval)
+
+	for Running {
+		now := time.Now()
+		err := renewExpiringCerts(now)
+		if err != nil {
+			logger.Errorf("Cert renewal run failed: %v", err)
+		}
+		recordCertRenewRun(now.UTC(), err)
+		time.Sleep(time.Duration(certRenewInterval) * time.Second)
+	}
+}
+
+// Show the cert renewal settings and recent renewals.
+
+func doBMCCertRenewGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	status := getCertRenewStatus()
+
+	ba, baerr := json.Marshal(&status)
+	if baerr != nil {
+		emsg := fmt.Sprintf("ERROR marshalling response data: %v", baerr)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
@@ -0,0 +1,199 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+func TestGroupBMCsByCertDomain(t *testing.T) {
+	loggerSetup()
+	bmcs := []hsmComponent{{ID: "x0c0s1b0"}, {ID: "x1c0s1b0"}, {ID: "x0c0s2b0"}, {ID: "foo"}}
+
+	domMap := groupBMCsByCertDomain(bmcs, hms_certs.CertDomainCabinet)
+	if len(domMap) != 2 || len(domMap["x0"]) != 2 || len(domMap["x1"]) != 1 {
+		t.Errorf("Unexpected cabinet domains: %v", domMap)
+	}
+	domMap = groupBMCsByCertDomain(bmcs, hms_certs.CertDomainBMC)
+	if len(domMap) != 3 || len(domMap["x0c0s2b0"]) != 1 {
+		t.Errorf("Unexpected BMC domains: %v", domMap)
+	}
+}
+
+func TestCertNeedsRenewal(t *testing.T) {
+	cert, certPEM, _ := makeTestCert(t, "x0", 20*24*time.Hour)
+	vcert := &hms_certs.VaultCertData{Data: hms_certs.CertInfo{Certificate: certPEM}}
+
+	notAfter, renew, err := certNeedsRenewal(vcert, 30, time.Now())
+	if err != nil || !renew || !notAfter.Equal(cert.NotAfter) {
+		t.Errorf("Expected renewal of a cert expiring in 20 days, got %s %t %v",
+			notAfter, renew, err)
+	}
+	_, renew, _ = certNeedsRenewal(vcert, 10, time.Now())
+	if renew {
+		t.Errorf("Expected no renewal with a 10 day window")
+	}
+	vcert.Data.Certificate = "junk"
+	if _, _, err = certNeedsRenewal(vcert, 30, time.Now()); err == nil {
+		t.Errorf("Expected an error for a bad cert")
+	}
+}
+
+func TestRecordCertRenewal(t *testing.T) {
+	saved := certRenewHistory
+	defer func() { certRenewHistory = saved }()
+	certRenewHistory = nil
+
+	for ii := 0; ii < certRenewHistoryMax+5; ii++ {
+		recordCertRenewal(certRenewal{DomainID: fmt.Sprintf("x%d", ii)})
+	}
+	status := getCertRenewStatus()
+	if len(status.Renewals) != certRenewHistoryMax {
+		t.Errorf("Expected %d renewals, got %d", certRenewHistoryMax, len(status.Renewals))
+	}
+	if status.Renewals[0].DomainID != fmt.Sprintf("x%d", certRenewHistoryMax+4) {
+		t.Errorf("Expected the most recent renewal first, got %s", status.Renewals[0].DomainID)
+	}
+}
+
+func TestRenewExpiringCerts(t *testing.T) {
+	loggerSetup()
+	savedURL, savedHistory := appParams.SmdURL, certRenewHistory
+	savedDomain, savedDays := certRenewDomain, certRenewDays
+	defer func() {
+		appParams.SmdURL, certRenewHistory = savedURL, savedHistory
+		certRenewDomain, certRenewDays = savedDomain, savedDays
+	}()
+	certRenewHistory = nil
+	certRenewDomain, certRenewDays = "cabinet", 30
+
+	os.Setenv("VAULT_ENABLE", "0")
+	defer os.Unsetenv("VAULT_ENABLE")
+	hms_certs.Init(nil)
+	savedVault := appParams.VaultEnable
+	defer func() { appParams.VaultEnable = savedVault }()
+	appParams.VaultEnable = nil
+
+	smServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Components":[{"ID":"x5c0s1b0","State":"Ready"},{"ID":"x6c0s1b0","State":"Ready"},{"ID":"x7c0s1b0","State":"Off"}]}`))
+	}))
+	defer smServer.Close()
+	appParams.SmdURL = smServer.URL
+
+	//x5 is good for a year, x6 has a bad cert and x7 an uploaded cert
+	//expiring in 10 days.
+
+	_, certPEM, _ := makeTestCert(t, "x5", 365*24*time.Hour)
+	hms_certs.StoreCertData("x5", hms_certs.VaultCertData{Data: hms_certs.CertInfo{Certificate: certPEM}})
+	defer hms_certs.DeleteCertData("x5", true)
+	hms_certs.StoreCertData("x6", hms_certs.VaultCertData{Data: hms_certs.CertInfo{Certificate: "junk"}})
+	defer hms_certs.DeleteCertData("x6", true)
+	_, certPEM, _ = makeTestCert(t, "x7", 10*24*time.Hour)
+	hms_certs.StoreCertData("x7", hms_certs.VaultCertData{Data: hms_certs.CertInfo{Certificate: certPEM}})
+	defer hms_certs.DeleteCertData("x7", true)
+	storeCertMeta("x7", certMeta{Source: CertSourceUploaded})
+	defer deleteCertMeta("x7")
+
+	err := renewExpiringCerts(time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	status := getCertRenewStatus()
+	if len(status.Renewals) != 2 || status.Renewals[0].DomainID != "x7" ||
+		status.Renewals[1].DomainID != "x6" ||
+		status.Renewals[1].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected a failure for x6 and a report for x7, got %v", status.Renewals)
+	}
+	if !status.Renewals[0].ExternalRenewal || status.Renewals[0].StatusCode != http.StatusConflict {
+		t.Errorf("Expected the uploaded x7 cert to need external renewal, got %v", status.Renewals[0])
+	}
+	vcert, _ := hms_certs.FetchCertData("x7", hms_certs.CertDomainCabinet)
+	if vcert.Data.Certificate != certPEM {
+		t.Errorf("Expected the uploaded x7 cert to be kept")
+	}
+
+	//The uploaded cert is only reported once.
+
+	certRenewHistory = certRenewHistory[:1]
+	renewExpiringCerts(time.Now())
+	status = getCertRenewStatus()
+	if len(status.Renewals) != 2 || status.Renewals[1].DomainID != "x7" {
+		t.Errorf("Expected the x7 report once, got %v", status.Renewals)
+	}
+
+	smServer.Close()
+	if err = renewExpiringCerts(time.Now()); err == nil {
+		t.Errorf("Expected an error when HSM can't be reached")
+	}
+}
+
+func TestRenewDomainCertOpts(t *testing.T) {
+	loggerSetup()
+	os.Setenv("VAULT_ENABLE", "0")
+	defer os.Unsetenv("VAULT_ENABLE")
+	hms_certs.Init(nil)
+
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Test PKI CA", nil, true, year, nil)
+	fs := &fakeCreateSigner{ca: root}
+	saveSigner := signCreateCSR
+	signCreateCSR = fs.sign
+	defer func() { signCreateCSR = saveSigner }()
+	defer hms_certs.DeleteCertData("x1000c0s0b0", true)
+
+	//Only a BMC in a bad state, so nothing is applied.
+
+	bmcs := []hsmComponent{{ID: "x1000c0s0b0", State: "Off"}}
+	vcert := hms_certs.VaultCertData{Data: hms_certs.CertInfo{FQDN: ".hmn"}}
+	meta := certMeta{Source: CertSourceCreated,
+		Options: &certCreateOpts{AltNames: []string{"bmc-s0"},
+			IPAddresses:  []string{"10.254.1.5"},
+			KeyType:      "ECDSA",
+			ValidityDays: 30}}
+
+	renewal := renewDomainCert("x1000c0s0b0", hms_certs.CertDomainBMC, bmcs, &vcert, meta, time.Now())
+	if (renewal.StatusCode != http.StatusInternalServerError) || (len(renewal.Targets) != 1) ||
+		(renewal.Targets[0].StatusCode != http.StatusUnprocessableEntity) {
+		t.Errorf("Expected only the BMC in a bad state to fail, got %v", renewal)
+	}
+	if (fs.req.TTL != "720h") || (fs.req.AltNames != "x1000c0s0b0.hmn,bmc-s0") ||
+		(fs.req.IPSANs != "10.254.1.5") {
+		t.Errorf("Expected the stored cert options to be used, got %+v", fs.req)
+	}
+
+	stored, err := hms_certs.FetchCertData("x1000c0s0b0", hms_certs.CertDomainBMC)
+	if err != nil {
+		t.Fatalf("Renewed cert not stored: %v", err)
+	}
+	cert, err := parseLeafCert(stored.Data.Certificate)
+	if (err != nil) || (stored.Data.PrivateKeyType != "ec") || (len(cert.IPAddresses) != 1) {
+		t.Errorf("Renewed cert doesn't have the stored options: %v %+v", err, stored.Data)
+	}
+}
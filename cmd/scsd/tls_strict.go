@@ -0,0 +1,395 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Strict TLS mode.  Every Redfish request SCSD sends carries BMC admin
+// creds, and many carry private keys or passwords in their payloads.  If
+// the CA bundle can't be loaded, TRS uses unvalidated https, and even with
+// a CA bundle it retries requests which fail TLS validation without it.
+//
+// In strict mode, Redfish operations are refused with a per-target error
+// until a CA bundle has been loaded.  Once one is, SCSD sends the requests
+// itself over connections validated against it, with no unvalidated
+// retry, so a target failing validation never gets a request.  Operations
+// which only look at the cert a BMC serves, with a TLS handshake and no
+// creds, are not affected.
+
+var strictTLS = false
+
+// Outbound Redfish TLS status, as reported by /v1/health.
+
+const (
+	RFTLSValidated   = "Validated"
+	RFTLSUnvalidated = "Not Validated"
+	RFTLSPlain       = "Plain HTTP"
+)
+
+var errRFTLSRefused = errors.New("refusing to send BMC creds")
+
+var rfTLSLock sync.Mutex
+var rfTLSPool *x509.CertPool
+
+// Redfish clients used in strict mode or with TLS pinning, by retry policy
+// and mode.  They are dropped when the CA bundle changes.
+
+type rfClientKey struct {
+	policy trsapi.RetryPolicy
+	strict bool
+	pinned bool
+}
+
+var rfClients = make(map[rfClientKey]*retryablehttp.Client)
+
+// Clients reading BMC service roots for identity checks, by mode.
+
+var rfIdentityClients = make(map[rfClientKey]*http.Client)
+
+// Set the CA bundle used to validate BMCs in strict mode.  This is the
+// bundle loaded into the Redfish transports.
+//
+// caBundle(in): PEM CA bundle; "" means none is loaded.
+// Return:       Error if the bundle holds no certs, in which case none is
+//               considered loaded.
+
+func setRFTLSCA(caBundle string) error {
+	var err error
+	pool := x509.NewCertPool()
+	if (caBundle == "") || !pool.AppendCertsFromPEM([]byte(caBundle)) {
+		pool = nil
+		if caBundle != "" {
+			err = fmt.Errorf("No CA certs found in CA bundle")
+		}
+	}
+	rfTLSLock.Lock()
+	defer rfTLSLock.Unlock()
+	rfTLSPool = pool
+	for key, client := range rfClients {
+		client.HTTPClient.CloseIdleConnections()
+		delete(rfClients, key)
+	}
+	for key, client := range rfIdentityClients {
+		client.CloseIdleConnections()
+		delete(rfIdentityClients, key)
+	}
+	return err
+}
+
+func getRFTLSPool() *x509.CertPool {
+	rfTLSLock.Lock()
+	defer rfTLSLock.Unlock()
+	return rfTLSPool
+}
+
+// Returns the current outbound Redfish TLS status.
+
+func redfishTLSStatus() string {
+	if dfltProtocol != "https" {
+		return RFTLSPlain
+	}
+	if getRFTLSPool() == nil {
+		return RFTLSUnvalidated
+	}
+	return RFTLSValidated
+}
+
+// Returns the mode reported by /v1/health.
+
+func redfishTLSMode() string {
+	if strictTLS {
+		return "Strict"
+	}
+	return "Permissive"
+}
+
+// Returns an error if all Redfish operations are to be refused, which is
+// when strict mode is on and outbound Redfish TLS is not validated.
+
+func rfTLSRefusal() error {
+	if !strictTLS {
+		return nil
+	}
+	status := redfishTLSStatus()
+	if status == RFTLSValidated {
+		return nil
+	}
+	return fmt.Errorf("Redfish TLS status is '%s', %w in strict TLS mode",
+		status, errRFTLSRefused)
+}
+
+// Check the tasks of a task list about to be launched against strict TLS
+// mode.  If no CA bundle is loaded, all tasks are refused.  Otherwise the
+// targets are validated by the transport which sends the tasks, see
+// launchRFTasks().
+//
+// taskList(in): Tasks about to be launched.
+// Return:       Indexes of the refused tasks.
+
+func strictTLSCheck(taskList []trsapi.HttpTask) []int {
+	var refused []int
+	if !strictTLS {
+		return refused
+	}
+
+	err := rfTLSRefusal()
+	if err == nil {
+		return refused
+	}
+	for ii := 0; ii < len(taskList); ii++ {
+		if !taskList[ii].Ignore && (taskList[ii].Request != nil) {
+			refuseTask(&taskList[ii], err)
+			refused = append(refused, ii)
+		}
+	}
+	return refused
+}
+
+// Returns the Redfish client for a retry policy, creating it if need be,
+// or nil in strict mode if no CA bundle is loaded.  Clients are set up like
+// the TRS ones, minus the unvalidated fallback.  In strict mode they
+// validate targets against the CA bundle; with TLS pinning they check the
+// cert each target serves against its pin.
+
+func getRFClient(policy trsapi.RetryPolicy) *retryablehttp.Client {
+	rfTLSLock.Lock()
+	defer rfTLSLock.Unlock()
+	if strictTLS && (rfTLSPool == nil) {
+		return nil
+	}
+	key := rfClientKey{policy: policy, strict: strictTLS, pinned: tlsPinEnable}
+	if client, ok := rfClients[key]; ok {
+		return client
+	}
+
+	client := retryablehttp.NewClient()
+	client.HTTPClient.Transport = newRFTransport(true)
+	client.Logger = nil
+	client.RetryMax = trsapi.DFLT_RETRY_MAX
+	client.RetryWaitMax = trsapi.DFLT_BACKOFF_MAX * time.Second
+	if policy.Retries > 0 {
+		client.RetryMax = policy.Retries
+	}
+	if policy.BackoffTimeout > 0 {
+		client.RetryWaitMax = policy.BackoffTimeout
+	}
+	client.CheckRetry = rfCheckRetry
+	rfClients[key] = client
+	return client
+}
+
+// Returns the client used to read BMC service roots for identity checks,
+// creating it if need be, or nil in strict mode if no CA bundle is loaded.
+// It validates targets like the Redfish clients do, so a target is never
+// checked with less scrutiny than the requests sent to it after.  It
+// doesn't pin targets which have no pin yet, as their identity isn't
+// known until the check is done.
+
+func getRFIdentityClient() *http.Client {
+	rfTLSLock.Lock()
+	defer rfTLSLock.Unlock()
+	if strictTLS && (rfTLSPool == nil) {
+		return nil
+	}
+	key := rfClientKey{strict: strictTLS, pinned: tlsPinEnable}
+	if client, ok := rfIdentityClients[key]; ok {
+		return client
+	}
+	client := &http.Client{Transport: newRFTransport(false)}
+	rfIdentityClients[key] = client
+	return client
+}
+
+// Returns a Redfish transport for the current mode.  Must be called with
+// rfTLSLock held.
+//
+// pinNew(in): With TLS pinning, pin targets which have no pin yet.
+// Return:     Transport validating targets against the CA bundle in strict
+//             mode, and checking their certs against their pins with TLS
+//             pinning.
+
+func newRFTransport(pinNew bool) *http.Transport {
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if strictTLS {
+		tlsConfig = &tls.Config{RootCAs: rfTLSPool}
+	}
+	tr := &http.Transport{TLSClientConfig: tlsConfig}
+	if tlsPinEnable {
+		//No connection reuse, so that each request is checked against
+		//the current pin.
+		tr.DialTLSContext = tlsPinDialer(tlsConfig, pinNew)
+		tr.DisableKeepAlives = true
+	}
+	return tr
+}
+
+// Retry policy of SCSD's Redfish clients: TLS validation and pin failures
+// are never retried.
+
+func rfCheckRetry(ctx context.Context, rsp *http.Response, err error) (bool, error) {
+	if isRFTLSError(err) {
+		return false, err
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, rsp, err)
+}
+
+// Returns true if a request failed because its target didn't pass TLS
+// validation or the pin check.
+
+func isRFTLSError(err error) bool {
+	var verr *tls.CertificateVerificationError
+	return errors.As(err, &verr) || errors.Is(err, errRFTLSRefused)
+}
+
+// Response body which releases the request context when closed.
+
+type rfTaskBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *rfTaskBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Send a task with SCSD's Redfish client.  A target which fails TLS
+// validation or the pin check fails the handshake, so no request, and no
+// creds, are sent to it; the task is marked refused.
+//
+// tp(inout): Task to send; gets its response or error.
+
+func sendRFTask(tp *trsapi.HttpTask) {
+	client := getRFClient(tp.RetryPolicy)
+	if client == nil {
+		refuseTask(tp, fmt.Errorf("No CA bundle loaded, %w in strict TLS mode",
+			errRFTLSRefused))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(
+		withTLSPinTarg(context.Background(), tp.Request.URL.Host), tp.Timeout)
+	base.SetHTTPUserAgent(tp.Request, serviceName)
+	req, err := retryablehttp.FromRequest(tp.Request)
+	if err != nil {
+		cancel()
+		tp.Request.Response = nil
+		tp.Err = &err
+		return
+	}
+
+	rsp, err := client.Do(req.WithContext(ctx))
+	if isRFTLSError(err) {
+		cancel()
+		if !errors.Is(err, errRFTLSRefused) {
+			err = fmt.Errorf("TLS validation of '%s' failed, %w in strict TLS mode: %v",
+				tp.Request.URL.Host, errRFTLSRefused, err)
+		}
+		refuseTask(tp, err)
+		return
+	}
+	if rsp != nil {
+		rsp.Body = &rfTaskBody{ReadCloser: rsp.Body, cancel: cancel}
+	} else {
+		cancel()
+	}
+	tp.Request.Response = rsp
+	tp.Err = &err
+}
+
+// Launch a task list, like TRS Launch().  In strict mode or with TLS
+// pinning the tasks are sent with SCSD's own clients instead of TRS, which
+// retries requests failing TLS validation without it and can't check pins.
+//
+// taskList(inout): Tasks to launch.  Ignored tasks are skipped.
+// Return:          Chan getting each launched task when it completes;
+//                  error if nothing could be launched.
+
+func launchRFTasks(taskList *[]trsapi.HttpTask) (chan *trsapi.HttpTask, error) {
+	if !strictTLS && !tlsPinEnable {
+		return tloc.Launch(taskList)
+	}
+
+	rchan := make(chan *trsapi.HttpTask, len(*taskList)+1)
+	if len(*taskList) == 0 {
+		return rchan, fmt.Errorf("Empty task list, nothing to do.")
+	}
+	for ii := 0; ii < len(*taskList); ii++ {
+		if (*taskList)[ii].Ignore {
+			continue
+		}
+		go func(tp *trsapi.HttpTask) {
+			sendRFTask(tp)
+			rchan <- tp
+		}(&(*taskList)[ii])
+	}
+	return rchan, nil
+}
+
+// Mark a task as refused without sending it.  It gets a 503 response so
+// that handlers report it like any other failed target.
+
+func refuseTask(tp *trsapi.HttpTask, err error) {
+	logger.Errorf("Refusing request to '%s': %v", targFromTask(tp), err)
+	terr := err
+	tp.Err = &terr
+	tp.Request.Response = &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Status:     http.StatusText(http.StatusServiceUnavailable),
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    tp.Request,
+	}
+}
+
+// Returns true if a task was refused by strict TLS mode or TLS pinning.
+
+func taskRFTLSRefused(tp *trsapi.HttpTask) bool {
+	return (tp.Err != nil) && errors.Is(*tp.Err, errRFTLSRefused)
+}
+
+// Returns how the Redfish transports fall back when no CA bundle can be
+// loaded, for log messages.
+
+func rfTLSFallbackMsg() string {
+	if strictTLS {
+		return "refusing Redfish operations (strict TLS mode)"
+	}
+	return "falling back to unvalidated https for Redfish communications"
+}
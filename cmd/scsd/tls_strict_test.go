@@ -0,0 +1,272 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+// Save and restore the strict TLS related globals around a test.
+
+func strictTLSSetup(t *testing.T) func() {
+	loggerSetup()
+	savedStrict, savedProto := strictTLS, dfltProtocol
+	savedVault, savedPool := appParams.VaultEnable, getRFTLSPool()
+	return func() {
+		strictTLS, dfltProtocol = savedStrict, savedProto
+		appParams.VaultEnable = savedVault
+		rfTLSLock.Lock()
+		rfTLSPool = savedPool
+		rfTLSLock.Unlock()
+	}
+}
+
+func TestRedfishTLSStatus(t *testing.T) {
+	defer strictTLSSetup(t)()
+	_, caPEM, _ := makeTestCert(t, "Test CA", time.Hour)
+
+	dfltProtocol = "http"
+	if st := redfishTLSStatus(); st != RFTLSPlain {
+		t.Errorf("Expected '%s' with http, got '%s'", RFTLSPlain, st)
+	}
+
+	dfltProtocol = "https"
+	setRFTLSCA("")
+	if st := redfishTLSStatus(); st != RFTLSUnvalidated {
+		t.Errorf("Expected '%s' with no CA, got '%s'", RFTLSUnvalidated, st)
+	}
+	strictTLS = false
+	if err := rfTLSRefusal(); err != nil {
+		t.Errorf("Unexpected refusal in permissive mode: %v", err)
+	}
+	strictTLS = true
+	if err := rfTLSRefusal(); err == nil {
+		t.Errorf("Expected refusal in strict mode with no CA")
+	}
+
+	if err := setRFTLSCA("not a cert"); err == nil {
+		t.Errorf("Expected error setting a bad CA bundle")
+	}
+	if err := setRFTLSCA(caPEM); err != nil {
+		t.Fatalf("Error setting CA bundle: %v", err)
+	}
+	if st := redfishTLSStatus(); st != RFTLSValidated {
+		t.Errorf("Expected '%s' with a CA, got '%s'", RFTLSValidated, st)
+	}
+	if err := rfTLSRefusal(); err != nil {
+		t.Errorf("Unexpected refusal with a CA: %v", err)
+	}
+}
+
+func TestStrictTLSDoOp(t *testing.T) {
+	defer strictTLSSetup(t)()
+	var nReqs int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nReqs, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	targ := strings.TrimPrefix(srv.URL, "https://")
+	srvCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: srv.Certificate().Raw}))
+	_, otherCA, _ := makeTestCert(t, "Other CA", time.Hour)
+
+	dfltProtocol = "https"
+	appParams.VaultEnable = nil //no RF creds needed
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("TRS init failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		ca      string
+		refused bool
+	}{
+		{"permissive, no CA", false, "", false},
+		{"strict, no CA", true, "", true},
+		{"strict, wrong CA", true, otherCA, true},
+		{"strict, right CA", true, srvCA, false},
+	}
+
+	for _, tt := range tests {
+		strictTLS = tt.strict
+		if err := setRFTLSCA(tt.ca); err != nil {
+			t.Fatalf("%s: error setting CA bundle: %v", tt.name, err)
+		}
+		var sourceTL trsapi.HttpTask
+		sourceTL.Timeout = 5 * time.Second
+		sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+		taskList := tloc.CreateTaskList(&sourceTL, 1)
+		populateTaskList(taskList, []string{targ}, "/redfish/v1/", http.MethodGet, nil)
+
+		before := atomic.LoadInt32(&nReqs)
+		err := doOp(taskList)
+		if err != nil {
+			t.Fatalf("%s: doOp() failed: %v", tt.name, err)
+		}
+		sent := atomic.LoadInt32(&nReqs) != before
+		code := getStatusCode(&taskList[0])
+
+		if taskList[0].Ignore {
+			t.Errorf("%s: task left ignored", tt.name)
+		}
+		if tt.refused {
+			if sent {
+				t.Errorf("%s: request sent despite refusal", tt.name)
+			}
+			if code != http.StatusServiceUnavailable {
+				t.Errorf("%s: expected status %d, got %d", tt.name,
+					http.StatusServiceUnavailable, code)
+			}
+			if msg := getStatusMsg(&taskList[0]); !strings.Contains(msg, "strict TLS mode") {
+				t.Errorf("%s: unexpected status message '%s'", tt.name, msg)
+			}
+		} else {
+			if !sent || (code != http.StatusOK) {
+				t.Errorf("%s: expected request sent with status 200, sent %t, got %d",
+					tt.name, sent, code)
+			}
+		}
+	}
+}
+
+// Tasks launched in strict mode fail in the transport if their target
+// fails validation, with no unvalidated retry, even without the pre-flight
+// check.
+
+func TestLaunchRFTasks(t *testing.T) {
+	defer strictTLSSetup(t)()
+	var nReqs int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nReqs, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	targ := strings.TrimPrefix(srv.URL, "https://")
+	srvCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: srv.Certificate().Raw}))
+	_, otherCA, _ := makeTestCert(t, "Other CA", time.Hour)
+
+	strictTLS = true
+	dfltProtocol = "https"
+	tloc = &tlocLocal
+
+	tests := []struct {
+		name    string
+		ca      string
+		refused bool
+	}{
+		{"wrong CA", otherCA, true},
+		{"right CA", srvCA, false},
+		{"wrong CA again", otherCA, true},
+	}
+
+	for _, tt := range tests {
+		if err := setRFTLSCA(tt.ca); err != nil {
+			t.Fatalf("%s: error setting CA bundle: %v", tt.name, err)
+		}
+		var sourceTL trsapi.HttpTask
+		sourceTL.Timeout = 5 * time.Second
+		sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+		taskList := tloc.CreateTaskList(&sourceTL, 1)
+		populateTaskList(taskList, []string{targ}, "/redfish/v1/", http.MethodGet, nil)
+
+		before := atomic.LoadInt32(&nReqs)
+		rchan, err := launchRFTasks(&taskList)
+		if err != nil {
+			t.Fatalf("%s: launchRFTasks() failed: %v", tt.name, err)
+		}
+		task := <-rchan
+		sent := atomic.LoadInt32(&nReqs) != before
+		code := getStatusCode(task)
+
+		if tt.refused {
+			if sent || !taskRFTLSRefused(task) || (code != http.StatusServiceUnavailable) {
+				t.Errorf("%s: expected refusal with status %d, sent %t, refused %t, got %d",
+					tt.name, http.StatusServiceUnavailable, sent,
+					taskRFTLSRefused(task), code)
+			}
+			continue
+		}
+		if !sent || (code != http.StatusOK) {
+			t.Errorf("%s: expected request sent with status 200, sent %t, got %d",
+				tt.name, sent, code)
+		}
+		task.Request.Response.Body.Close()
+	}
+}
+
+func TestStrictTLSHealth(t *testing.T) {
+	defer strictTLSSetup(t)()
+	appParams.VaultEnable = nil
+	dfltProtocol = "https"
+	setRFTLSCA("")
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("TRS init failed: %v", err)
+	}
+
+	for _, strict := range []bool{false, true} {
+		strictTLS = strict
+		req := httptest.NewRequest(http.MethodGet, "http://localhost"+API_HEALTH, nil)
+		w := httptest.NewRecorder()
+		doHealthGet(w, req)
+		var health healthData
+		err := json.Unmarshal(w.Body.Bytes(), &health)
+		if err != nil {
+			t.Fatalf("Error unmarshalling health data: %v", err)
+		}
+		if health.RedfishTLSStatus != RFTLSUnvalidated {
+			t.Errorf("Expected Redfish TLS status '%s', got '%s'",
+				RFTLSUnvalidated, health.RedfishTLSStatus)
+		}
+		if health.RedfishTLSMode != redfishTLSMode() {
+			t.Errorf("Expected Redfish TLS mode '%s', got '%s'",
+				redfishTLSMode(), health.RedfishTLSMode)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "http://localhost"+API_READINESS, nil)
+		w = httptest.NewRecorder()
+		doReadinessGet(w, req)
+		exp := http.StatusNoContent
+		if strict {
+			exp = http.StatusServiceUnavailable
+		}
+		if w.Code != exp {
+			t.Errorf("Strict %t: expected readiness %d, got %d", strict, exp, w.Code)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+func TestSetCertsRiver(t *testing.T) {
+	loggerSetup()
+	savedProto, savedVault := dfltProtocol, appParams.VaultEnable
+	defer func() { dfltProtocol, appParams.VaultEnable = savedProto, savedVault }()
+	dfltProtocol = "http"
+	appParams.VaultEnable = nil //no RF creds needed
+
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("Error initializing TRS API: %v", err)
+	}
+
+	intelBMC := &fakeCSRBMC{t: t, chassis: "RackMount"}
+	intelSrv := httptest.NewServer(intelBMC)
+	defer intelSrv.Close()
+	gbBMC := &fakeCSRBMC{t: t, chassis: "Self", noActions: true}
+	gbSrv := httptest.NewServer(gbBMC)
+	defer gbSrv.Close()
+
+	intelURL, _ := url.Parse(intelSrv.URL)
+	gbURL, _ := url.Parse(gbSrv.URL)
+	targs := []string{intelURL.Host, gbURL.Host}
+
+	_, certPEM, keyPEM := makeTestCert(t, "x0c0s6b0", time.Hour)
+	certs := []bmcCertData{{Cert: certPEM, Key: keyPEM}, {Cert: certPEM, Key: keyPEM}}
+
+	var sourceTL trsapi.HttpTask
+	sourceTL.Timeout = 10 * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, len(targs))
+	populateTaskList(taskList, targs, RFROOT_API, http.MethodGet, nil)
+
+	var retData rfCertPostRsp
+	err = setCerts(taskList, certs, &retData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(retData.Targets) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %v", len(retData.Targets), retData.Targets)
+	}
+
+	for _, elm := range retData.Targets {
+		switch elm.ID {
+		case intelURL.Host:
+			if elm.StatusCode != http.StatusOK {
+				t.Errorf("Intel target failed: %d/%s", elm.StatusCode, elm.StatusMsg)
+			}
+		case gbURL.Host:
+			if elm.StatusCode != http.StatusNotImplemented {
+				t.Errorf("Expected 501 for Gigabyte target without ReplaceCertificate, got %d",
+					elm.StatusCode)
+			}
+		default:
+			t.Errorf("Unexpected target in results: '%s'", elm.ID)
+		}
+	}
+
+	pld := intelBMC.replacePld
+	if (pld.CertificateString == nil) ||
+		!strings.Contains(*pld.CertificateString, "BEGIN CERTIFICATE") ||
+		!strings.Contains(*pld.CertificateString, "PRIVATE KEY") {
+		t.Errorf("Bad Intel cert payload: %v", pld.CertificateString)
+	}
+	if (pld.CertificateType == nil) || (*pld.CertificateType != "PEM") {
+		t.Errorf("Bad Intel cert type: %v", pld.CertificateType)
+	}
+	if (pld.CertificateUri == nil) ||
+		(pld.CertificateUri.Uri != "/redfish/v1/Managers/BMC/NetworkProtocol/HTTPS/Certificates/1") {
+		t.Errorf("Bad Intel cert URI: %v", pld.CertificateUri)
+	}
+}
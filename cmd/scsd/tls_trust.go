@@ -0,0 +1,843 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+	trsapi "github.com/Cray-HPE/hms-trs-app-api/pkg/trs_http_api"
+)
+
+// CA trust bundles on BMCs.  BMCs need the CA certs to validate the TLS
+// servers they talk to, e.g. syslog, LDAP and HTTPS update servers.
+//
+// The trust store of a BMC is found like this:
+//
+// o GET /redfish/v1/Managers to get the manager (should be only one entry)
+// o GET /redfish/v1/Managers/X and follow SecurityPolicy (DMTF) or
+//   Oem.Hpe.Links.SecurityService (HPE).
+// o DMTF: GET the SecurityPolicy, its TLS.Client.TrustedCertificates is the
+//   trust store.  CA certs are installed by a POST to it.
+// o HPE:  GET the SecurityService, follow Links.CertAuth, and GET that.
+//   Its CACertificates is the trust store, CA certs are installed with the
+//   HpeCertAuth.ImportCACertificate action.
+//
+// Installed CA certs are members of the trust store and are removed with a
+// DELETE.
+
+// Used for /v1/bmc/trustcerts POST.  If CABundle is empty, the CA bundle
+// SCSD uses for its own Redfish TLS is installed.
+
+type trustCertPost struct {
+	Force    bool     `json:"Force"`
+	Targets  []string `json:"Targets"`
+	CABundle string   `json:"CABundle,omitempty"`
+}
+
+// Used for /v1/bmc/fetchtrustcerts POST
+
+type trustCertFetchPost struct {
+	Force   bool     `json:"Force"`
+	Targets []string `json:"Targets"`
+}
+
+// Used for /v1/bmc/deletetrustcerts POST.  Fingerprints are the SHA-256
+// fingerprints of the CA certs to remove, as returned by fetchtrustcerts.
+
+type trustCertDeletePost struct {
+	Force        bool     `json:"Force"`
+	Targets      []string `json:"Targets"`
+	Fingerprints []string `json:"Fingerprints"`
+}
+
+type trustCertInfo struct {
+	URI         string     `json:"URI"`
+	Subject     string     `json:"Subject,omitempty"`
+	Issuer      string     `json:"Issuer,omitempty"`
+	NotAfter    *time.Time `json:"NotAfter,omitempty"`
+	Fingerprint string     `json:"Fingerprint,omitempty"`
+}
+
+type trustCertRspElem struct {
+	ID         string          `json:"ID"`
+	StatusCode int             `json:"StatusCode"`
+	StatusMsg  string          `json:"StatusMsg"`
+	Certs      []trustCertInfo `json:"Certs,omitempty"`
+}
+
+type trustCertRsp struct {
+	Targets []trustCertRspElem `json:"Targets"`
+}
+
+// Redfish trust store discovery data
+
+type rfTrustManager struct {
+	SecurityPolicy rfODataID         `json:"SecurityPolicy"`
+	Oem            hpeManagerDataOem `json:"Oem"`
+}
+
+type rfODataID struct {
+	ID string `json:"@odata.id"`
+}
+
+type rfSecurityPolicy struct {
+	TLS rfSecurityPolicyTLS `json:"TLS"`
+}
+
+type rfSecurityPolicyTLS struct {
+	Client rfSecurityPolicyTLSClient `json:"Client"`
+}
+
+type rfSecurityPolicyTLSClient struct {
+	TrustedCertificates rfODataID `json:"TrustedCertificates"`
+}
+
+type hpeTrustSecurityService struct {
+	Links hpeTrustSecurityServiceLinks `json:"Links"`
+}
+
+type hpeTrustSecurityServiceLinks struct {
+	CertAuth rfODataID `json:"CertAuth"`
+}
+
+type hpeCertAuth struct {
+	CACertificates rfODataID          `json:"CACertificates"`
+	Actions        hpeCertAuthActions `json:"Actions"`
+}
+
+type hpeCertAuthActions struct {
+	ImportCACert hpeSecurityServiceHttpsCertActionsImport `json:"#HpeCertAuth.ImportCACertificate"`
+}
+
+type rfCertCollection struct {
+	Members []rfODataID `json:"Members"`
+}
+
+type rfCertificate struct {
+	CertificateString string             `json:"CertificateString"`
+	Subject           rfCertificateIdent `json:"Subject"`
+	Issuer            rfCertificateIdent `json:"Issuer"`
+	ValidNotAfter     string             `json:"ValidNotAfter"`
+}
+
+type rfCertificateIdent struct {
+	CommonName string `json:"CommonName"`
+}
+
+type rfTrustCertPayload struct {
+	CertificateString string `json:"CertificateString"`
+	CertificateType   string `json:"CertificateType"`
+}
+
+type hpeImportCACertPayload struct {
+	Certificate string `json:"Certificate"`
+}
+
+// Trust store of one target, and the state of the operation on it.
+
+type trustStore struct {
+	targ      string
+	next      string //next URI to follow during discovery
+	hpe       bool
+	collURI   string //trust store cert collection
+	importURI string //HPE import action; DMTF certs are POSTed to collURI
+	members   []string
+	certs     []trustCertInfo
+	added     int
+	present   int
+	removed   int
+	missing   []string
+	result    certOpResult //set once the target has failed
+}
+
+func (ts *trustStore) failed() bool {
+	return ts.result.statusCode != 0
+}
+
+// Do one Redfish operation on each of a list of trust stores.  Failed
+// trust stores, and those for which uri returns "", are left out.  Failed
+// operations and failures in done are recorded in the trust store.
+//
+// funcName(in):  Name of the calling func, for logging.
+// stores(inout): Trust stores.
+// method(in):    HTTP method.
+// uri(in):       Returns the URI to use for a trust store.  Called once for
+//                each trust store which has not failed.
+// pld(in):       Returns the payload for a trust store, may be nil.
+// done(in):      Called with each successful task, may be nil.
+// Return:        Error if the operation could not be done at all.
+
+func trustStoresOp(funcName string, stores []*trustStore, method string,
+	uri func(*trustStore) string, pld func(*trustStore) []byte,
+	done func(*trustStore, *trsapi.HttpTask) error) error {
+	var sourceTL trsapi.HttpTask
+	var tstores []*trustStore
+	var uris []string
+
+	for _, ts := range stores {
+		if ts.failed() {
+			continue
+		}
+		if tsURI := uri(ts); tsURI != "" {
+			tstores = append(tstores, ts)
+			uris = append(uris, tsURI)
+		}
+	}
+	if len(tstores) == 0 {
+		return nil
+	}
+
+	sourceTL.Timeout = time.Duration(appParams.HTTPTimeout) * time.Second
+	sourceTL.Request, _ = http.NewRequest(http.MethodGet, "", nil)
+	taskList := tloc.CreateTaskList(&sourceTL, len(tstores))
+	for ii, ts := range tstores {
+		url := dfltProtocol + "://" + ts.targ + uris[ii]
+		if pld == nil {
+			taskList[ii].Request, _ = http.NewRequest(method, url, nil)
+			continue
+		}
+		taskList[ii].Request, _ = http.NewRequest(method, url, bytes.NewBuffer(pld(ts)))
+		taskList[ii].Request.Header.Set(CT_TYPE, CT_APPJSON)
+	}
+
+	err := doOp(taskList)
+	if err != nil {
+		logger.Errorf("%s: Problem executing trust store operation: %v",
+			funcName, err)
+		return err
+	}
+
+	for ii, ts := range tstores {
+		code := getStatusCode(&taskList[ii])
+		if !statusCodeOK(code) {
+			ts.result = certOpResult{statusCode: code,
+				statusMsg: getStatusMsg(&taskList[ii])}
+			continue
+		}
+		if done == nil {
+			continue
+		}
+		err = done(ts, &taskList[ii])
+		if err != nil {
+			logger.Errorf("%s: Problem with response from '%s': %v",
+				funcName, ts.targ, err)
+			ts.result = certOpResult{statusCode: http.StatusInternalServerError,
+				statusMsg: err.Error()}
+		}
+	}
+	return nil
+}
+
+// Find the trust store of each target.  Targets without one fail with
+// a 501.
+//
+// funcName(in): Name of the calling func, for logging.
+// targs(in):    Targets.
+// Return:       Trust store of each target; error if discovery could not
+//               be done at all.
+
+func getTrustStores(funcName string, targs []string) ([]*trustStore, error) {
+	stores := make([]*trustStore, len(targs))
+	for ii := range targs {
+		stores[ii] = &trustStore{targ: targs[ii], next: HPE_MGR_API}
+	}
+	nextURI := func(ts *trustStore) string { return ts.next }
+	notFound := certOpResult{statusCode: http.StatusNotImplemented,
+		statusMsg: "No CA trust store found"}
+
+	//Managers
+
+	err := trustStoresOp(funcName, stores, http.MethodGet, nextURI, nil,
+		func(ts *trustStore, task *trsapi.HttpTask) error {
+			var jdata hpeManagers
+			err := grabTaskRspData(funcName, task, &jdata)
+			if err != nil {
+				return err
+			}
+			if len(jdata.Members) == 0 {
+				return fmt.Errorf("No manager found")
+			}
+			ts.next = jdata.Members[0].ID
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	//Manager: SecurityPolicy or HPE SecurityService
+
+	err = trustStoresOp(funcName, stores, http.MethodGet, nextURI, nil,
+		func(ts *trustStore, task *trsapi.HttpTask) error {
+			var jdata rfTrustManager
+			err := grabTaskRspData(funcName, task, &jdata)
+			if err != nil {
+				return err
+			}
+			ts.next = jdata.SecurityPolicy.ID
+			if ts.next == "" {
+				ts.next = jdata.Oem.HPE.Links.SecurityService.ID
+				ts.hpe = true
+			}
+			if ts.next == "" {
+				ts.result = notFound
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	//SecurityPolicy: done.  SecurityService: CertAuth.
+
+	err = trustStoresOp(funcName, stores, http.MethodGet, nextURI, nil,
+		func(ts *trustStore, task *trsapi.HttpTask) error {
+			if !ts.hpe {
+				var jdata rfSecurityPolicy
+				err := grabTaskRspData(funcName, task, &jdata)
+				if err != nil {
+					return err
+				}
+				ts.collURI = jdata.TLS.Client.TrustedCertificates.ID
+				ts.next = ""
+				return nil
+			}
+			var jdata hpeTrustSecurityService
+			err := grabTaskRspData(funcName, task, &jdata)
+			if err != nil {
+				return err
+			}
+			ts.next = jdata.Links.CertAuth.ID
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	//HPE CertAuth
+
+	err = trustStoresOp(funcName, stores, http.MethodGet, nextURI, nil,
+		func(ts *trustStore, task *trsapi.HttpTask) error {
+			var jdata hpeCertAuth
+			err := grabTaskRspData(funcName, task, &jdata)
+			if err != nil {
+				return err
+			}
+			ts.collURI = jdata.CACertificates.ID
+			ts.importURI = jdata.Actions.ImportCACert.Target
+			ts.next = ""
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ts := range stores {
+		if !ts.failed() && ((ts.collURI == "") || (ts.hpe && (ts.importURI == ""))) {
+			ts.result = notFound
+		}
+	}
+	return stores, nil
+}
+
+// Make the info of an installed CA cert from its Redfish data.  If the
+// PEM data can be parsed it is used, else the Redfish fields are.
+
+func makeTrustCertInfo(uri string, rfCert *rfCertificate) trustCertInfo {
+	info := trustCertInfo{URI: uri}
+	cert, err := parseLeafCert(rfCert.CertificateString)
+	if err == nil {
+		notAfter := cert.NotAfter.UTC()
+		info.Subject = cert.Subject.String()
+		info.Issuer = cert.Issuer.String()
+		info.NotAfter = &notAfter
+		info.Fingerprint = certFingerprint(cert)
+		return info
+	}
+
+	if rfCert.Subject.CommonName != "" {
+		info.Subject = "CN=" + rfCert.Subject.CommonName
+	}
+	if rfCert.Issuer.CommonName != "" {
+		info.Issuer = "CN=" + rfCert.Issuer.CommonName
+	}
+	notAfter, err := time.Parse(time.RFC3339, rfCert.ValidNotAfter)
+	if err == nil {
+		notAfter = notAfter.UTC()
+		info.NotAfter = &notAfter
+	}
+	return info
+}
+
+// Get the CA certs installed in each trust store.
+//
+// funcName(in):  Name of the calling func, for logging.
+// stores(inout): Trust stores, their certs are filled in.
+// Return:        Error if the certs could not be fetched at all.
+
+func getTrustedCerts(funcName string, stores []*trustStore) error {
+	err := trustStoresOp(funcName, stores, http.MethodGet,
+		func(ts *trustStore) string { return ts.collURI }, nil,
+		func(ts *trustStore, task *trsapi.HttpTask) error {
+			var jdata rfCertCollection
+			err := grabTaskRspData(funcName, task, &jdata)
+			if err != nil {
+				return err
+			}
+			ts.members = nil
+			for _, mem := range jdata.Members {
+				ts.members = append(ts.members, mem.ID)
+			}
+			ts.certs = []trustCertInfo{}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	//One round per member index, since each target has its own members.
+
+	maxMembers := 0
+	for _, ts := range stores {
+		if len(ts.members) > maxMembers {
+			maxMembers = len(ts.members)
+		}
+	}
+	for ix := 0; ix < maxMembers; ix++ {
+		err = trustStoresOp(funcName, stores, http.MethodGet,
+			func(ts *trustStore) string {
+				if ix < len(ts.members) {
+					return ts.members[ix]
+				}
+				return ""
+			}, nil,
+			func(ts *trustStore, task *trsapi.HttpTask) error {
+				var jdata rfCertificate
+				err := grabTaskRspData(funcName, task, &jdata)
+				if err != nil {
+					return err
+				}
+				ts.certs = append(ts.certs, makeTrustCertInfo(ts.members[ix], &jdata))
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Split a CA bundle into its CA certs.
+//
+// bundle(in): PEM CA certs, with newlines or \n tuples.
+// Return:     PEM data and fingerprint of each CA cert; error if there are
+//             none or a cert is not a CA cert.
+
+func parseCABundle(bundle string) ([]string, []string, error) {
+	var pems, fps []string
+	rest := []byte(hms_certs.TupleToNewline(bundle))
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Can't parse CA cert: %v", err)
+		}
+		if !cert.IsCA {
+			return nil, nil, fmt.Errorf("Cert '%s' is not a CA cert",
+				cert.Subject.String())
+		}
+		pems = append(pems, string(pem.EncodeToMemory(block)))
+		fps = append(fps, certFingerprint(cert))
+	}
+	if len(pems) == 0 {
+		return nil, nil, fmt.Errorf("No certs found in the CA bundle")
+	}
+	return pems, fps, nil
+}
+
+// Returns the installed CA cert of a trust store with a fingerprint, nil
+// if there is none.
+
+func (ts *trustStore) hasCert(fp string) *trustCertInfo {
+	for ix := range ts.certs {
+		if ts.certs[ix].Fingerprint == fp {
+			return &ts.certs[ix]
+		}
+	}
+	return nil
+}
+
+// Install CA certs in trust stores.  CA certs which are already installed
+// are left alone.
+//
+// funcName(in):  Name of the calling func, for logging.
+// stores(inout): Trust stores, with their installed certs.
+// pems(in):      PEM data of each CA cert.
+// fps(in):       Fingerprint of each CA cert.
+// Return:        Error if the certs could not be installed at all.
+
+func installTrustedCerts(funcName string, stores []*trustStore, pems []string, fps []string) error {
+	for ix := range pems {
+		err := trustStoresOp(funcName, stores, http.MethodPost,
+			func(ts *trustStore) string {
+				if ts.hasCert(fps[ix]) != nil {
+					ts.present++
+					return ""
+				}
+				if ts.hpe {
+					return ts.importURI
+				}
+				return ts.collURI
+			},
+			func(ts *trustStore) []byte {
+				var ba []byte
+				if ts.hpe {
+					ba, _ = json.Marshal(&hpeImportCACertPayload{Certificate: pems[ix]})
+				} else {
+					ba, _ = json.Marshal(&rfTrustCertPayload{CertificateString: pems[ix],
+						CertificateType: "PEM"})
+				}
+				return ba
+			},
+			func(ts *trustStore, task *trsapi.HttpTask) error {
+				ts.added++
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ts := range stores {
+		if !ts.failed() {
+			ts.result = certOpResult{statusCode: http.StatusOK,
+				statusMsg: fmt.Sprintf("Installed %d CA certs, %d already present",
+					ts.added, ts.present)}
+		}
+	}
+	return nil
+}
+
+// Remove CA certs from trust stores.
+//
+// funcName(in):  Name of the calling func, for logging.
+// stores(inout): Trust stores, with their installed certs.
+// fps(in):       Fingerprints of the CA certs to remove.
+// Return:        Error if the certs could not be removed at all.
+
+func removeTrustedCerts(funcName string, stores []*trustStore, fps []string) error {
+	for ix := range fps {
+		err := trustStoresOp(funcName, stores, http.MethodDelete,
+			func(ts *trustStore) string {
+				info := ts.hasCert(fps[ix])
+				if info == nil {
+					ts.missing = append(ts.missing, fps[ix])
+					return ""
+				}
+				return info.URI
+			}, nil,
+			func(ts *trustStore, task *trsapi.HttpTask) error {
+				ts.removed++
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ts := range stores {
+		if ts.failed() {
+			continue
+		}
+		if (ts.removed == 0) && (len(ts.missing) > 0) {
+			ts.result = certOpResult{statusCode: http.StatusNotFound,
+				statusMsg: fmt.Sprintf("CA certs not installed: %s",
+					strings.Join(ts.missing, ","))}
+			continue
+		}
+		msg := fmt.Sprintf("Removed %d CA certs", ts.removed)
+		if len(ts.missing) > 0 {
+			msg += fmt.Sprintf(", not installed: %s", strings.Join(ts.missing, ","))
+		}
+		ts.result = certOpResult{statusCode: http.StatusOK, statusMsg: msg}
+	}
+	return nil
+}
+
+// Verify targets with HSM.  Targets in a bad HSM state are added to the
+// response data.
+//
+// targets(in):  Targets, groups are expanded.
+// force(in):    Don't contact HSM.
+// retData(out): Response data.
+// Return:       Targets in a good HSM state; error if HSM failed.
+
+func trustCertTargets(targets []string, force bool, retData *trustCertRsp) ([]string, error) {
+	expTargData, err := hsmVerify(makeTargData(targets), force, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlist []string
+	for ii := 0; ii < len(expTargData); ii++ {
+		if expTargData[ii].groupMatched {
+			continue
+		}
+		if !goodHSMState(expTargData[ii].state.String()) {
+			retData.Targets = append(retData.Targets, trustCertRspElem{ID: expTargData[ii].target,
+				StatusCode: http.StatusUnprocessableEntity,
+				StatusMsg: fmt.Sprintf("Target '%s' in bad HSM state: %s",
+					expTargData[ii].target, string(expTargData[ii].state))})
+			continue
+		}
+		tlist = append(tlist, expTargData[ii].target)
+	}
+	return tlist, nil
+}
+
+// Add the outcome of each trust store operation to the response data.
+
+func setTrustRetData(stores []*trustStore, withCerts bool, retData *trustCertRsp) {
+	for _, ts := range stores {
+		elm := trustCertRspElem{ID: ts.targ,
+			StatusCode: ts.result.statusCode,
+			StatusMsg:  ts.result.statusMsg}
+		if elm.StatusCode == 0 {
+			elm.StatusCode = http.StatusOK
+			elm.StatusMsg = "OK"
+		}
+		if withCerts && statusCodeOK(elm.StatusCode) {
+			elm.Certs = ts.certs
+		}
+		retData.Targets = append(retData.Targets, elm)
+	}
+}
+
+// Send a trust cert response.
+
+func sendTrustCertRsp(w http.ResponseWriter, r *http.Request, retData *trustCertRsp) {
+	if retData.Targets == nil {
+		retData.Targets = []trustCertRspElem{}
+	}
+	ba, berr := json.Marshal(retData)
+	if berr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling return data: %v", berr)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+// Install a CA bundle into the trust store of BMCs.
+
+func doBMCTrustCertsPost(w http.ResponseWriter, r *http.Request) {
+	var jdata trustCertPost
+	var retData trustCertRsp
+	funcName := "doBMCTrustCertsPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request data", "ERROR: No targets specified.",
+			r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	bundle := jdata.CABundle
+	if bundle == "" {
+		if caURI == "" {
+			sendErrorRsp(w, "Bad request data",
+				"ERROR: No CABundle given and no CA bundle URI configured.",
+				r.URL.Path, http.StatusBadRequest)
+			return
+		}
+		bundle, err = hms_certs.FetchCAChain(caURI)
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: Can't fetch the CA bundle: %v", err)
+			sendErrorRsp(w, "CA bundle fetch error", emsg, r.URL.Path,
+				http.StatusInternalServerError)
+			return
+		}
+	}
+	pems, fps, err := parseCABundle(bundle)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Invalid CA bundle: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+
+	tlist, err := trustCertTargets(jdata.Targets, jdata.Force, &retData)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem verifying target states: %v.", err)
+		sendErrorRsp(w, "Indeterminate target state", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	if len(tlist) > 0 {
+		stores, err := getTrustStores(funcName, tlist)
+		if err == nil {
+			err = getTrustedCerts(funcName, stores)
+		}
+		if err == nil {
+			err = installTrustedCerts(funcName, stores, pems, fps)
+		}
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: CA cert install failed: %v", err)
+			sendErrorRsp(w, "CA cert install error", emsg, r.URL.Path,
+				http.StatusInternalServerError)
+			return
+		}
+		setTrustRetData(stores, false, &retData)
+	}
+
+	sendTrustCertRsp(w, r, &retData)
+}
+
+// List the CA certs in the trust store of BMCs.
+
+func doBMCFetchTrustCertsPost(w http.ResponseWriter, r *http.Request) {
+	var jdata trustCertFetchPost
+	var retData trustCertRsp
+	funcName := "doBMCFetchTrustCertsPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+	if len(jdata.Targets) == 0 {
+		sendErrorRsp(w, "Bad request data", "ERROR: No targets specified.",
+			r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	tlist, err := trustCertTargets(jdata.Targets, jdata.Force, &retData)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem verifying target states: %v.", err)
+		sendErrorRsp(w, "Indeterminate target state", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	if len(tlist) > 0 {
+		stores, err := getTrustStores(funcName, tlist)
+		if err == nil {
+			err = getTrustedCerts(funcName, stores)
+		}
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: CA cert fetch failed: %v", err)
+			sendErrorRsp(w, "CA cert fetch error", emsg, r.URL.Path,
+				http.StatusInternalServerError)
+			return
+		}
+		setTrustRetData(stores, true, &retData)
+	}
+
+	sendTrustCertRsp(w, r, &retData)
+}
+
+// Remove CA certs from the trust store of BMCs.
+
+func doBMCDeleteTrustCertsPost(w http.ResponseWriter, r *http.Request) {
+	var jdata trustCertDeletePost
+	var retData trustCertRsp
+	funcName := "doBMCDeleteTrustCertsPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		return
+	}
+	if (len(jdata.Targets) == 0) || (len(jdata.Fingerprints) == 0) {
+		sendErrorRsp(w, "Bad request data",
+			"ERROR: Targets and Fingerprints must be specified.",
+			r.URL.Path, http.StatusBadRequest)
+		return
+	}
+	for ix := range jdata.Fingerprints {
+		jdata.Fingerprints[ix] = strings.ToLower(strings.ReplaceAll(jdata.Fingerprints[ix], ":", ""))
+	}
+
+	tlist, err := trustCertTargets(jdata.Targets, jdata.Force, &retData)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem verifying target states: %v.", err)
+		sendErrorRsp(w, "Indeterminate target state", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	if len(tlist) > 0 {
+		stores, err := getTrustStores(funcName, tlist)
+		if err == nil {
+			err = getTrustedCerts(funcName, stores)
+		}
+		if err == nil {
+			err = removeTrustedCerts(funcName, stores, jdata.Fingerprints)
+		}
+		if err != nil {
+			emsg := fmt.Sprintf("ERROR: CA cert removal failed: %v", err)
+			sendErrorRsp(w, "CA cert removal error", emsg, r.URL.Path,
+				http.StatusInternalServerError)
+			return
+		}
+		setTrustRetData(stores, false, &retData)
+	}
+
+	sendTrustCertRsp(w, r, &retData)
+}
@@ -0,0 +1,286 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fake BMC with a CA trust store, either the DMTF SecurityPolicy one or
+// the HPE CertAuth one.
+
+type fakeTrustBMC struct {
+	hpe     bool
+	noStore bool
+	lock    sync.Mutex
+	certs   map[string]string
+	nextID  int
+}
+
+const fakeTrustColl = "/redfish/v1/Managers/BMC/Truststore/Certificates"
+const fakeTrustImport = "/redfish/v1/Managers/BMC/SecurityService/CertificateAuthentication/Actions/HpeCertAuth.ImportCACertificate"
+
+func (f *fakeTrustBMC) addCert(pemData string) {
+	f.nextID++
+	f.certs[fmt.Sprintf("%s/%d", fakeTrustColl, f.nextID)] = pemData
+}
+
+func (f *fakeTrustBMC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var pld string
+	switch {
+	case r.URL.Path == "/redfish/v1/Managers":
+		pld = `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`
+	case r.URL.Path == "/redfish/v1/Managers/BMC":
+		if f.noStore {
+			pld = `{"Id":"BMC"}`
+		} else if f.hpe {
+			pld = `{"Oem":{"Hpe":{"Links":{"SecurityService":{"@odata.id":"/redfish/v1/Managers/BMC/SecurityService"}}}}}`
+		} else {
+			pld = `{"SecurityPolicy":{"@odata.id":"/redfish/v1/Managers/BMC/SecurityPolicy"}}`
+		}
+	case r.URL.Path == "/redfish/v1/Managers/BMC/SecurityPolicy":
+		pld = `{"TLS":{"Client":{"TrustedCertificates":{"@odata.id":"` + fakeTrustColl + `"}}}}`
+	case r.URL.Path == "/redfish/v1/Managers/BMC/SecurityService":
+		pld = `{"Links":{"CertAuth":{"@odata.id":"/redfish/v1/Managers/BMC/SecurityService/CertificateAuthentication"}}}`
+	case r.URL.Path == "/redfish/v1/Managers/BMC/SecurityService/CertificateAuthentication":
+		pld = `{"CACertificates":{"@odata.id":"` + fakeTrustColl + `"},"Actions":{"#HpeCertAuth.ImportCACertificate":{"target":"` + fakeTrustImport + `"}}}`
+	case (r.URL.Path == fakeTrustColl) && (r.Method == http.MethodGet):
+		var mems []string
+		for uri := range f.certs {
+			mems = append(mems, `{"@odata.id":"`+uri+`"}`)
+		}
+		sort.Strings(mems)
+		pld = `{"Members":[` + strings.Join(mems, ",") + `]}`
+	case (r.URL.Path == fakeTrustColl) && !f.hpe && (r.Method == http.MethodPost):
+		var jdata rfTrustCertPayload
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &jdata)
+		if jdata.CertificateType != "PEM" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.addCert(jdata.CertificateString)
+		w.WriteHeader(http.StatusCreated)
+		return
+	case (r.URL.Path == fakeTrustImport) && f.hpe && (r.Method == http.MethodPost):
+		var jdata hpeImportCACertPayload
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &jdata)
+		f.addCert(jdata.Certificate)
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		pemData, ok := f.certs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.certs, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		ba, _ := json.Marshal(&rfCertificate{CertificateString: pemData})
+		pld = string(ba)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(pld))
+}
+
+func TestParseCABundle(t *testing.T) {
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Site Root CA", nil, true, year, nil)
+	inter, _ := makeTestSignedCert(t, "Site Issuing CA", nil, true, year, root)
+	leaf, _ := makeTestSignedCert(t, "x1000c0s0b0", []string{"x1000c0s0b0"}, false, year, inter)
+
+	pems, fps, err := parseCABundle(strings.ReplaceAll(inter.pem+root.pem, "\n", "\\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if (len(pems) != 2) || (pems[1] != root.pem) || (fps[0] != certFingerprint(inter.cert)) {
+		t.Errorf("Bad CA bundle split: %v", fps)
+	}
+
+	if _, _, err = parseCABundle(root.pem + leaf.pem); err == nil {
+		t.Errorf("Expected an error for a non-CA cert")
+	}
+	if _, _, err = parseCABundle("junk"); err == nil {
+		t.Errorf("Expected an error for an empty bundle")
+	}
+}
+
+func TestTrustCerts(t *testing.T) {
+	loggerSetup()
+	savedProto, savedVault := dfltProtocol, appParams.VaultEnable
+	defer func() {
+		dfltProtocol, appParams.VaultEnable = savedProto, savedVault
+	}()
+	dfltProtocol = "http"
+	appParams.VaultEnable = nil //no RF creds needed
+
+	tloc = &tlocLocal
+	err := tloc.Init("SCSD_TEST", nil)
+	if err != nil {
+		t.Fatalf("Error initializing TRS API: %v", err)
+	}
+
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Site Root CA", nil, true, year, nil)
+	inter, _ := makeTestSignedCert(t, "Site Issuing CA", nil, true, year, root)
+	pems, fps, _ := parseCABundle(inter.pem + root.pem)
+
+	//The DMTF BMC already has the root CA.
+
+	dmtfBMC := &fakeTrustBMC{certs: map[string]string{}}
+	dmtfBMC.addCert(root.pem)
+	hpeBMC := &fakeTrustBMC{hpe: true, certs: map[string]string{}}
+	noBMC := &fakeTrustBMC{noStore: true, certs: map[string]string{}}
+	var targs []string
+	for _, bmc := range []*fakeTrustBMC{dmtfBMC, hpeBMC, noBMC} {
+		srv := httptest.NewServer(bmc)
+		defer srv.Close()
+		surl, _ := url.Parse(srv.URL)
+		targs = append(targs, surl.Host)
+	}
+
+	//Install
+
+	stores, err := getTrustStores("test", targs)
+	if err == nil {
+		err = getTrustedCerts("test", stores)
+	}
+	if err == nil {
+		err = installTrustedCerts("test", stores, pems, fps)
+	}
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var retData trustCertRsp
+	setTrustRetData(stores, false, &retData)
+	expMsgs := []string{"Installed 1 CA certs, 1 already present",
+		"Installed 2 CA certs, 0 already present",
+		"No CA trust store found"}
+	expCodes := []int{http.StatusOK, http.StatusOK, http.StatusNotImplemented}
+	for ix, elm := range retData.Targets {
+		if (elm.StatusCode != expCodes[ix]) || (elm.StatusMsg != expMsgs[ix]) {
+			t.Errorf("Install %d: expected %d/%s, got %d/%s", ix,
+				expCodes[ix], expMsgs[ix], elm.StatusCode, elm.StatusMsg)
+		}
+	}
+	if (len(dmtfBMC.certs) != 2) || (len(hpeBMC.certs) != 2) {
+		t.Errorf("Expected 2 CA certs on each BMC, got %d/%d",
+			len(dmtfBMC.certs), len(hpeBMC.certs))
+	}
+
+	//List
+
+	stores, _ = getTrustStores("test", targs[:2])
+	err = getTrustedCerts("test", stores)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	retData = trustCertRsp{}
+	setTrustRetData(stores, true, &retData)
+	for _, elm := range retData.Targets {
+		if len(elm.Certs) != 2 {
+			t.Errorf("%s: expected 2 CA certs, got %v", elm.ID, elm.Certs)
+			continue
+		}
+		seen := map[string]bool{}
+		for _, cert := range elm.Certs {
+			seen[cert.Fingerprint] = true
+			if (cert.NotAfter == nil) || !strings.HasPrefix(cert.URI, fakeTrustColl) {
+				t.Errorf("%s: bad cert info %+v", elm.ID, cert)
+			}
+		}
+		if !seen[fps[0]] || !seen[fps[1]] {
+			t.Errorf("%s: CA certs missing from %v", elm.ID, elm.Certs)
+		}
+	}
+
+	//Remove the issuing CA and an unknown one; then the unknown one only.
+
+	stores, _ = getTrustStores("test", targs[:1])
+	getTrustedCerts("test", stores)
+	err = removeTrustedCerts("test", stores, []string{fps[0], "abcd"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if (stores[0].result.statusCode != http.StatusOK) ||
+		(stores[0].result.statusMsg != "Removed 1 CA certs, not installed: abcd") {
+		t.Errorf("Bad removal result: %+v", stores[0].result)
+	}
+	for _, pemData := range dmtfBMC.certs {
+		if pemData != root.pem {
+			t.Errorf("Wrong CA cert removed")
+		}
+	}
+
+	stores, _ = getTrustStores("test", targs[:1])
+	getTrustedCerts("test", stores)
+	removeTrustedCerts("test", stores, []string{"abcd"})
+	if stores[0].result.statusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown CA cert, got %+v", stores[0].result)
+	}
+}
+
+func TestTrustCertsBadRequests(t *testing.T) {
+	loggerSetup()
+	savedCAURI := caURI
+	defer func() { caURI = savedCAURI }()
+	caURI = ""
+
+	tests := []struct {
+		handler func(http.ResponseWriter, *http.Request)
+		uri     string
+		pld     string
+	}{
+		{doBMCTrustCertsPost, API_TRUST_CERTS, `{"Targets":[]}`},
+		{doBMCTrustCertsPost, API_TRUST_CERTS, `{"Targets":["x0c0s0b0"]}`},
+		{doBMCTrustCertsPost, API_TRUST_CERTS, `{"Targets":["x0c0s0b0"],"CABundle":"junk"}`},
+		{doBMCFetchTrustCertsPost, API_FETCH_TRUST, `{"Targets":[]}`},
+		{doBMCDeleteTrustCertsPost, API_DEL_TRUST, `{"Targets":["x0c0s0b0"]}`},
+	}
+
+	for ix, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.uri, strings.NewReader(tt.pld))
+		rr := httptest.NewRecorder()
+		tt.handler(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Test %d: expected 400, got %d", ix, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+// Upload of externally issued BMC certs, e.g. from a site CA.  The certs
+// are checked and stored in secure storage the same way as certs created
+// by hms_certs, so /bmc/setcerts can apply them.  They are marked as
+// uploaded, since automatic renewal can't renew them.
+
+// Used for /v1/bmc/uploadcerts POST.  ID is either a BMC XName or a cert
+// domain ID, and is resolved to a domain ID within Domain.  Cert is the PEM
+// leaf cert followed by its chain.  CA holds the PEM CA cert(s) the chains
+// are verified against; if not given, the chain must contain its root.
+
+type bmcUploadCertPost struct {
+	Domain string          `json:"Domain"`
+	CA     string          `json:"CA,omitempty"`
+	Certs  []bmcUploadCert `json:"Certs"`
+}
+
+type bmcUploadCert struct {
+	ID      string   `json:"ID"`
+	Cert    string   `json:"Cert"`
+	Key     string   `json:"Key"`
+	FQDN    string   `json:"FQDN,omitempty"`
+	Targets []string `json:"Targets,omitempty"` //BMCs the cert must cover
+}
+
+// Parse all certs out of PEM data.
+//
+// pemData(in): PEM cert data, with newlines or \n tuples.
+// Return:      Parsed certs and their PEM encodings, in order; error if
+//              there are no certs or a cert can't be parsed.
+
+func parsePEMCerts(pemData string) ([]*x509.Certificate, []string, error) {
+	var certs []*x509.Certificate
+	var pems []string
+
+	rest := []byte(hms_certs.TupleToNewline(pemData))
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Can't parse cert %d: %v", len(certs), err)
+		}
+		certs = append(certs, cert)
+		pems = append(pems, string(pem.EncodeToMemory(block)))
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("No certs found")
+	}
+	return certs, pems, nil
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) &&
+		(cert.CheckSignatureFrom(cert) == nil)
+}
+
+// Returns the private key type in the format used by the Vault PKI.
+
+func privateKeyType(key interface{}) string {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return "rsa"
+	case *ecdsa.PrivateKey:
+		return "ec"
+	case ed25519.PrivateKey:
+		return "ed25519"
+	}
+	return ""
+}
+
+// Returns a cert serial number in the format used by the Vault PKI,
+// e.g. 3a:0f:12.
+
+func certSerial(cert *x509.Certificate) string {
+	var toks []string
+	for _, b := range cert.SerialNumber.Bytes() {
+		toks = append(toks, fmt.Sprintf("%02x", b))
+	}
+	return strings.Join(toks, ":")
+}
+
+// Check an uploaded cert and turn it into secure storage cert data.
+//
+// up(in):    Uploaded cert/key data.
+// caPEM(in): PEM CA certs to verify the chain against, may be empty.
+// names(in): Names which must be covered by the cert's SANs.
+// now(in):   Time at which the cert must be valid.
+// Return:    Cert data to store; error if the cert is not acceptable.
+
+func checkUploadCert(up *bmcUploadCert, caPEM string, names []string, now time.Time) (*hms_certs.VaultCertData, error) {
+	chain, chainPEMs, err := parsePEMCerts(up.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("Bad cert data: %v", err)
+	}
+	leaf := chain[0]
+
+	keyPEM := hms_certs.TupleToNewline(up.Key)
+	pair, err := tls.X509KeyPair([]byte(chainPEMs[0]), []byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("Key does not match the cert: %v", err)
+	}
+
+	if now.After(leaf.NotAfter) {
+		return nil, fmt.Errorf("Cert expired on %s", leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(leaf.NotBefore) {
+		return nil, fmt.Errorf("Cert not valid until %s", leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+
+	//Verify the chain.  Self-signed certs in the chain are roots, the rest
+	//intermediates.
+
+	roots := x509.NewCertPool()
+	inters := x509.NewCertPool()
+	nRoots := 0
+	for _, cert := range chain[1:] {
+		if isSelfSigned(cert) {
+			roots.AddCert(cert)
+			nRoots++
+		} else {
+			inters.AddCert(cert)
+		}
+	}
+	if caPEM != "" {
+		cas, _, cerr := parsePEMCerts(caPEM)
+		if cerr != nil {
+			return nil, fmt.Errorf("Bad CA data: %v", cerr)
+		}
+		for _, cert := range cas {
+			roots.AddCert(cert)
+			nRoots++
+		}
+	}
+	if nRoots == 0 {
+		return nil, fmt.Errorf("No root CA to verify the cert chain against")
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: roots,
+		Intermediates: inters,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	if err != nil {
+		return nil, fmt.Errorf("Cert chain is not valid: %v", err)
+	}
+
+	for _, name := range names {
+		err = leaf.VerifyHostname(name)
+		if err != nil {
+			return nil, fmt.Errorf("Cert SANs don't cover '%s'", name)
+		}
+	}
+
+	//Store the leaf with its intermediates, which is what the BMC should
+	//serve.  Roots only go in the CA chain.
+
+	var served []string
+	for ii, cert := range chain {
+		if (ii == 0) || !isSelfSigned(cert) {
+			served = append(served, strings.TrimSpace(chainPEMs[ii]))
+		}
+	}
+	vcert := hms_certs.VaultCertData{Data: hms_certs.CertInfo{
+		Certificate:    strings.Join(served, "\n"),
+		Expiration:     int(leaf.NotAfter.Unix()),
+		PrivateKey:     strings.TrimSpace(keyPEM),
+		PrivateKeyType: privateKeyType(pair.PrivateKey),
+		SerialNumber:   certSerial(leaf),
+	}}
+	for _, cpem := range chainPEMs[1:] {
+		vcert.Data.CAChain = append(vcert.Data.CAChain, strings.TrimSpace(cpem))
+	}
+	if len(vcert.Data.CAChain) > 0 {
+		vcert.Data.IssuingCA = vcert.Data.CAChain[0]
+	}
+	if up.FQDN != "" {
+		vcert.Data.FQDN = "." + strings.TrimLeft(up.FQDN, ".")
+	}
+	return &vcert, nil
+}
+
+// Check that the targets of an uploaded cert are all in its cert domain,
+// since the cert is stored for the domain and not just for them.
+//
+// up(in):         Uploaded cert data.
+// domID(in):      Cert domain ID of the upload.
+// certDomain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// Return:         Error naming the first target outside of the domain.
+
+func checkUploadTargets(up *bmcUploadCert, domID string, certDomain string) error {
+	for _, targ := range up.Targets {
+		tdom, err := hms_certs.CheckDomain([]string{stripPort(targ)}, certDomain)
+		if (err != nil) || (tdom != domID) {
+			return fmt.Errorf("Target '%s' is not in domain ID '%s'", targ, domID)
+		}
+	}
+	return nil
+}
+
+// Get the names an uploaded cert must cover.  The cert is stored for the
+// whole domain and applied to all of its BMCs, so these are the domain's
+// BMCs known to HSM, plus the given targets and the BMC itself when the ID
+// is a BMC XName.  The FQDN, if any, is appended to each name, the same as
+// for created certs.
+//
+// up(in):         Uploaded cert data.
+// domID(in):      Cert domain ID of the upload.
+// certDomain(in): Cert domain, e.g. hms_certs.CertDomainCabinet.
+// hsmDoms(inout): HSM BMCs by domain ID, fetched on first use.
+// Return:         Names to cover; error if HSM can't be reached.
+
+func uploadCertNames(up *bmcUploadCert, domID string, certDomain string, hsmDoms *map[string][]hsmComponent) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	addName := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if certDomain == hms_certs.CertDomainBMC {
+		addName(domID)
+	} else {
+		if *hsmDoms == nil {
+			bmcs, err := getHSMBMCs()
+			if err != nil {
+				return nil, err
+			}
+			*hsmDoms = groupBMCsByCertDomain(bmcs, certDomain)
+		}
+		for _, bmc := range (*hsmDoms)[domID] {
+			addName(bmc.ID)
+		}
+	}
+	for _, targ := range up.Targets {
+		addName(targ)
+	}
+	if up.ID != domID {
+		addName(up.ID)
+	}
+
+	if up.FQDN == "" {
+		return names, nil
+	}
+	fqdn := "." + strings.TrimLeft(up.FQDN, ".")
+	fnames := make([]string, 0, len(names))
+	for _, name := range names {
+		fnames = append(fnames, stripPort(name)+fqdn)
+	}
+	return fnames, nil
+}
+
+// Check externally issued cert/key pairs and store them in secure storage.
+
+func doBMCUploadCertsPost(w http.ResponseWriter, r *http.Request) {
+	var jdata bmcUploadCertPost
+	var retData bmcManageCertPostRsp
+	var hsmDoms map[string][]hsmComponent
+	funcName := "doBMCUploadCertsPost"
+
+	defer base.DrainAndCloseRequestBody(r)
+
+	err := getReqData(funcName, r, &jdata)
+	if err != nil {
+		emsg := fmt.Sprintf("ERROR: Problem getting request data: %v", err)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		logger.Errorf("%s: %s", funcName, emsg)
+		return
+	}
+	if len(jdata.Certs) == 0 {
+		sendErrorRsp(w, "Bad request data", "ERROR: No certs specified",
+			r.URL.Path, http.StatusBadRequest)
+		return
+	}
+	certDomain, derr := userDomainToCertDomain(jdata.Domain)
+	if derr != nil {
+		emsg := fmt.Sprintf("ERROR: %v", derr)
+		sendErrorRsp(w, "Bad request data", emsg, r.URL.Path,
+			http.StatusBadRequest)
+		logger.Errorf("%s: %s", funcName, emsg)
+		return
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	retData.DomainIDs = make([]certRsp, len(jdata.Certs))
+
+	for ix := 0; ix < len(jdata.Certs); ix++ {
+		up := &jdata.Certs[ix]
+		elm := &retData.DomainIDs[ix]
+		elm.ID = up.ID
+		elm.StatusCode = http.StatusBadRequest
+
+		domID, err := hms_certs.CheckDomain([]string{up.ID}, certDomain)
+		if err != nil {
+			elm.StatusMsg = fmt.Sprintf("ID '%s' not in stated domain: %v", up.ID, err)
+			continue
+		}
+		if seen[domID] {
+			elm.StatusMsg = fmt.Sprintf("Duplicate cert for domain ID '%s'", domID)
+			continue
+		}
+		seen[domID] = true
+
+		err = checkUploadTargets(up, domID, certDomain)
+		if err != nil {
+			elm.StatusMsg = fmt.Sprintf("Cert for '%s' rejected: %v", up.ID, err)
+			continue
+		}
+
+		names, err := uploadCertNames(up, domID, certDomain, &hsmDoms)
+		if err != nil {
+			elm.StatusCode = http.StatusInternalServerError
+			elm.StatusMsg = fmt.Sprintf("Can't get the BMCs of domain ID '%s': %v",
+				domID, err)
+			continue
+		}
+		if len(names) == 0 {
+			elm.StatusMsg = fmt.Sprintf("No BMCs of domain ID '%s' known to HSM to check the cert against, Targets must be given",
+				domID)
+			continue
+		}
+
+		vcert, err := checkUploadCert(up, jdata.CA, names, now)
+		if err != nil {
+			elm.StatusMsg = fmt.Sprintf("Cert for '%s' rejected: %v", up.ID, err)
+			continue
+		}
+
+		//Mark the cert as uploaded before storing it, so that renewal never
+		//replaces it with one of our own.
+
+		logger.Tracef("%s: Storing uploaded cert data for '%s'.", funcName, domID)
+		err = storeCertMeta(domID, certMeta{Source: CertSourceUploaded})
+		if err == nil {
+			err = hms_certs.StoreCertData(domID, *vcert)
+		}
+		if err != nil {
+			elm.StatusCode = http.StatusInternalServerError
+			elm.StatusMsg = fmt.Sprintf("ERROR storing cert for '%s', domain '%s': %v",
+				up.ID, jdata.Domain, err)
+			continue
+		}
+		elm.StatusCode = http.StatusOK
+		elm.StatusMsg = "OK"
+	}
+
+	ba, berr := json.Marshal(&retData)
+	if berr != nil {
+		emsg := fmt.Sprintf("ERROR: Problem marshaling return data: %v", berr)
+		sendErrorRsp(w, "JSON marshal error", emsg, r.URL.Path,
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(CT_TYPE, CT_APPJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
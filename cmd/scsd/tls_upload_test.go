@@ -0,0 +1,255 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  string
+}
+
+// Make a cert signed by parent, or self-signed if parent is nil.
+
+func makeTestSignedCert(t *testing.T, cn string, sans []string, isCA bool,
+	notAfter time.Time, parent *testCA) (*testCA, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := x509.Certificate{SerialNumber: serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-2 * time.Hour),
+		NotAfter:              notAfter,
+		DNSNames:              sans,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	signer, signerKey := &tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	kder, _ := x509.MarshalECPrivateKey(key)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}))
+	return &testCA{cert: cert, key: key,
+		pem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}, keyPEM
+}
+
+func TestCheckUploadCert(t *testing.T) {
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Site Root CA", nil, true, year, nil)
+	inter, _ := makeTestSignedCert(t, "Site Issuing CA", nil, true, year, root)
+	leaf, leafKey := makeTestSignedCert(t, "x1000c0s0b0",
+		[]string{"x1000c0s0b0", "x1000c0s0b0.hmn"}, false, year, inter)
+	expired, expiredKey := makeTestSignedCert(t, "x1000c0s0b0",
+		[]string{"x1000c0s0b0"}, false, time.Now().Add(-time.Hour), inter)
+	_, otherKey := makeTestSignedCert(t, "x1000c0s0b0", nil, false, year, inter)
+	otherRoot, _ := makeTestSignedCert(t, "Other Root CA", nil, true, year, nil)
+
+	now := time.Now()
+	names := []string{"x1000c0s0b0"}
+
+	//Full chain in the upload, with \n tuples
+
+	up := bmcUploadCert{ID: "x1000c0s0b0",
+		Cert: hms_certs.NewlineToTuple(leaf.pem + inter.pem + root.pem),
+		Key:  leafKey, FQDN: "hmn"}
+	vcert, err := checkUploadCert(&up, "", names, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	served, _, err := parsePEMCerts(vcert.Data.Certificate)
+	if (err != nil) || (len(served) != 2) || !served[0].Equal(leaf.cert) ||
+		!served[1].Equal(inter.cert) {
+		t.Errorf("Expected the leaf and intermediate to be stored, got %d certs: %v",
+			len(served), err)
+	}
+	if (len(vcert.Data.CAChain) != 2) || (vcert.Data.IssuingCA != vcert.Data.CAChain[0]) {
+		t.Errorf("Bad CA chain: %d certs", len(vcert.Data.CAChain))
+	}
+	if (vcert.Data.PrivateKeyType != "ec") || (vcert.Data.FQDN != ".hmn") ||
+		(int64(vcert.Data.Expiration) != leaf.cert.NotAfter.Unix()) ||
+		(vcert.Data.SerialNumber == "") {
+		t.Errorf("Bad cert info: %v", vcert.Data)
+	}
+
+	//Chain without root, CA given separately, FQDN names
+
+	up.Cert = leaf.pem + inter.pem
+	_, err = checkUploadCert(&up, root.pem, []string{"x1000c0s0b0.hmn"}, now)
+	if err != nil {
+		t.Errorf("Unexpected error with separate CA: %v", err)
+	}
+
+	bads := []struct {
+		name  string
+		cert  string
+		key   string
+		ca    string
+		names []string
+	}{
+		{"no certs", "junk", leafKey, "", names},
+		{"no root", leaf.pem + inter.pem, leafKey, "", names},
+		{"wrong key", leaf.pem + inter.pem, otherKey, root.pem, names},
+		{"expired", expired.pem + inter.pem, expiredKey, root.pem, names},
+		{"untrusted", leaf.pem + inter.pem, leafKey, otherRoot.pem, names},
+		{"missing intermediate", leaf.pem, leafKey, root.pem, names},
+		{"SANs", leaf.pem + inter.pem, leafKey, root.pem, []string{"x1000c0s1b0"}},
+	}
+	for _, bad := range bads {
+		up := bmcUploadCert{ID: "x1000c0s0b0", Cert: bad.cert, Key: bad.key}
+		_, err = checkUploadCert(&up, bad.ca, bad.names, now)
+		if err == nil {
+			t.Errorf("%s: expected an error", bad.name)
+		}
+	}
+}
+
+func TestUploadCertNames(t *testing.T) {
+	hsmDoms := map[string][]hsmComponent{"x1000": {{ID: "x1000c3s0b0"}, {ID: "x1000c0s0b0"}}}
+
+	up := bmcUploadCert{ID: "x1000c0s0b0", FQDN: ".hmn"}
+	names, err := uploadCertNames(&up, "x1000", hms_certs.CertDomainCabinet, &hsmDoms)
+	if (err != nil) || (strings.Join(names, ",") != "x1000c3s0b0.hmn,x1000c0s0b0.hmn") {
+		t.Errorf("Expected all BMCs of the domain with FQDN, got %v %v", names, err)
+	}
+
+	up = bmcUploadCert{ID: "x1000", Targets: []string{"x1000c0s0b0", "x1000c1s0b0"}}
+	names, err = uploadCertNames(&up, "x1000", hms_certs.CertDomainCabinet, &hsmDoms)
+	if (err != nil) || (strings.Join(names, ",") != "x1000c3s0b0,x1000c0s0b0,x1000c1s0b0") {
+		t.Errorf("Expected the HSM BMCs and the given targets, got %v %v", names, err)
+	}
+
+	up = bmcUploadCert{ID: "x1000c0s0b0"}
+	names, err = uploadCertNames(&up, "x1000c0s0b0", hms_certs.CertDomainBMC, &hsmDoms)
+	if (err != nil) || (strings.Join(names, ",") != "x1000c0s0b0") {
+		t.Errorf("Expected the BMC itself, got %v %v", names, err)
+	}
+}
+
+func TestCheckUploadTargets(t *testing.T) {
+	up := bmcUploadCert{ID: "x1000", Targets: []string{"x1000c0s0b0", "x1000c1s0b0:8443"}}
+	if err := checkUploadTargets(&up, "x1000", hms_certs.CertDomainCabinet); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	up.Targets = append(up.Targets, "x1001c0s0b0")
+	if err := checkUploadTargets(&up, "x1000", hms_certs.CertDomainCabinet); err == nil {
+		t.Errorf("Expected an error for a target in another domain")
+	}
+	up.Targets = []string{"foo"}
+	if err := checkUploadTargets(&up, "x1000", hms_certs.CertDomainCabinet); err == nil {
+		t.Errorf("Expected an error for a bad target")
+	}
+}
+
+func TestDoBMCUploadCertsPost(t *testing.T) {
+	loggerSetup()
+	os.Setenv("VAULT_ENABLE", "0")
+	defer os.Unsetenv("VAULT_ENABLE")
+	hms_certs.Init(nil)
+	savedVault := appParams.VaultEnable
+	defer func() { appParams.VaultEnable = savedVault }()
+	appParams.VaultEnable = nil
+
+	year := time.Now().Add(365 * 24 * time.Hour)
+	root, _ := makeTestSignedCert(t, "Site Root CA", nil, true, year, nil)
+	leaf, leafKey := makeTestSignedCert(t, "x1000c0s0b0", []string{"x1000c0s0b0"}, false, year, root)
+	defer hms_certs.DeleteCertData("x1000c0s0b0", true)
+	defer deleteCertMeta("x1000c0s0b0")
+
+	pld := bmcUploadCertPost{Domain: "BMC", CA: root.pem,
+		Certs: []bmcUploadCert{
+			{ID: "x1000c0s0b0", Cert: leaf.pem, Key: leafKey},
+			{ID: "x1000c0s0b0", Cert: leaf.pem, Key: leafKey},
+			{ID: "x1000c0s1b0", Cert: leaf.pem, Key: leafKey},
+			{ID: "x1000c0s2b0", Cert: leaf.pem, Key: leafKey, Targets: []string{"x1000c0s0b0"}},
+		}}
+	ba, _ := json.Marshal(&pld)
+	req := httptest.NewRequest(http.MethodPost, API_UPL_CERTS, strings.NewReader(string(ba)))
+	rr := httptest.NewRecorder()
+	doBMCUploadCertsPost(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Bad status code: %d, %s", rr.Code, rr.Body.String())
+	}
+	var rsp bmcManageCertPostRsp
+	json.Unmarshal(rr.Body.Bytes(), &rsp)
+	exp := []int{http.StatusOK, http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest}
+	if len(rsp.DomainIDs) != len(exp) {
+		t.Fatalf("Expected %d results, got %v", len(exp), rsp.DomainIDs)
+	}
+	for ix, code := range exp {
+		if rsp.DomainIDs[ix].StatusCode != code {
+			t.Errorf("Result %d: expected %d, got %d/%s", ix, code,
+				rsp.DomainIDs[ix].StatusCode, rsp.DomainIDs[ix].StatusMsg)
+		}
+	}
+
+	vcert, err := hms_certs.FetchCertData("x1000c0s0b0", hms_certs.CertDomainBMC)
+	if err != nil {
+		t.Fatalf("Uploaded cert not stored: %v", err)
+	}
+	stored, err := parseLeafCert(vcert.Data.Certificate)
+	if (err != nil) || !stored.Equal(leaf.cert) || (vcert.Data.PrivateKey == "") {
+		t.Errorf("Stored cert doesn't match the uploaded one: %v", err)
+	}
+	meta, err := fetchCertMeta("x1000c0s0b0")
+	if (err != nil) || (meta.Source != CertSourceUploaded) {
+		t.Errorf("Expected the stored cert to be marked as uploaded, got %v %v", meta, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, API_UPL_CERTS,
+		strings.NewReader(`{"Domain":"Bogus","Certs":[{"ID":"x1000"}]}`))
+	rr = httptest.NewRecorder()
+	doBMCUploadCertsPost(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad domain, got %d", rr.Code)
+	}
+}
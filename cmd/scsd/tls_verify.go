@@ -0,0 +1,157 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Post-install TLS verification of pushed BMC certs.  A BMC returning
+// success for a cert replace or import doesn't mean it serves the new
+// cert; iLO for example only applies it after a reset.  Verification
+// reconnects to the targets over TLS and compares the served leaf cert with
+// the pushed one.
+
+const (
+	CertVerifyApplied     = "applied"       //serving the pushed cert
+	CertVerifyPending     = "pending reset" //still serving the old cert
+	CertVerifyMismatch    = "mismatch"      //serving some other cert
+	CertVerifyUnreachable = "unreachable"   //no TLS connection possible
+)
+
+const certVerifyWaitDflt = 30 //seconds
+
+var certVerifyPollInterval = 3 * time.Second
+
+// Get the verification wait time from a request's VerifyWait.
+//
+// secs(in): Seconds to wait, 0 for the default.
+// Return:   Wait time; error if secs is invalid.
+
+func getCertVerifyWait(secs int) (time.Duration, error) {
+	if secs < 0 {
+		return 0, fmt.Errorf("Invalid VerifyWait: %d", secs)
+	}
+	if secs == 0 {
+		secs = certVerifyWaitDflt
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// Get the fingerprints of the certs currently served by targets.  Targets
+// which can't be reached are left out.
+//
+// targets(in): BMC XNames, with optional :port.
+// Return:      Map of target to served cert fingerprint.
+
+func getServedCertFingerprints(targets []string) map[string]string {
+	var lock sync.Mutex
+	fps := make(map[string]string)
+
+	runBMCDials(len(targets), func(ii int) {
+		cert, err := getBMCCert(targets[ii])
+		if err != nil {
+			logger.Debugf("Can't get the served cert of '%s': %v", targets[ii], err)
+			return
+		}
+		lock.Lock()
+		fps[targets[ii]] = certFingerprint(cert)
+		lock.Unlock()
+	})
+	return fps
+}
+
+// Wait for a target to serve a pushed cert.
+//
+// target(in):   BMC XName, with optional :port.
+// pushedFP(in): Fingerprint of the pushed cert.
+// oldFP(in):    Fingerprint of the cert served before the push, if known.
+// deadline(in): Time to give up.
+// Return:       Verification result, one of the CertVerify* values.
+
+func verifyServedCert(target string, pushedFP string, oldFP string, deadline time.Time) string {
+	var servedFP string
+	var lastErr error
+
+	for {
+		cert, err := getBMCCert(target)
+		if err == nil {
+			servedFP = certFingerprint(cert)
+			if servedFP == pushedFP {
+				return CertVerifyApplied
+			}
+		} else {
+			lastErr = err
+		}
+		if time.Now().Add(certVerifyPollInterval).After(deadline) {
+			break
+		}
+		time.Sleep(certVerifyPollInterval)
+	}
+
+	switch {
+	case servedFP == "":
+		logger.Errorf("Can't verify the cert of '%s': %v", target, lastErr)
+		return CertVerifyUnreachable
+	case servedFP == oldFP:
+		return CertVerifyPending
+	default:
+		return CertVerifyMismatch
+	}
+}
+
+// Verify that targets serve the certs pushed to them, setting the
+// TLSVerify field of each target whose push succeeded.
+//
+// retData(inout): Results of the cert push.
+// pushed(in):     PEM cert pushed to each target.
+// before(in):     Fingerprint of the cert each target served before the push.
+// wait(in):       How long to wait for the targets to serve the pushed certs.
+
+func verifyPushedCerts(retData *rfCertPostRsp, pushed map[string]string, before map[string]string, wait time.Duration) {
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(wait)
+
+	for ii := 0; ii < len(retData.Targets); ii++ {
+		elm := &retData.Targets[ii]
+		pem, ok := pushed[elm.ID]
+		if !ok || !statusCodeOK(elm.StatusCode) {
+			continue
+		}
+		pushedCert, err := parseLeafCert(pem)
+		if err != nil {
+			logger.Errorf("Can't parse the cert pushed to '%s': %v", elm.ID, err)
+			elm.TLSVerify = CertVerifyMismatch
+			continue
+		}
+
+		wg.Add(1)
+		go func(elm *certRsp, pushedFP string) {
+			defer wg.Done()
+			elm.TLSVerify = verifyServedCert(elm.ID, pushedFP, before[elm.ID], deadline)
+		}(elm, certFingerprint(pushedCert))
+	}
+	wg.Wait()
+}
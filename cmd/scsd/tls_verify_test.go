@@ -0,0 +1,109 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func startTLSTestServer(t *testing.T, certPEM string, keyPEM string) *httptest.Server {
+	pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+	srv.StartTLS()
+	return srv
+}
+
+func TestGetCertVerifyWait(t *testing.T) {
+	wait, err := getCertVerifyWait(0)
+	if err != nil || wait != certVerifyWaitDflt*time.Second {
+		t.Errorf("Expected default wait, got %v %v", wait, err)
+	}
+	wait, err = getCertVerifyWait(5)
+	if err != nil || wait != 5*time.Second {
+		t.Errorf("Expected 5s wait, got %v %v", wait, err)
+	}
+	if _, err = getCertVerifyWait(-1); err == nil {
+		t.Errorf("Expected error with negative wait")
+	}
+}
+
+func TestVerifyPushedCerts(t *testing.T) {
+	loggerSetup()
+	savedInterval := certVerifyPollInterval
+	defer func() { certVerifyPollInterval = savedInterval }()
+	certVerifyPollInterval = 50 * time.Millisecond
+
+	servedCert, servedPEM, servedKey := makeTestCert(t, "x0c0s1b0", time.Hour)
+	_, newPEM, _ := makeTestCert(t, "x0c0s1b0", time.Hour)
+	_, otherPEM, _ := makeTestCert(t, "x0c0s1b0", time.Hour)
+
+	srv := startTLSTestServer(t, servedPEM, servedKey)
+	defer srv.Close()
+	targ := srv.Listener.Addr().String()
+
+	before := getServedCertFingerprints([]string{targ, "127.0.0.1:1"})
+	if (len(before) != 1) || (before[targ] != certFingerprint(servedCert)) {
+		t.Fatalf("Wrong served fingerprints: %v", before)
+	}
+
+	tests := []struct {
+		name   string
+		pushed string
+		before map[string]string
+		code   int
+		exp    string
+	}{
+		{"applied", servedPEM, before, http.StatusOK, CertVerifyApplied},
+		{"pending", newPEM, before, http.StatusOK, CertVerifyPending},
+		{"mismatch", newPEM, map[string]string{targ: certFingerprint(servedCert) + "x"}, http.StatusOK, CertVerifyMismatch},
+		{"bad cert", "junk", before, http.StatusOK, CertVerifyMismatch},
+		{"failed push", otherPEM, before, http.StatusInternalServerError, ""},
+	}
+
+	for _, tc := range tests {
+		retData := rfCertPostRsp{Targets: []certRsp{{ID: targ, StatusCode: tc.code}}}
+		verifyPushedCerts(&retData, map[string]string{targ: tc.pushed}, tc.before,
+			200*time.Millisecond)
+		if retData.Targets[0].TLSVerify != tc.exp {
+			t.Errorf("%s: expected '%s', got '%s'", tc.name, tc.exp,
+				retData.Targets[0].TLSVerify)
+		}
+	}
+
+	srv.Close()
+	retData := rfCertPostRsp{Targets: []certRsp{{ID: targ, StatusCode: http.StatusOK}}}
+	verifyPushedCerts(&retData, map[string]string{targ: newPEM}, before,
+		100*time.Millisecond)
+	if retData.Targets[0].TLSVerify != CertVerifyUnreachable {
+		t.Errorf("Expected '%s' for a closed server, got '%s'",
+			CertVerifyUnreachable, retData.Targets[0].TLSVerify)
+	}
+}
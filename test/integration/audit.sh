@@ -0,0 +1,56 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script checks the audit log.  By now the earlier tests have loaded
+# config onto the fake BMCs, which should be recorded, with the SSH keys
+# redacted.
+
+curl -D hout "http://${SCSD}/v1/audit?endpoint=/v1/bmc/loadcfg" | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+nrecs=`cat out.txt | jq '.Records | length'`
+if [[ $scode -ne 200 || $nrecs -lt 1 ]]; then
+	echo "Unexpected audit query result: ${scode}, ${nrecs} records."
+	exit 1
+fi
+
+nkeys=`cat out.txt | grep -c 'aabbccdd'`
+if [[ $nkeys -ne 0 ]]; then
+	echo "SSH keys not redacted in audit records."
+	exit 1
+fi
+
+curl -D hout "http://${SCSD}/v1/audit?since=yesterday" > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if [[ $scode -ne 400 ]]; then
+	echo "Bad status code from bad audit query: ${scode}"
+	exit 1
+fi
+
+exit 0
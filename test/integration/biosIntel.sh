@@ -0,0 +1,347 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2025] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+if [ -z $SCSD ]; then
+    echo "MISSING SCSD ENV VAR."
+    exit 1
+fi
+if [ -z $X_INTEL_HOST ]; then
+    echo "MISSING X_INTEL_HOST ENV VAR."
+    exit 1
+fi
+
+node=${X_INTEL_HOST}n0
+
+echo "====================================================================="
+echo "Intel BIOS: get TPM state"
+echo "====================================================================="
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node}/tpmstate | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel TPM state get: ${scode}"
+	exit 1
+fi
+
+future=`cat out.txt | jq -r .Future`
+if [[ "${future}" != "Disabled" ]]; then
+	echo "Bad initial Intel TPM future state: ${future}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: enable TPM"
+echo "====================================================================="
+
+curl -D hout -X PATCH -d '{"Future":"Enabled"}' http://${SCSD}/v1/bmc/bios/${node}/tpmstate
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 204 )); then
+	echo "Bad status code from Intel TPM state patch: ${scode}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: verify pending TPM state"
+echo "====================================================================="
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node}/tpmstate | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel TPM state get: ${scode}"
+	exit 1
+fi
+
+current=`cat out.txt | jq -r .Current`
+future=`cat out.txt | jq -r .Future`
+if [[ "${current}" != "Disabled" || "${future}" != "Enabled" ]]; then
+	echo "Bad Intel TPM state after patch, Current: ${current} Future: ${future}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: bulk disable TPM"
+echo "====================================================================="
+
+pld='{"Future":"Disabled","Targets":["'${node}'"]}'
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/bios/load/tpmstate | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel bulk TPM state load: ${scode}"
+	exit 1
+fi
+
+tcode=`cat out.txt | jq '.Targets[0].StatusCode'`
+if (( tcode != 200 )); then
+	echo "Bad target status code from Intel bulk TPM state load: ${tcode}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: bulk get TPM state"
+echo "====================================================================="
+
+pld='{"Targets":["'${node}'"]}'
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/bios/dump/tpmstate | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel bulk TPM state dump: ${scode}"
+	exit 1
+fi
+
+future=`cat out.txt | jq -r '.Targets[0].Future'`
+if [[ "${future}" != "Disabled" ]]; then
+	echo "Bad Intel TPM future state after bulk load: ${future}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: disable SMT"
+echo "====================================================================="
+
+curl -D hout -X PATCH -d '{"Future":"Disabled"}' http://${SCSD}/v1/bmc/bios/${node}/smt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 204 )); then
+	echo "Bad status code from Intel SMT patch: ${scode}"
+	exit 1
+fi
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node}/smt | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel SMT get: ${scode}"
+	exit 1
+fi
+
+current=`cat out.txt | jq -r .Current`
+future=`cat out.txt | jq -r .Future`
+if [[ "${current}" != "Enabled" || "${future}" != "Disabled" ]]; then
+	echo "Bad Intel SMT state after patch, Current: ${current} Future: ${future}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: get pending settings"
+echo "====================================================================="
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node} | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel pending BIOS get: ${scode}"
+	exit 1
+fi
+
+npending=`cat out.txt | jq '.Pending | length'`
+if (( npending == 0 )); then
+	echo "Expected pending Intel BIOS settings"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: clear pending settings"
+echo "====================================================================="
+
+curl -D hout -X DELETE http://${SCSD}/v1/bmc/bios/${node}
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 204 )); then
+	echo "Bad status code from Intel pending BIOS delete: ${scode}"
+	exit 1
+fi
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node} | jq > out.txt
+cat out.txt
+echo " "
+
+npending=`cat out.txt | jq '.Pending | length'`
+if (( npending != 0 )); then
+	echo "Expected no pending Intel BIOS settings after clear, got ${npending}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: reset to defaults"
+echo "====================================================================="
+
+curl -D hout -X POST http://${SCSD}/v1/bmc/bios/${node}/reset | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel BIOS reset: ${scode}"
+	exit 1
+fi
+
+reboot=`cat out.txt | jq -r .RebootRequired`
+if [[ "${reboot}" != "true" ]]; then
+	echo "Expected a reboot to be required after the Intel BIOS reset"
+	exit 1
+fi
+
+pld='{"Targets":["'${node}'"]}'
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/bios/reset | jq > out.txt
+cat out.txt
+echo " "
+
+tcode=`cat out.txt | jq '.Targets[0].StatusCode'`
+if (( tcode != 200 )); then
+	echo "Bad target status code from Intel bulk BIOS reset: ${tcode}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: enable SMT with a reboot"
+echo "====================================================================="
+
+curl -D hout -X PATCH -d '{"Future":"Enabled","Reboot":true}' http://${SCSD}/v1/bmc/bios/${node}/smt | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+	echo "Bad status code from Intel SMT patch with reboot: ${scode}"
+	exit 1
+fi
+
+pending=`cat out.txt | jq -r .ResetPending`
+if [[ "${pending}" != "false" ]]; then
+	echo "Expected no pending reset after the reboot, got ${pending}"
+	exit 1
+fi
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node}/smt | jq > out.txt
+cat out.txt
+echo " "
+
+current=`cat out.txt | jq -r .Current`
+if [[ "${current}" != "Enabled" ]]; then
+	echo "Expected SMT to be enabled after the reboot, got ${current}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: enable Secure Boot"
+echo "====================================================================="
+
+curl -D hout -X PATCH -d '{"SecureBootEnable":true}' http://${SCSD}/v1/bmc/bios/${node}/secureboot
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 204 )); then
+	echo "Bad status code from Intel Secure Boot patch: ${scode}"
+	exit 1
+fi
+
+curl -D hout http://${SCSD}/v1/bmc/bios/${node}/secureboot | jq > out.txt
+cat out.txt
+echo " "
+
+enable=`cat out.txt | jq -r .SecureBootEnable`
+if [[ "${enable}" != "true" ]]; then
+	echo "Expected Secure Boot to be enabled, got ${enable}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: bulk Secure Boot disable"
+echo "====================================================================="
+
+curl -D hout -X POST -d '{"Targets":["'${node}'"],"SecureBootEnable":false}' http://${SCSD}/v1/bmc/bios/load/secureboot | jq > out.txt
+cat out.txt
+echo " "
+
+tcode=`cat out.txt | jq '.Targets[0].StatusCode'`
+enable=`cat out.txt | jq -r '.Targets[0].SecureBootEnable'`
+if (( tcode != 200 )) || [[ "${enable}" != "false" ]]; then
+	echo "Bad result from Intel bulk Secure Boot load: ${tcode} ${enable}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: set the BIOS password"
+echo "====================================================================="
+
+curl -D hout -X POST -d '{"NewPassword":"Initial123"}' http://${SCSD}/v1/bmc/bios/${node}/password
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 204 )); then
+	echo "Bad status code from Intel BIOS password change: ${scode}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: bulk BIOS password change using the password in Vault"
+echo "====================================================================="
+
+curl -D hout -X POST -d '{"Targets":["'${node}'"]}' http://${SCSD}/v1/bmc/bios/password | jq > out.txt
+cat out.txt
+echo " "
+
+tcode=`cat out.txt | jq '.Targets[0].StatusCode'`
+if (( tcode != 200 )); then
+	echo "Bad target status code from Intel bulk BIOS password change: ${tcode}"
+	exit 1
+fi
+
+echo "====================================================================="
+echo "Intel BIOS: compare with a baseline"
+echo "====================================================================="
+
+pld='{"Targets":["'${node}'"],"Attributes":{"ProcessorHyperThreadingDisable":0,"TpmOperation":2},"Ignore":["Tpm*"]}'
+curl -D hout -X POST -d "${pld}" http://${SCSD}/v1/bmc/bios/compare | jq > out.txt
+cat out.txt
+echo " "
+
+tcode=`cat out.txt | jq '.Targets[0].StatusCode'`
+matches=`cat out.txt | jq -r '.Targets[0].Matches'`
+if (( tcode != 200 )) || [[ "${matches}" != "true" ]]; then
+	echo "Bad result from Intel BIOS compare: ${tcode} ${matches}"
+	exit 1
+fi
+
+exit 0
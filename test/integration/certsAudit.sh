@@ -0,0 +1,48 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script audits the cabinet-domain certs of the x0 BMCs in HSM.  The
+# earlier tests leave the BMCs with various certs, so only the shape of
+# the report is checked.
+
+pld='{"CertDomain":"Cabinet","DomainIDs":["x0"]}'
+
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/certaudit | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if [[ $scode -ne 200 ]]; then
+	echo "Bad status code from cert audit: ${scode}"
+	exit 1
+fi
+
+ntargs=`cat out.txt | jq '.Targets | length'`
+nbad=`cat out.txt | jq '[.Targets[] | select((.DomainID != "x0") or (.Status | IN("match","drifted","missing","unreachable","skipped") | not))] | length'`
+if [[ $ntargs -eq 0 || $nbad -ne 0 ]]; then
+	echo "Unexpected cert audit report: ${ntargs} targets, ${nbad} bad."
+	exit 1
+fi
+
+exit 0
@@ -0,0 +1,57 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script creates a BMC-domain cert with an extra DNS name, an IP SAN,
+# a 3072 bit RSA key and a 30 day validity period, then fetches it and
+# checks that the cert has them.
+
+pld='{"Domain":"BMC","DomainIDs":["x0c0s9b0"],"AltNames":["bmc-s9"],"IPAddresses":["10.254.1.9"],"KeyType":"RSA","KeyBits":3072,"ValidityDays":30}'
+
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/createcerts | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+scode2=`cat out.txt | grep StatusCode | grep -v 200`
+if [[ $scode -ne 200 || "${scode2}" != "" ]]; then
+	echo "Bad status code from cert create with options: ${scode}"
+	exit 1
+fi
+
+pld='{"Domain":"BMC","DomainIDs":["x0c0s9b0"]}'
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/fetchcerts | jq > out.txt
+crt=`cat out.txt | jq -r '.DomainIDs[0].Cert.Cert'`
+txt=`echo "$crt" | openssl x509 -noout -text`
+curl -X POST -d "$pld" http://${SCSD}/v1/bmc/deletecerts > /dev/null 2>&1
+
+echo "$txt" | grep -q "DNS:bmc-s9" && \
+	echo "$txt" | grep -q "IP Address:10.254.1.9" && \
+	echo "$txt" | grep -q "Public-Key: (3072 bit)"
+if [ $? -ne 0 ]; then
+	echo "Created cert does not have the requested SANs or key:"
+	echo "$txt"
+	exit 1
+fi
+
+exit 0
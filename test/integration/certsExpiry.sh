@@ -0,0 +1,58 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script gets the cert expiration inventory of 2 BMCs.  The fake BMCs
+# serve http, so only the Vault side of each target is checked.
+
+pldx='{"Force":false,"CertDomain":"Cabinet","Targets":["X_S5_HOST:XP5","X_S1_HOST:XP1"]}'
+
+source portFix.sh
+pld=`portFix "$pldx"`
+
+curl -D hout -X POST -d "$pld"  http://${SCSD}/v1/bmc/certexpiry | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if [[ $scode -ne 200 ]]; then
+	echo "Bad status code from cert expiry inventory: ${scode}"
+	exit 1
+fi
+
+ntargs=`cat out.txt | jq '.Targets | length'`
+nvault=`cat out.txt | jq '[.Targets[] | select(.VaultCert.NotAfter != null)] | length'`
+if [[ $ntargs -ne 2 || $nvault -ne 2 ]]; then
+	echo "Expected 2 targets with Vault certs, got ${ntargs}/${nvault}"
+	exit 1
+fi
+
+pld='{"Force":true,"Targets":["x0c0s0b0"],"ExpiresWithinDays":-1}'
+curl -D hout -X POST -d "$pld"  http://${SCSD}/v1/bmc/certexpiry > out.txt
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if [[ $scode -ne 400 ]]; then
+	echo "Bad status code from cert expiry inventory with bad days: ${scode}"
+	exit 1
+fi
+
+exit 0
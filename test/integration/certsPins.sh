@@ -0,0 +1,61 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script checks the TLS cert pin endpoints.  Pinning is not enabled in
+# the test environment, and the fake BMCs use plain http, so there are no
+# pins.
+
+curl -D hout http://${SCSD}/v1/bmc/tlspins | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+enabled=`cat out.txt | jq '.Enabled'`
+npins=`cat out.txt | jq '.Pins | length'`
+if [[ $scode -ne 200 || "$enabled" != "false" || $npins -ne 0 ]]; then
+	echo "Unexpected TLS pin list: ${scode}, enabled ${enabled}, ${npins} pins."
+	exit 1
+fi
+
+curl -D hout http://${SCSD}/v1/bmc/tlspins/${X_S0_HOST} > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if [[ $scode -ne 404 ]]; then
+	echo "Bad status code from missing TLS pin: ${scode}"
+	exit 1
+fi
+
+curl -D hout -X POST http://${SCSD}/v1/bmc/tlspins/${X_S0_HOST}/ack > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if [[ $scode -ne 404 ]]; then
+	echo "Bad status code from acknowledging a missing TLS pin: ${scode}"
+	exit 1
+fi
+
+exit 0
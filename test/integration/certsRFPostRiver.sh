@@ -0,0 +1,49 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script applies a cert to 2 River BMCs, one Intel and one Gigabyte.
+
+if [ -z $X_INTEL_HOST ]; then
+    echo "MISSING X_INTEL_HOST ENV VAR."
+    exit 1
+fi
+if [ -z $X_GB_HOST ]; then
+    echo "MISSING X_GB_HOST ENV VAR."
+    exit 1
+fi
+
+pld='{"Force":false,"CertDomain":"Cabinet","Targets":["'${X_INTEL_HOST}'","'${X_GB_HOST}'"]}'
+
+curl -D hout -X POST -d "$pld"  http://${SCSD}/v1/bmc/setcerts | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+scode2=`cat out.txt | grep StatusCode | grep -v 200`
+if [[ $scode -ne 200 || "${scode2}" != "" ]]; then
+	echo "Bad status code from River BMC cert replace: ${scode}"
+	exit 1
+fi
+
+exit 0
@@ -0,0 +1,93 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script creates a site CA, installs it in the CA trust store of two
+# BMCs, checks that it is listed, and removes it again.
+
+if [ -z $X_S0_HOST ]; then
+    echo "MISSING X_S0_HOST ENV VAR."
+    exit 1
+fi
+if [ -z $X_INTEL_HOST ]; then
+    echo "MISSING X_INTEL_HOST ENV VAR."
+    exit 1
+fi
+
+tdir=`mktemp -d`
+cd $tdir
+
+openssl req -x509 -newkey rsa:2048 -nodes -keyout ca.key -out ca.crt \
+	-days 30 -subj "/CN=Trust Test CA" > /dev/null 2>&1
+if [ $? -ne 0 ]; then
+	echo "Can't create test CA cert."
+	exit 1
+fi
+
+ca=`jq -Rs . < ca.crt`
+targs='"Force":true,"Targets":["'${X_S0_HOST}'","'${X_INTEL_HOST}'"]'
+pld='{'${targs}',"CABundle":'${ca}'}'
+
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/trustcerts | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+nbad=`cat out.txt | jq '[.Targets[] | select(.StatusCode != 200)] | length'`
+if [[ $scode -ne 200 || $nbad -ne 0 ]]; then
+	echo "Bad status codes from CA trust cert install: ${scode}, ${nbad} bad."
+	cd /
+	rm -rf $tdir
+	exit 1
+fi
+
+curl -D hout -X POST -d '{'${targs}'}' http://${SCSD}/v1/bmc/fetchtrustcerts | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+fp=`cat out.txt | jq -r '[.Targets[0].Certs[] | select(.Subject == "CN=Trust Test CA")][0].Fingerprint'`
+nmiss=`cat out.txt | jq --arg fp "$fp" '[.Targets[] | select([.Certs[]? | .Fingerprint] | index($fp) | not)] | length'`
+if [[ $scode -ne 200 || "$fp" == "null" || $nmiss -ne 0 ]]; then
+	echo "CA trust cert not listed on all BMCs: ${scode}, ${nmiss} missing."
+	cd /
+	rm -rf $tdir
+	exit 1
+fi
+
+pld='{'${targs}',"Fingerprints":["'${fp}'"]}'
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/deletetrustcerts | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+nbad=`cat out.txt | jq '[.Targets[] | select(.StatusCode != 200)] | length'`
+cd /
+rm -rf $tdir
+
+if [[ $scode -ne 200 || $nbad -ne 0 ]]; then
+	echo "Bad status codes from CA trust cert removal: ${scode}, ${nbad} bad."
+	exit 1
+fi
+
+exit 0
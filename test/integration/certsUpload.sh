@@ -0,0 +1,68 @@
+#!/bin/bash
+
+# MIT License
+#
+# (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+#
+# Permission is hereby granted, free of charge, to any person obtaining a
+# copy of this software and associated documentation files (the "Software"),
+# to deal in the Software without restriction, including without limitation
+# the rights to use, copy, modify, merge, publish, distribute, sublicense,
+# and/or sell copies of the Software, and to permit persons to whom the
+# Software is furnished to do so, subject to the following conditions:
+#
+# The above copyright notice and this permission notice shall be included
+# in all copies or substantial portions of the Software.
+#
+# THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+# IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+# FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+# THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+# OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+# ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+# OTHER DEALINGS IN THE SOFTWARE.
+
+# This script creates a site CA and a cert issued by it, and uploads the
+# cert for a BMC.  A cert whose SANs don't cover its BMC must be rejected.
+
+if [ -z $X_INTEL_HOST ]; then
+    echo "MISSING X_INTEL_HOST ENV VAR."
+    exit 1
+fi
+
+tdir=`mktemp -d`
+cd $tdir
+
+openssl req -x509 -newkey rsa:2048 -nodes -keyout ca.key -out ca.crt \
+	-days 30 -subj "/CN=Site CA" > /dev/null 2>&1
+openssl req -newkey rsa:2048 -nodes -keyout leaf.key -out leaf.csr \
+	-subj "/CN=${X_INTEL_HOST}" > /dev/null 2>&1
+printf "subjectAltName=DNS:${X_INTEL_HOST}\nextendedKeyUsage=serverAuth\n" > ext
+openssl x509 -req -in leaf.csr -CA ca.crt -CAkey ca.key -CAcreateserial \
+	-out leaf.crt -days 10 -extfile ext > /dev/null 2>&1
+if [ $? -ne 0 ]; then
+	echo "Can't create test certs."
+	exit 1
+fi
+
+ca=`jq -Rs . < ca.crt`
+cert=`jq -Rs . < leaf.crt`
+key=`jq -Rs . < leaf.key`
+pld='{"Domain":"BMC","CA":'${ca}',"Certs":[{"ID":"'${X_INTEL_HOST}'","Cert":'${cert}',"Key":'${key}'},{"ID":"x0c0s9b0","Cert":'${cert}',"Key":'${key}'}]}'
+
+curl -D hout -X POST -d "$pld" http://${SCSD}/v1/bmc/uploadcerts | jq > out.txt
+cat out.txt
+echo " "
+
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+scode1=`cat out.txt | jq '.DomainIDs[0].StatusCode'`
+scode2=`cat out.txt | jq '.DomainIDs[1].StatusCode'`
+cd /
+rm -rf $tdir
+
+if [[ $scode -ne 200 || $scode1 -ne 200 || $scode2 -ne 400 ]]; then
+	echo "Bad status codes from cert upload: ${scode}/${scode1}/${scode2}"
+	exit 1
+fi
+
+exit 0
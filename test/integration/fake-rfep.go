@@ -23,7 +23,12 @@
 package main
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"fmt"
 	"log"
@@ -51,7 +56,9 @@ import (
 //               NTP    Only use NTP data
 //               ???    Any other value == use ALL NWP data.
 //
-// VENDOR  'cray' or 'hpe'.  Determines the behavior of Certificate Service.
+// VENDOR  'cray', 'hpe' or 'intel'.  Determines the behavior of Certificate
+//         Service and of the CA trust store.  'intel' also selects the RackMount chassis layout and
+//         the Systems/BIOS settings object used by the BIOS APIs.
 //
 // BMCPORT Determines the port used in the host:port of the app instance.
 //         Defaults to 20000 e.g. http://${X_S0_HOST}:2000/redfish/v1/...
@@ -59,6 +66,8 @@ import (
 // XNAME   Determines which BMC XName the app is pretending to be.  Defaults is
 //         ${X_S0_HOST}.
 //
+// RFUUID  UUID shown in the service root, used by SCSD's BMC identity check.
+//
 // BADACCT Used for debugging, to force AccountService data to be incorrect.
 //
 // HTTPS   Not set: use http.  
@@ -115,6 +124,7 @@ type rfManagersBMCNWP struct {
 
 type rfManagersBMC struct {
 	NetworkProtocol rfManagersBMCNWP
+	SecurityPolicy *rfManagersBMCNWP `json:"SecurityPolicy,omitempty"`
 	//HPE stuff
 	Oem *rfManagersOem `json:"Oem,omitempty"`
 }
@@ -302,6 +312,10 @@ var certSvcCrayPld = `{
     "#CertificateService.ReplaceCertificate": {
       "@Redfish.ActionInfo": "/redfish/v1/CertificateService/ReplaceCertificateActionInfo",
       "target": "/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate"
+    },
+    "#CertificateService.GenerateCSR": {
+      "@Redfish.ActionInfo": "/redfish/v1/CertificateService/GenerateCSRActionInfo",
+      "target": "/redfish/v1/CertificateService/Actions/CertificateService.GenerateCSR"
     }
   },
   "CertificateLocations": {
@@ -317,8 +331,15 @@ var certSvcCrayPld = `{
 var burl = "BMC"
 var nwpType = ""
 var isHPE = true
+var isIntel = false
+var isGB = false
 var ishttps = false
 var replaceCert = false
+var csrKey = ""    //key of the last generated CSR
+var trustCerts = make(map[string]string)  //trusted CA certs by URI
+var trustCertID = 0
+var rfUUID = "8b2a3c1e-6f0d-4d5e-9a7b-2c4e6f8a0b1d"  //service root UUID
+var hpeCSR = ""    //last HPE CSR, shows up in HttpsCert
 var tlsCertFile = "/tmp/server.crt"
 var tlsKeyFile = "/tmp/server.key"
 var port = ":20000"
@@ -351,6 +372,18 @@ func (p *httpStuff) certificateService(w http.ResponseWriter, r *http.Request) {
 }
 
 
+// HTTPS cert location.  Intel and Gigabyte BMCs use their own manager IDs.
+
+func certLocation() string {
+	if (isIntel) {
+		return "/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates/1"
+	}
+	if (isGB) {
+		return "/redfish/v1/Managers/Self/NetworkProtocol/HTTPS/Certificates/1"
+	}
+	return "/redfish/v1/Managers/BMC/NetworkProtocol/HTTPS/Certificates/1"
+}
+
 func (p *httpStuff) certificateLocations(w http.ResponseWriter, r *http.Request) {
 	pld := `{
   "@odata.context": "/redfish/v1/$metadata#CertificateLocations.CertificateLocations",
@@ -361,7 +394,7 @@ func (p *httpStuff) certificateLocations(w http.ResponseWriter, r *http.Request)
   "Links": {
     "Certificates": [
       {
-        "@odata.id": "/redfish/v1/Managers/BMC/NetworkProtocol/HTTPS/Certificates/1"
+        "@odata.id": "` + certLocation() + `"
       }
     ]
   }
@@ -404,14 +437,39 @@ func (p *httpStuff) certificateReplace(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	//River BMCs are strict about the cert URI and type.
+
+	if (isIntel || isGB) {
+		if ((jdata.CertificateURI == nil) || (jdata.CertificateURI.Uri != certLocation())) {
+			log.Printf("ERROR: bad cert URI: %v",jdata.CertificateURI)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if (jdata.CertificateType != "PEM") {
+			log.Printf("ERROR: bad cert type: '%s'",jdata.CertificateType)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
 	//actually try to replace the cert and restart the HTTP server if
 	//the flag says to do so.
 
 	if (replaceCert && ishttps) {
-		//Disassemble cert
+		//Disassemble cert.  A cert without a key is from a CSR, so use
+		//the CSR's key.
+		certStr := jdata.CertificateString
+		if (!strings.Contains(certStr,"PRIVATE KEY")) {
+			if (csrKey == "") {
+				log.Printf("ERROR: cert has no key and no CSR was generated.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			certStr = certStr + "\\n" + strings.Replace(csrKey,"\n","\\n",-1)
+		}
 		tlsCertFile = "/tmp/newserver.crt"
 		tlsKeyFile = "/tmp/newserver.key"
-		derr := dumpCertInfo(jdata.CertificateString)
+		derr := dumpCertInfo(certStr)
 		if (derr != nil) {
 			log.Printf("ERROR dumping cert info: %v",derr)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -424,6 +482,88 @@ func (p *httpStuff) certificateReplace(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// Generate a key pair and CSR.  The key is kept for a subsequent cert
+// replacement.
+
+func genCSR(cn string) (string,error) {
+	key,err := rsa.GenerateKey(rand.Reader,2048)
+	if (err != nil) {
+		return "",err
+	}
+	tmpl := x509.CertificateRequest{Subject: pkix.Name{CommonName: cn},
+	                                DNSNames: []string{cn},}
+	der,err := x509.CreateCertificateRequest(rand.Reader,&tmpl,key)
+	if (err != nil) {
+		return "",err
+	}
+	csrKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY",
+	                Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST",
+	              Bytes: der})),nil
+}
+
+func (p *httpStuff) certificateGenerateCSR(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != "POST") {
+        fmt.Printf("ERROR: request is not a POST.\n")
+        w.WriteHeader(http.StatusMethodNotAllowed)
+        return
+    }
+
+	printReqHdrs("certificateGenerateCSR",r)
+	var jdata map[string]interface{}
+	body,_ := ioutil.ReadAll(r.Body)
+	log.Printf("GenerateCSR payload: '%s'",string(body))
+	err := json.Unmarshal(body,&jdata)
+	if (err != nil) {
+		fmt.Println("ERROR unmarshalling data:",err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cn,_ := jdata["CommonName"].(string)
+	csr,err := genCSR(cn)
+	if (err != nil) {
+		log.Printf("ERROR generating CSR: %v",err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rsp := map[string]interface{}{"CSRString": csr,
+	                              "CertificateCollection": jdata["CertificateCollection"]}
+	ba,_ := json.Marshal(&rsp)
+	w.Header().Set("Content-Type","application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+func (p *httpStuff) hpeGenerateCSR(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != "POST") {
+        fmt.Printf("ERROR: request is not a POST.\n")
+        w.WriteHeader(http.StatusMethodNotAllowed)
+        return
+    }
+
+	printReqHdrs("hpeGenerateCSR",r)
+	var jdata map[string]interface{}
+	body,_ := ioutil.ReadAll(r.Body)
+	log.Printf("HPE GenerateCSR payload: '%s'",string(body))
+	err := json.Unmarshal(body,&jdata)
+	if (err != nil) {
+		fmt.Println("ERROR unmarshalling data:",err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cn,_ := jdata["CommonName"].(string)
+	hpeCSR,err = genCSR(cn)
+	if (err != nil) {
+		log.Printf("ERROR generating CSR: %v",err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (p *httpStuff) Chassis(w http.ResponseWriter, r *http.Request) {
 	crayPld := `{
   "@odata.context": "/redfish/v1/$metadata#ChassisCollection.ChassisCollection",
@@ -461,10 +601,40 @@ func (p *httpStuff) Chassis(w http.ResponseWriter, r *http.Request) {
   "Name": "Computer System Chassis"
 }`
 
+	intelPld := `{
+  "@odata.context": "/redfish/v1/$metadata#ChassisCollection.ChassisCollection",
+  "@odata.id": "/redfish/v1/Chassis",
+  "@odata.type": "#ChassisCollection.ChassisCollection",
+  "Members": [
+    {
+      "@odata.id": "/redfish/v1/Chassis/RackMount"
+    }
+  ],
+  "Members@odata.count": 1,
+  "Name": "Chassis Collection"
+}`
+
+	gbPld := `{
+  "@odata.context": "/redfish/v1/$metadata#ChassisCollection.ChassisCollection",
+  "@odata.id": "/redfish/v1/Chassis",
+  "@odata.type": "#ChassisCollection.ChassisCollection",
+  "Members": [
+    {
+      "@odata.id": "/redfish/v1/Chassis/Self"
+    }
+  ],
+  "Members@odata.count": 1,
+  "Name": "Chassis Collection"
+}`
+
 	var pld string
 
 	if (isHPE) {
 		pld = hpePld
+	} else if (isIntel) {
+		pld = intelPld
+	} else if (isGB) {
+		pld = gbPld
 	} else {
 		pld = crayPld
 	}
@@ -520,6 +690,254 @@ func (p *httpStuff) chassisSelf(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(pld))
 }
 
+// Systems and BIOS, pretends to be a river intel endpoint.  Intel BIOS
+// attribute values are numbers; PATCHes go to the Bios/Settings object and
+// are only pending until the next reboot, so they never show up in the
+// current settings.
+
+const intelSystemID = "BQWF73500342"
+
+var intelBiosCurrent = map[string]interface{}{
+	"TpmOperation": 0,
+	"Tpm2Operation": 0,
+	"ProcessorHyperThreadingDisable": 0,
+}
+var intelBiosFuture = map[string]interface{}{}
+var intelBiosEtag = 1
+
+//Bios.ResetBios stages these values as the future settings
+
+var intelBiosDefaults = map[string]interface{}{
+	"TpmOperation": 1,
+	"Tpm2Operation": 1,
+	"ProcessorHyperThreadingDisable": 0,
+}
+
+var intelBiosAdminPassword = ""
+
+var intelSecureBootEnable = false
+var intelSecureBootEtag = 1
+
+func (p *httpStuff) systems(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("systems",r)
+	pld := `{"Members":[{"@odata.id":"/redfish/v1/Systems/`+intelSystemID+`"}],"Members@odata.count":1}`
+	w.Header().Set("Content-Type","application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(pld))
+}
+
+func (p *httpStuff) systemIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("systemIntel",r)
+	pld := `{"Id":"`+intelSystemID+`","Bios":{"@odata.id":"/redfish/v1/Systems/`+intelSystemID+`/Bios"},`+
+		`"SecureBoot":{"@odata.id":"/redfish/v1/Systems/`+intelSystemID+`/SecureBoot"},`+
+		`"Actions":{"#ComputerSystem.Reset":{"ResetType@Redfish.AllowableValues":["On","ForceOff","ForceRestart"],`+
+		`"target":"/redfish/v1/Systems/`+intelSystemID+`/Actions/ComputerSystem.Reset"}}}`
+	w.Header().Set("Content-Type","application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(pld))
+}
+
+//A reset of the system applies the pending BIOS settings
+
+func (p *httpStuff) systemResetIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("systemResetIntel",r)
+	if (r.Method != "POST") {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	for k,v := range(intelBiosFuture) {
+		intelBiosCurrent[k] = v
+	}
+	intelBiosFuture = map[string]interface{}{}
+	intelBiosEtag ++
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *httpStuff) biosIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("biosIntel",r)
+	if (r.Method != "GET") {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var pld = map[string]interface{}{
+		"@Redfish.Settings": map[string]interface{}{
+			"@odata.type": "#Settings.v1_0_0.Settings",
+			"SettingsObject": map[string]interface{}{
+				"@odata.id": "/redfish/v1/Systems/"+intelSystemID+"/Bios/Settings",
+			},
+			"SupportedApplyTimes": []string{"Immediate","OnReset"},
+		},
+		"Attributes": intelBiosCurrent,
+		"Actions": map[string]interface{}{
+			"#Bios.ResetBios": map[string]interface{}{
+				"target": "/redfish/v1/Systems/"+intelSystemID+"/Bios/Actions/Bios.ResetBios",
+			},
+			"#Bios.ChangePassword": map[string]interface{}{
+				"target": "/redfish/v1/Systems/"+intelSystemID+"/Bios/Actions/Bios.ChangePassword",
+			},
+		},
+	}
+	ba,_ := json.Marshal(pld)
+	w.Header().Set("Content-Type","application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+func (p *httpStuff) biosSettingsIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("biosSettingsIntel",r)
+	etag := fmt.Sprintf("W/\"%d\"",intelBiosEtag)
+
+	switch (r.Method) {
+	case "GET":
+		pld := map[string]interface{}{
+			"@odata.etag": etag,
+			"Attributes": intelBiosFuture,
+		}
+		ba,_ := json.Marshal(pld)
+		w.Header().Set("Content-Type","application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(ba)
+	case "PATCH":
+		ifMatch := r.Header.Get("If-Match")
+		if ((ifMatch != "") && (ifMatch != "*") && (ifMatch != etag)) {
+			log.Printf("ERROR: If-Match '%s' does not match etag '%s'",ifMatch,etag)
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var jdata struct {
+			Attributes map[string]interface{}
+			ApplyTime struct {
+				ApplyTime string
+			} `json:"@Redfish.SettingsApplyTime"`
+		}
+		body,_ := ioutil.ReadAll(r.Body)
+		err := json.Unmarshal(body,&jdata)
+		if (err != nil) {
+			log.Printf("ERROR unmarshalling BIOS settings: %v",err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for k,v := range(jdata.Attributes) {
+			_,ok := intelBiosCurrent[k]
+			if (!ok) {
+				log.Printf("ERROR: unknown BIOS attribute '%s'",k)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			//Intel BMCs reject strings for numeric attributes
+			_,ok = v.(float64)
+			if (!ok) {
+				log.Printf("ERROR: BIOS attribute '%s' value '%v' is not a number",k,v)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		for k,v := range(jdata.Attributes) {
+			if (jdata.ApplyTime.ApplyTime == "Immediate") {
+				intelBiosCurrent[k] = v
+				delete(intelBiosFuture,k)
+			} else {
+				intelBiosFuture[k] = v
+			}
+		}
+		intelBiosEtag ++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *httpStuff) biosResetIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("biosResetIntel",r)
+	if (r.Method != "POST") {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	intelBiosFuture = map[string]interface{}{}
+	for k,v := range(intelBiosDefaults) {
+		intelBiosFuture[k] = v
+	}
+	intelBiosEtag ++
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *httpStuff) biosChangePasswordIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("biosChangePasswordIntel",r)
+	if (r.Method != "POST") {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var jdata struct {
+		PasswordName string
+		OldPassword string
+		NewPassword string
+	}
+	body,_ := ioutil.ReadAll(r.Body)
+	err := json.Unmarshal(body,&jdata)
+	if (err != nil) {
+		log.Printf("ERROR unmarshalling BIOS password change: %v",err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if ((jdata.PasswordName != "AdminPassword") || (jdata.NewPassword == "")) {
+		log.Printf("ERROR: bad BIOS password change for '%s'",jdata.PasswordName)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if (jdata.OldPassword != intelBiosAdminPassword) {
+		log.Printf("ERROR: BIOS old password does not match")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	intelBiosAdminPassword = jdata.NewPassword
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//Secure Boot changes take effect on the next boot, so the current boot
+//state is left alone.
+
+func (p *httpStuff) secureBootIntel(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("secureBootIntel",r)
+	etag := fmt.Sprintf("W/\"%d\"",intelSecureBootEtag)
+
+	switch (r.Method) {
+	case "GET":
+		pld := map[string]interface{}{
+			"@odata.etag": etag,
+			"@odata.id": "/redfish/v1/Systems/"+intelSystemID+"/SecureBoot",
+			"SecureBootEnable": intelSecureBootEnable,
+			"SecureBootCurrentBoot": "Disabled",
+			"SecureBootMode": "UserMode",
+		}
+		ba,_ := json.Marshal(pld)
+		w.Header().Set("Content-Type","application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(ba)
+	case "PATCH":
+		ifMatch := r.Header.Get("If-Match")
+		if ((ifMatch != "") && (ifMatch != etag)) {
+			log.Printf("ERROR: If-Match '%s' does not match etag '%s'",ifMatch,etag)
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var jdata struct {
+			SecureBootEnable *bool
+		}
+		body,_ := ioutil.ReadAll(r.Body)
+		err := json.Unmarshal(body,&jdata)
+		if ((err != nil) || (jdata.SecureBootEnable == nil)) {
+			log.Printf("ERROR: bad Secure Boot patch '%s'",string(body))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		intelSecureBootEnable = *jdata.SecureBootEnable
+		intelSecureBootEtag ++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 // Redfish root
 
 func (p *httpStuff) rfroot(w http.ResponseWriter, r *http.Request) {
@@ -546,6 +964,7 @@ func (p *httpStuff) rfroot(w http.ResponseWriter, r *http.Request) {
 	rdat := `{
    "@odata.id" : "/redfish/v1/",
    "RedfishVersion" : "1.2.0",
+   "UUID" : "` + rfUUID + `",
    "EventService" : {
       "@odata.id" : "/redfish/v1/EventService"
    },
@@ -858,6 +1277,9 @@ func (p *httpStuff) ManagersBMC(w http.ResponseWriter, r *http.Request) {
 	var jdata rfManagersBMC
 
 	jdata.NetworkProtocol.ID = "/redfish/v1/Managers/"+burl+"/NetworkProtocol"
+	if (!isHPE) {
+		jdata.SecurityPolicy = &rfManagersBMCNWP{ID: "/redfish/v1/Managers/"+burl+"/SecurityPolicy"}
+	}
 
 	if (isHPE) {
 		jdata.Oem = &rfManagersOem{HPE: rfManagersOemHPE{Links: rfManagersOemHPELinks{SecurityService: rfManagersOemHPELinksSecurityService{ID: "/redfish/v1/Managers/1/SecurityService",},},},}
@@ -882,7 +1304,7 @@ func (p *httpStuff) hpeSecurityService(w http.ResponseWriter, r *http.Request) {
     }
 
 	printReqHdrs("hpeSecurityService",r)
-	pld := `{"Links":{"HttpsCert":{"@odata.id":"/redfish/v1/Managers/1/SecurityService/HttpsCert"}}}`
+	pld := `{"Links":{"HttpsCert":{"@odata.id":"/redfish/v1/Managers/1/SecurityService/HttpsCert"},"CertAuth":{"@odata.id":"/redfish/v1/Managers/`+burl+`/SecurityService/CertificateAuthentication"}}}`
 	w.Header().Set("Content-Type","application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(pld))
@@ -905,6 +1327,11 @@ func (p *httpStuff) hpeSecurityServiceHttpsCert(w http.ResponseWriter, r *http.R
       "target": "/redfish/v1/Managers/1/SecurityService/HttpsCert/Actions/HpeHttpsCert.ImportCertificate"
     }
   }}`
+	if (hpeCSR != "") {
+		ba,_ := json.Marshal(hpeCSR)
+		pld = strings.TrimSuffix(pld,"}") + `,
+  "CertificateSigningRequest": ` + string(ba) + `}`
+	}
 	w.Header().Set("Content-Type","application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(pld))
@@ -931,6 +1358,140 @@ func (p *httpStuff) hpeImportCert(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// CA trust store.  Non-HPE BMCs use the DMTF SecurityPolicy
+// TLS.Client.TrustedCertificates collection, HPE BMCs the CertAuth
+// CACertificates collection with an import action.  Both collections are
+// at the same URI here.
+
+func trustCollURI() string {
+	return "/redfish/v1/Managers/"+burl+"/Truststore/Certificates"
+}
+
+func checkCACert(cert string) error {
+	block,_ := pem.Decode([]byte(strings.Replace(cert,"\\n","\n",-1)))
+	if (block == nil) {
+		return fmt.Errorf("no PEM cert found")
+	}
+	xcert,err := x509.ParseCertificate(block.Bytes)
+	if (err != nil) {
+		return err
+	}
+	if (!xcert.IsCA) {
+		return fmt.Errorf("'%s' is not a CA cert",xcert.Subject.String())
+	}
+	return nil
+}
+
+func addTrustCert(cert string) {
+	trustCertID ++
+	trustCerts[fmt.Sprintf("%s/%d",trustCollURI(),trustCertID)] = cert
+	log.Printf("Added trusted CA cert %d",trustCertID)
+}
+
+func (p *httpStuff) trustSecurityPolicy(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != "GET") {
+        fmt.Printf("ERROR: request is not a GET.\n")
+        w.WriteHeader(http.StatusMethodNotAllowed)
+        return
+    }
+	printReqHdrs("trustSecurityPolicy",r)
+	pld := `{"TLS":{"Client":{"TrustedCertificates":{"@odata.id":"`+trustCollURI()+`"}}}}`
+	w.Header().Set("Content-Type","application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(pld))
+}
+
+func (p *httpStuff) hpeCertAuth(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != "GET") {
+        fmt.Printf("ERROR: request is not a GET.\n")
+        w.WriteHeader(http.StatusMethodNotAllowed)
+        return
+    }
+	printReqHdrs("hpeCertAuth",r)
+	pld := `{"CACertificates":{"@odata.id":"`+trustCollURI()+`"},
+  "Actions":{"#HpeCertAuth.ImportCACertificate":{"target":"/redfish/v1/Managers/`+burl+`/SecurityService/CertificateAuthentication/Actions/HpeCertAuth.ImportCACertificate"}}}`
+	w.Header().Set("Content-Type","application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(pld))
+}
+
+func (p *httpStuff) hpeImportCACert(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != "POST") {
+        fmt.Printf("ERROR: request is not a POST.\n")
+        w.WriteHeader(http.StatusMethodNotAllowed)
+        return
+    }
+	printReqHdrs("hpeImportCACert",r)
+	var jdata struct {
+		Certificate string
+	}
+	body,_ := ioutil.ReadAll(r.Body)
+	err := json.Unmarshal(body,&jdata)
+	if ((err != nil) || (checkCACert(jdata.Certificate) != nil)) {
+		log.Printf("ERROR: bad HPE CA cert import payload: %v",err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	addTrustCert(jdata.Certificate)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (p *httpStuff) trustCertColl(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("trustCertColl",r)
+	switch (r.Method) {
+		case "GET":
+			var mems []string
+			for uri,_ := range(trustCerts) {
+				mems = append(mems,`{"@odata.id":"`+uri+`"}`)
+			}
+			pld := `{"Members":[`+strings.Join(mems,",")+`]}`
+			w.Header().Set("Content-Type","application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(pld))
+		case "POST":
+			if (isHPE) {
+				log.Printf("ERROR: HPE CA certs must be imported with the action.")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			var jdata CertificatePayload
+			body,_ := ioutil.ReadAll(r.Body)
+			err := json.Unmarshal(body,&jdata)
+			if ((err != nil) || (jdata.CertificateType != "PEM") ||
+			    (checkCACert(jdata.CertificateString) != nil)) {
+				log.Printf("ERROR: bad CA cert payload: %v",err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			addTrustCert(jdata.CertificateString)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *httpStuff) trustCert(w http.ResponseWriter, r *http.Request) {
+	printReqHdrs("trustCert",r)
+	cert,ok := trustCerts[r.URL.Path]
+	if (!ok) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch (r.Method) {
+		case "GET":
+			ba,_ := json.Marshal(map[string]string{"CertificateString":cert,"CertificateType":"PEM"})
+			w.Header().Set("Content-Type","application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(ba)
+		case "DELETE":
+			delete(trustCerts,r.URL.Path)
+			log.Printf("Removed trusted CA cert '%s'",r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (p *httpStuff) nwp_rcv(w http.ResponseWriter, r *http.Request) {
 	printReqHdrs("nwp_rcv",r)
     if (r.Method == "PATCH") {
@@ -1046,6 +1607,12 @@ func main() {
 	if (strings.ToLower(envstr) != "hpe") {
 		isHPE = false
 	}
+	if (strings.ToLower(envstr) == "intel") {
+		isIntel = true
+	}
+	if ((strings.ToLower(envstr) == "gigabyte") || (strings.ToLower(envstr) == "gb")) {
+		isGB = true
+	}
 	envstr = os.Getenv("BMCPORT")
 	if (envstr != "") {
 		port = envstr
@@ -1057,6 +1624,10 @@ func main() {
 	if (envstr != "") {
 		xname = envstr
 	}
+	envstr = os.Getenv("RFUUID")
+	if (envstr != "") {
+		rfUUID = envstr
+	}
 	envstr = os.Getenv("HTTPS")
 	if (envstr == "1") {
 		ishttps = true
@@ -1078,6 +1649,12 @@ func main() {
     http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityService",hstuff.hpeSecurityService)
     http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityService/HttpsCert",hstuff.hpeSecurityServiceHttpsCert)
     http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityService/HttpsCert/Actions/HpeHttpsCert.ImportCertificate",hstuff.certificateReplace)
+    http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityService/HttpsCert/Actions/HpeHttpsCert.GenerateCSR",hstuff.hpeGenerateCSR)
+    http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityService/CertificateAuthentication",hstuff.hpeCertAuth)
+    http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityService/CertificateAuthentication/Actions/HpeCertAuth.ImportCACertificate",hstuff.hpeImportCACert)
+    http.HandleFunc("/redfish/v1/Managers/"+burl+"/SecurityPolicy",hstuff.trustSecurityPolicy)
+    http.HandleFunc(trustCollURI(),hstuff.trustCertColl)
+    http.HandleFunc(trustCollURI()+"/",hstuff.trustCert)
 	http.HandleFunc("/redfish/v1/AccountService",hstuff.acctService)
 	http.HandleFunc("/redfish/v1/AccountService/Accounts",hstuff.acctAccounts)
 	http.HandleFunc("/redfish/v1/AccountService/Accounts/1",hstuff.targAccount1)
@@ -1088,9 +1665,18 @@ func main() {
 	http.HandleFunc("/redfish/v1/Chassis/Enclosure",hstuff.chassisEnclosure)
 	http.HandleFunc("/redfish/v1/Chassis/Rackmount",hstuff.chassisRackmount)
 	http.HandleFunc("/redfish/v1/Chassis/Self",hstuff.chassisSelf)
+	http.HandleFunc("/redfish/v1/Systems",hstuff.systems)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID,hstuff.systemIntel)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID+"/Bios",hstuff.biosIntel)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID+"/Bios/Settings",hstuff.biosSettingsIntel)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID+"/Bios/Actions/Bios.ResetBios",hstuff.biosResetIntel)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID+"/Bios/Actions/Bios.ChangePassword",hstuff.biosChangePasswordIntel)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID+"/Actions/ComputerSystem.Reset",hstuff.systemResetIntel)
+	http.HandleFunc("/redfish/v1/Systems/"+intelSystemID+"/SecureBoot",hstuff.secureBootIntel)
 	http.HandleFunc("/redfish/v1/CertificateService",hstuff.certificateService)
 	http.HandleFunc("/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate",hstuff.certificateReplace)
 	http.HandleFunc("/redfish/v1/CertificateService/CertificateLocations",hstuff.certificateLocations)
+	http.HandleFunc("/redfish/v1/CertificateService/Actions/CertificateService.GenerateCSR",hstuff.certificateGenerateCSR)
 	http.HandleFunc("/redfish/v1/",hstuff.rfroot)
 
 	httpsrv = startHTTPServer()
@@ -72,6 +72,33 @@ echo "READINESS CHECK OK"
 echo "+++++++++++++++++++++"
 echo " "
 
+# GET /health
+
+echo "====================================================================="
+echo "Checking /health"
+echo "====================================================================="
+
+curl -D hout http://${SCSD}/v1/health > /tmp/out 2>/dev/null
+cat /tmp/out
+echo " "
+
+cat hout
+scode=`cat hout | grep HTTP | awk '{print $2}'`
+if (( scode != 200 )); then
+    echo "Bad status code from health check"
+    exit 1
+fi
+tlsst=`cat /tmp/out | jq -r '.RedfishTLSStatus'`
+tlsmode=`cat /tmp/out | jq -r '.RedfishTLSMode'`
+if [[ "${tlsst}" == "null" || "${tlsmode}" != "Permissive" ]]; then
+    echo "Bad Redfish TLS status in health check: '${tlsst}', '${tlsmode}'"
+    exit 1
+fi
+echo "+++++++++++++++++++++"
+echo "HEALTH CHECK OK"
+echo "+++++++++++++++++++++"
+echo " "
+
 # Get /version
 
 echo "====================================================================="
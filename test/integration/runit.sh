@@ -63,6 +63,14 @@ if [ -z $X_S5_PORT ]; then
     echo "ENV var 'X_S5_PORT' not set, exiting."
     exit 1
 fi
+if [ -z $X_INTEL_HOST ]; then
+    echo "ENV var 'X_INTEL_HOST' not set, exiting."
+    exit 1
+fi
+if [ -z $X_GB_HOST ]; then
+    echo "ENV var 'X_GB_HOST' not set, exiting."
+    exit 1
+fi
 
 # Make sure SCSD, HSM, and all fake RF endpoints are running
 
@@ -133,6 +141,19 @@ if [[ $? != 1 ]]; then
     exit 1
 fi
 
+echo "CHECKING FOR ${X_INTEL_HOST}..."
+isReady http://${X_INTEL_HOST}/redfish/v1/
+if [[ $? != 1 ]]; then
+    echo "Can't continue, exiting."
+    exit 1
+fi
+echo "CHECKING FOR ${X_GB_HOST}..."
+isReady http://${X_GB_HOST}/redfish/v1/
+if [[ $? != 1 ]]; then
+    echo "Can't continue, exiting."
+    exit 1
+fi
+
 echo "##################################"
 echo "Loading HSM data."
 echo "##################################"
@@ -290,6 +311,16 @@ if [ $? -ne 0 ]; then
     exit 1
 fi
 
+echo "##################################"
+echo "Replace River BMC certs."
+echo "##################################"
+
+certsRFPostRiver.sh
+if [ $? -ne 0 ]; then
+    echo "Error replacing River BMC certs with certsRFPostRiver.sh."
+    exit 1
+fi
+
 echo "##################################"
 echo "Replace single BMC cert."
 echo "##################################"
@@ -300,6 +331,92 @@ if [ $? -ne 0 ]; then
     exit 1
 fi
 
+echo "##################################"
+echo "Cert expiration inventory."
+echo "##################################"
+
+certsExpiry.sh
+if [ $? -ne 0 ]; then
+    echo "Error getting the cert expiration inventory with certsExpiry.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "Cert renewal status."
+echo "##################################"
+
+certsRenew.sh
+if [ $? -ne 0 ]; then
+    echo "Error getting the cert renewal status with certsRenew.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "CSR based certs."
+echo "##################################"
+
+certsCSR.sh
+if [ $? -ne 0 ]; then
+    echo "Error installing CSR based certs with certsCSR.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "Upload site CA issued certs."
+echo "##################################"
+
+certsUpload.sh
+if [ $? -ne 0 ]; then
+    echo "Error uploading certs with certsUpload.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "Create certs with custom SANs and key parameters."
+echo "##################################"
+
+certsCreateOpts.sh
+if [ $? -ne 0 ]; then
+    echo "Error creating certs with options with certsCreateOpts.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "Audit certs between Vault and BMCs."
+echo "##################################"
+
+certsAudit.sh
+if [ $? -ne 0 ]; then
+    echo "Error auditing certs with certsAudit.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "Install, list and remove CA trust certs."
+echo "##################################"
+
+certsTrust.sh
+if [ $? -ne 0 ]; then
+    echo "Error managing CA trust certs with certsTrust.sh."
+    exit 1
+fi
+
+echo "##################################"
+echo "TLS cert pin endpoints."
+echo "##################################"
+
+certsPins.sh
+if [ $? -ne 0 ]; then
+    echo "Error checking TLS cert pins with certsPins.sh."
+    exit 1
+fi
+
+audit.sh
+if [ $? -ne 0 ]; then
+    echo "Error checking the audit log with audit.sh."
+    exit 1
+fi
+
 echo "##################################"
 echo "Group tests."
 echo "##################################"
@@ -310,5 +427,15 @@ if [ $? -ne 0 ]; then
     exit 1
 fi
 
+echo "##################################"
+echo "Intel BIOS tests."
+echo "##################################"
+
+biosIntel.sh
+if [ $? -ne 0 ]; then
+    echo "Error running biosIntel.sh."
+    exit 1
+fi
+
 exit 0
 